@@ -1,17 +1,41 @@
 // Package dltree implements red-black tree for key value pairs with domain label keys.
 package dltree
 
-import "github.com/infobloxopen/go-trees/domain"
+import (
+	"iter"
+	"unsafe"
+
+	"github.com/infobloxopen/go-trees/domain"
+)
+
+// TreeOf is a red-black tree for key-value pairs where key is domain label and value has type V.
+type TreeOf[V any] struct {
+	root  *node[V]
+	count int
+}
 
 // Tree is a red-black tree for key-value pairs where key is domain label.
-type Tree struct {
-	root *node
+type Tree = TreeOf[interface{}]
+
+// PairOf is a key-value pair representing tree node content.
+type PairOf[V any] struct {
+	Key   string
+	Value V
 }
 
 // Pair is a key-value pair representing tree node content.
-type Pair struct {
-	Key   string
-	Value interface{}
+type Pair = PairOf[interface{}]
+
+// Stats holds tree statistics returned by Stats method.
+type Stats struct {
+	// Nodes is a number of all nodes in the tree.
+	Nodes int
+	// Childless is a number of nodes without children. Every node of red-black tree holds a key-value pair so the number of pairs is equal to Nodes unlike Leaves of radix trees.
+	Childless int
+	// MaxDepth is a number of nodes on the longest path from the root.
+	MaxDepth int
+	// Bytes is an estimated memory size of the nodes. It doesn't include memory referenced by keys and values.
+	Bytes int
 }
 
 // NewTree creates empty tree.
@@ -19,48 +43,56 @@ func NewTree() *Tree {
 	return new(Tree)
 }
 
-// Insert puts given key-value pair to the tree and returns pointer to new root.
-func (t *Tree) Insert(key string, value interface{}) *Tree {
-	var (
-		n *node
-	)
-
-	if t != nil {
-		n = t.root
-	}
+// NewTreeOf creates empty tree with values of type V.
+func NewTreeOf[V any]() *TreeOf[V] {
+	return new(TreeOf[V])
+}
 
+// Insert puts given key-value pair to the tree and returns pointer to new root.
+func (t *TreeOf[V]) Insert(key string, value V) *TreeOf[V] {
 	dl, _ := domain.MakeLabel(key)
-	return &Tree{root: n.insert(dl, value)}
+	return t.RawInsert(dl, value)
 }
 
 // RawInsert puts given key-value pair to the tree and returns pointer to new root. Expects bindary domain label on input.
-func (t *Tree) RawInsert(key string, value interface{}) *Tree {
+func (t *TreeOf[V]) RawInsert(key string, value V) *TreeOf[V] {
 	var (
-		n *node
+		n     *node[V]
+		count int
 	)
 
 	if t != nil {
 		n = t.root
+		count = t.count
+	}
+
+	root, ok := n.insert(key, value)
+	if ok {
+		count++
 	}
 
-	return &Tree{root: n.insert(key, value)}
+	return &TreeOf[V]{root: root, count: count}
 }
 
 // InplaceInsert inserts or replaces given key-value pair in the tree. The method inserts data directly to current tree so make sure you have exclusive access to it.
-func (t *Tree) InplaceInsert(key string, value interface{}) {
+func (t *TreeOf[V]) InplaceInsert(key string, value V) {
 	dl, _ := domain.MakeLabel(key)
-	t.root = t.root.inplaceInsert(dl, value)
+	t.RawInplaceInsert(dl, value)
 }
 
 // RawInplaceInsert inserts or replaces given key-value pair in the tree. The method inserts data directly to current tree so make sure you have exclusive access to it. Expects bindary domain label on input.
-func (t *Tree) RawInplaceInsert(key string, value interface{}) {
-	t.root = t.root.inplaceInsert(key, value)
+func (t *TreeOf[V]) RawInplaceInsert(key string, value V) {
+	var ok bool
+	if t.root, ok = t.root.inplaceInsert(key, value); ok {
+		t.count++
+	}
 }
 
 // Get returns value by given key.
-func (t *Tree) Get(key string) (interface{}, bool) {
+func (t *TreeOf[V]) Get(key string) (V, bool) {
 	if t == nil {
-		return nil, false
+		var v V
+		return v, false
 	}
 
 	dl, _ := domain.MakeLabel(key)
@@ -68,76 +100,110 @@ func (t *Tree) Get(key string) (interface{}, bool) {
 }
 
 // RawGet returns value by given key. Expects bindary domain label on input.
-func (t *Tree) RawGet(key string) (interface{}, bool) {
+func (t *TreeOf[V]) RawGet(key string) (V, bool) {
 	if t == nil {
-		return nil, false
+		var v V
+		return v, false
 	}
 
 	return t.root.get(key)
 }
 
 // Enumerate returns channel which is populated by key pair values in order of keys.
-func (t *Tree) Enumerate() chan Pair {
-	ch := make(chan Pair)
-
-	go func() {
-		defer close(ch)
+func (t *TreeOf[V]) Enumerate() chan PairOf[V] {
+	return enumerate(t.All())
+}
 
-		if t == nil {
-			return
-		}
+// All returns iterator over key-value pairs in order of keys.
+func (t *TreeOf[V]) All() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		t.Walk(yield)
+	}
+}
 
-		t.root.enumerate(ch)
-	}()
+// Walk calls f for key-value pairs in order of keys until f returns false. It reports if all the pairs have been visited.
+func (t *TreeOf[V]) Walk(f func(string, V) bool) bool {
+	if t == nil {
+		return true
+	}
 
-	return ch
+	return t.root.walk(f)
 }
 
 // RawEnumerate returns channel which is populated by key pair values in order of keys. Returns binary domain labels.
-func (t *Tree) RawEnumerate() chan Pair {
-	ch := make(chan Pair)
-
-	go func() {
-		defer close(ch)
+func (t *TreeOf[V]) RawEnumerate() chan PairOf[V] {
+	return enumerate(t.RawAll())
+}
 
-		if t == nil {
-			return
-		}
+// RawAll returns iterator over key-value pairs in order of keys. Returns binary domain labels.
+func (t *TreeOf[V]) RawAll() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		t.RawWalk(yield)
+	}
+}
 
-		t.root.rawEnumerate(ch)
-	}()
+// RawWalk calls f for key-value pairs in order of keys until f returns false. It reports if all the pairs have been visited. Passes binary domain labels to f.
+func (t *TreeOf[V]) RawWalk(f func(string, V) bool) bool {
+	if t == nil {
+		return true
+	}
 
-	return ch
+	return t.root.rawWalk(f)
 }
 
 // Delete removes node by given key. It returns copy of tree and true if node has been indeed deleted otherwise copy of tree and false.
-func (t *Tree) Delete(key string) (*Tree, bool) {
+func (t *TreeOf[V]) Delete(key string) (*TreeOf[V], bool) {
 	if t == nil {
 		return nil, false
 	}
 
 	dl, _ := domain.MakeLabel(key)
-	root, ok := t.root.del(dl)
-	return &Tree{root: root}, ok
+	return t.RawDelete(dl)
 }
 
 // RawDelete removes node by given key. It returns copy of tree and true if node has been indeed deleted otherwise copy of tree and false. Expects bindary domain label on input.
-func (t *Tree) RawDelete(key string) (*Tree, bool) {
+func (t *TreeOf[V]) RawDelete(key string) (*TreeOf[V], bool) {
 	if t == nil {
 		return nil, false
 	}
 
 	root, ok := t.root.del(key)
-	return &Tree{root: root}, ok
+
+	count := t.count
+	if ok {
+		count--
+	}
+
+	return &TreeOf[V]{root: root, count: count}, ok
+}
+
+// Len returns number of key-value pairs in the tree. It takes constant time.
+func (t *TreeOf[V]) Len() int {
+	if t == nil {
+		return 0
+	}
+
+	return t.count
+}
+
+// Stats walks the tree and collects its statistics.
+func (t *TreeOf[V]) Stats() Stats {
+	var s Stats
+	if t != nil {
+		t.root.stats(1, &s)
+		s.Bytes = s.Nodes * int(unsafe.Sizeof(node[V]{}))
+	}
+
+	return s
 }
 
 // IsEmpty returns true if given tree has no nodes.
-func (t *Tree) IsEmpty() bool {
+func (t *TreeOf[V]) IsEmpty() bool {
 	return t == nil || t.root == nil
 }
 
 // Dot dumps tree to Graphviz .dot format.
-func (t *Tree) Dot() string {
+func (t *TreeOf[V]) Dot() string {
 	body := ""
 
 	if t != nil {
@@ -146,3 +212,17 @@ func (t *Tree) Dot() string {
 
 	return "digraph d {\n" + body + "}\n"
 }
+
+func enumerate[V any](seq iter.Seq2[string, V]) chan PairOf[V] {
+	ch := make(chan PairOf[V])
+
+	go func() {
+		defer close(ch)
+
+		for k, v := range seq {
+			ch <- PairOf[V]{Key: k, Value: v}
+		}
+	}()
+
+	return ch
+}
@@ -456,6 +456,79 @@ func TestEnumerate(t *testing.T) {
 		"\"4\": \"test-4\"\n")
 }
 
+func TestAll(t *testing.T) {
+	var r *Tree
+
+	for k, v := range r.All() {
+		t.Errorf("Expected no pairs in empty tree but got %q: %#v", k, v)
+	}
+
+	r = NewTree()
+	r = r.Insert("1", "test-1")
+	r = r.Insert("0", "test-0")
+	r = r.Insert("4", "test-4")
+	r = r.Insert("2", "test-2")
+	r = r.Insert("3", "test-3")
+
+	keys := ""
+	for k := range r.All() {
+		keys += k
+		if len(keys) >= 3 {
+			break
+		}
+	}
+
+	if keys != "012" {
+		t.Errorf("Expected \"012\" keys but got %q", keys)
+	}
+
+	keys = ""
+	if r.Walk(func(k string, v interface{}) bool {
+		keys += k
+		return k != "3"
+	}) {
+		t.Error("Expected interrupted walk")
+	}
+
+	if keys != "0123" {
+		t.Errorf("Expected \"0123\" keys but got %q", keys)
+	}
+}
+
+func TestTreeOf(t *testing.T) {
+	r := NewTreeOf[int]()
+	r = r.Insert("1", 1)
+	r = r.Insert("0", 0)
+	r = r.Insert("4", 4)
+	r.InplaceInsert("2", 2)
+	r.InplaceInsert("3", 3)
+
+	if v, ok := r.Get("3"); !ok || v != 3 {
+		t.Errorf("Expected 3 but got %d (%v)", v, ok)
+	}
+
+	r, ok := r.Delete("1")
+	if !ok {
+		t.Errorf("Expected \"1\" to be deleted")
+	}
+
+	if v, ok := r.Get("1"); ok {
+		t.Errorf("Expected nothing but got %d", v)
+	}
+
+	items := []string{}
+	for p := range r.Enumerate() {
+		items = append(items, fmt.Sprintf("%q: %d\n", p.Key, p.Value))
+	}
+
+	assertStringLists(items, []string{
+		"\"0\": 0\n",
+		"\"2\": 2\n",
+		"\"3\": 3\n",
+		"\"4\": 4\n",
+	}, "enumeration of typed tree", t)
+}
+
 func TestDelete(t *testing.T) {
 	var r *Tree
 
@@ -590,6 +663,60 @@ func TestIsEmpty(t *testing.T) {
 		t.Errorf("Expected empty non-nil tree to be empty")
 	}
 }
+func TestLen(t *testing.T) {
+	var r *Tree
+	assertLen(r, 0, "nil tree", t)
+
+	r = NewTree()
+	assertLen(r, 0, "empty tree", t)
+
+	for _, k := range []string{"0", "1", "2", "3", "4"} {
+		r = r.Insert(k, nil)
+	}
+	assertLen(r, 5, "five nodes tree", t)
+
+	r = r.Insert("2", "replaced")
+	assertLen(r, 5, "five nodes tree after replace", t)
+
+	r.InplaceInsert("5", nil)
+	r.InplaceInsert("0", "replaced")
+	assertLen(r, 6, "six nodes tree after inplace insert", t)
+
+	d, ok := r.Delete("3")
+	if !ok {
+		t.Errorf("Expected element \"3\" to be deleted")
+	}
+	assertLen(d, 5, "tree after delete", t)
+	assertLen(r, 6, "original tree after delete", t)
+
+	d, ok = d.Delete("3")
+	if ok {
+		t.Errorf("Expected element \"3\" to be absent")
+	}
+	assertLen(d, 5, "tree after delete of missing element", t)
+}
+
+func TestStats(t *testing.T) {
+	var r *Tree
+	if s := r.Stats(); s != (Stats{}) {
+		t.Errorf("Expected empty stats for nil tree but got %#v", s)
+	}
+
+	for _, k := range []string{"0", "1", "2", "3", "4", "5", "6"} {
+		r = r.Insert(k, nil)
+	}
+
+	s := r.Stats()
+	if s.Nodes != 7 || s.Childless != 4 || s.MaxDepth != 4 || s.Bytes <= 0 {
+		t.Errorf("Expected 7 nodes, 4 childless nodes and depth 4 but got %#v", s)
+	}
+}
+
+func assertLen(r *Tree, e int, desc string, t *testing.T) {
+	if n := r.Len(); n != e {
+		t.Errorf("Expected %d elements in %s but got %d", e, desc, n)
+	}
+}
 
 func TestRawMethods(t *testing.T) {
 	var r *Tree
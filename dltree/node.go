@@ -12,20 +12,20 @@ const (
 	dirRight
 )
 
-type node struct {
+type node[V any] struct {
 	key   string
-	value interface{}
+	value V
 
-	chld [2]*node
+	chld [2]*node[V]
 	red  bool
 }
 
-func (n *node) dot() string {
+func (n *node[V]) dot() string {
 	body := ""
 
 	// Iterate all nodes using breadth-first search algorithm.
 	i := 0
-	queue := []*node{n}
+	queue := []*node[V]{n}
 	for len(queue) > 0 {
 		n := queue[0]
 		body += fmt.Sprintf("N%d %s\n", i, n.dotString())
@@ -43,13 +43,13 @@ func (n *node) dot() string {
 	return body
 }
 
-func (n *node) dotString() string {
+func (n *node[V]) dotString() string {
 	if n == nil {
 		return "[label=\"nil\" style=filled fontcolor=white fillcolor=black]"
 	}
 
 	k := fmt.Sprintf("%q", n.key)
-	if n.value != nil {
+	if any(n.value) != nil {
 		v := fmt.Sprintf("%q", fmt.Sprintf("%#v", n.value))
 		k = fmt.Sprintf("\"k: \\\"%s\\\" v: \\\"%s\\\"\"", k[1:len(k)-1], v[1:len(v)-1])
 	}
@@ -62,28 +62,29 @@ func (n *node) dotString() string {
 	return fmt.Sprintf("[label=%s style=filled %s]", k, color)
 }
 
-func (n *node) insert(key string, value interface{}) *node {
+func (n *node[V]) insert(key string, value V) (*node[V], bool) {
 	if n == nil {
-		return &node{key: key, value: value}
+		return &node[V]{key: key, value: value}, true
 	}
 
 	// Using fake root to get rid of corner cases with rotation right under the root.
-	root := &node{chld: [2]*node{nil, n}}
+	root := &node[V]{chld: [2]*node[V]{nil, n}}
 	dir := dirLeft
+	added := false
 
 	// Nodes down the path to current node. All these nodes are copies of nodes from tree.
 	var (
 		// Grandparent's parent.
-		gp *node
+		gp *node[V]
 
 		// Grandparent.
-		g *node
+		g *node[V]
 
 		// Parent.
-		p *node
+		p *node[V]
 
 		// Childern.
-		c [2]*node
+		c [2]*node[V]
 	)
 
 	// Start with fake root.
@@ -109,11 +110,12 @@ func (n *node) insert(key string, value interface{}) *node {
 
 		if n == nil {
 			// If no child in the direction we go insert new red node.
-			n = &node{
+			added = true
+			n = &node[V]{
 				key: key,
 				red: true}
 
-			c = [2]*node{nil, nil}
+			c = [2]*node[V]{nil, nil}
 		} else {
 			// Make copy of current node or just use copy of child node if it has been made during color flip.
 			if n != c[dir] {
@@ -123,12 +125,12 @@ func (n *node) insert(key string, value interface{}) *node {
 			// Color flip case to maintain invariant that the current node is black and has at least one black child.
 			if n.chld[dirLeft] != nil && n.chld[dirRight] != nil && n.chld[dirLeft].red && n.chld[dirRight].red {
 				n.red = true
-				c = [2]*node{
+				c = [2]*node[V]{
 					n.chld[dirLeft].colorCopy(false),
 					n.chld[dirRight].colorCopy(false)}
 				n.chld = c
 			} else {
-				c = [2]*node{nil, nil}
+				c = [2]*node[V]{nil, nil}
 			}
 		}
 		p.chld[dir] = n
@@ -177,21 +179,22 @@ func (n *node) insert(key string, value interface{}) *node {
 
 	n = root.chld[dirRight]
 	n.red = false
-	return n
+	return n, added
 }
 
-func (n *node) inplaceInsert(key string, value interface{}) *node {
+func (n *node[V]) inplaceInsert(key string, value V) (*node[V], bool) {
 	if n == nil {
-		return &node{key: key, value: value}
+		return &node[V]{key: key, value: value}, true
 	}
 
-	root := &node{chld: [2]*node{nil, n}}
+	root := &node[V]{chld: [2]*node[V]{nil, n}}
 	dir := dirLeft
+	added := false
 
 	var (
-		gp *node
-		g  *node
-		p  *node
+		gp *node[V]
+		g  *node[V]
+		p  *node[V]
 	)
 
 	n = root
@@ -210,7 +213,8 @@ func (n *node) inplaceInsert(key string, value interface{}) *node {
 		n = n.chld[dir]
 
 		if n == nil {
-			n = &node{
+			added = true
+			n = &node[V]{
 				key: key,
 				red: true}
 
@@ -248,26 +252,26 @@ func (n *node) inplaceInsert(key string, value interface{}) *node {
 
 	n = root.chld[dirRight]
 	n.red = false
-	return n
+	return n, added
 }
 
-func (n *node) fullCopy() *node {
-	return &node{
+func (n *node[V]) fullCopy() *node[V] {
+	return &node[V]{
 		key:   n.key,
 		value: n.value,
 		chld:  n.chld,
 		red:   n.red}
 }
 
-func (n *node) colorCopy(color bool) *node {
-	return &node{
+func (n *node[V]) colorCopy(color bool) *node[V] {
+	return &node[V]{
 		key:   n.key,
 		value: n.value,
 		chld:  n.chld,
 		red:   color}
 }
 
-func (n *node) single(dir int) *node {
+func (n *node[V]) single(dir int) *node[V] {
 	nDir := 1 - dir
 	s := n.chld[dir]
 	n.chld[dir] = s.chld[nDir]
@@ -279,12 +283,12 @@ func (n *node) single(dir int) *node {
 	return s
 }
 
-func (n *node) double(dir int) *node {
+func (n *node[V]) double(dir int) *node[V] {
 	n.chld[dir] = n.chld[dir].single(1 - dir)
 	return n.single(dir)
 }
 
-func (n *node) get(key string) (interface{}, bool) {
+func (n *node[V]) get(key string) (V, bool) {
 	for n != nil {
 		r := len(n.key) - len(key)
 		if r == 0 {
@@ -302,47 +306,44 @@ func (n *node) get(key string) (interface{}, bool) {
 		n = n.chld[dir]
 	}
 
-	return nil, false
+	var v V
+	return v, false
 }
 
-func (n *node) enumerate(ch chan Pair) {
+func (n *node[V]) walk(f func(string, V) bool) bool {
 	if n == nil {
-		return
+		return true
 	}
 
-	n.chld[dirLeft].enumerate(ch)
-
-	ch <- Pair{Key: domain.MakeHumanReadableLabel(n.key), Value: n.value}
-
-	n.chld[dirRight].enumerate(ch)
+	return n.chld[dirLeft].walk(f) &&
+		f(domain.MakeHumanReadableLabel(n.key), n.value) &&
+		n.chld[dirRight].walk(f)
 }
 
-func (n *node) rawEnumerate(ch chan Pair) {
+func (n *node[V]) rawWalk(f func(string, V) bool) bool {
 	if n == nil {
-		return
+		return true
 	}
 
-	n.chld[dirLeft].rawEnumerate(ch)
-
-	ch <- Pair{Key: n.key, Value: n.value}
-
-	n.chld[dirRight].rawEnumerate(ch)
+	return n.chld[dirLeft].rawWalk(f) &&
+		f(n.key, n.value) &&
+		n.chld[dirRight].rawWalk(f)
 }
 
-func (n *node) del(key string) (*node, bool) {
+func (n *node[V]) del(key string) (*node[V], bool) {
 	// Fake root.
-	root := &node{chld: [2]*node{nil, n}}
+	root := &node[V]{chld: [2]*node[V]{nil, n}}
 
 	// Nodes down the path to current node.
 	var (
 		// Grandparent.
-		g *node
+		g *node[V]
 
 		// Parent.
-		p *node
+		p *node[V]
 
 		// Target node.
-		t *node
+		t *node[V]
 	)
 
 	n = root
@@ -436,3 +437,21 @@ func (n *node) del(key string) (*node, bool) {
 	}
 	return n, t != nil
 }
+
+func (n *node[V]) stats(depth int, s *Stats) {
+	if n == nil {
+		return
+	}
+
+	s.Nodes++
+	if n.chld[dirLeft] == nil && n.chld[dirRight] == nil {
+		s.Childless++
+	}
+
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	n.chld[dirLeft].stats(depth+1, s)
+	n.chld[dirRight].stats(depth+1, s)
+}
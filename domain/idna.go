@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// ErrInvalidIDN is returned by MakeNameFromUnicode for domain name which can't be converted to ASCII according to IDNA rules.
+var ErrInvalidIDN = errors.New("invalid internationalized domain name")
+
+const acePrefix = "xn--"
+
+// idnaProfile applies UTS #46 non-transitional mapping and IDNA2008 validation to labels but unlike idna.Lookup allows any ASCII characters (like underscore) in the labels.
+var idnaProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.StrictDomainName(false),
+)
+
+// MakeNameFromUnicode creates a Name from domain name which may contain Unicode labels. The name is split to labels the same way as MakeNameFromString does and each label with Unicode characters is unescaped, mapped according to UTS #46, converted to ASCII compatible encoding ("xn--" label) and escaped back so the name is equal to one created by MakeNameFromString from ASCII form of the domain. Other labels as well as pure ASCII name are handled exactly as MakeNameFromString does. Escaped dot in a label with Unicode characters is an error as IDNA doesn't allow dots inside labels.
+func MakeNameFromUnicode(s string) (Name, error) {
+	if isASCII(s) {
+		return MakeNameFromString(s)
+	}
+
+	var offs [MaxLabels]int
+	m, err := markLabels(s, offs[:])
+	if err != nil {
+		return Name{h: s}, err
+	}
+
+	var b strings.Builder
+	for i := 0; i < m; i++ {
+		label, sep := splitLabel(s, offs[:m], i)
+		if isASCII(label) {
+			b.WriteString(label)
+			b.WriteString(sep)
+			continue
+		}
+
+		u, err := unescapeLabel(label)
+		if err != nil {
+			return Name{h: s}, err
+		}
+
+		if strings.Contains(u, ".") {
+			return Name{h: s}, fmt.Errorf("%w: escaped dot in label %q", ErrInvalidIDN, label)
+		}
+
+		// UTS #46 mapping turns full stop variants like U+3002 into dots so single label can become several ones.
+		a, err := idnaProfile.ToASCII(u)
+		if err != nil {
+			return Name{h: s}, fmt.Errorf("%w: %s", ErrInvalidIDN, err)
+		}
+
+		for j, label := range strings.Split(a, ".") {
+			if j > 0 {
+				b.WriteByte('.')
+			}
+
+			b.WriteString(escapeUnicodeLabel(label))
+		}
+
+		b.WriteString(sep)
+	}
+
+	return MakeNameFromString(b.String())
+}
+
+// Unicode returns human-readable domain name with labels in ASCII compatible encoding ("xn--" labels) converted back to Unicode. Decoded labels are escaped according to RFC 4343 except for Unicode characters. Other labels as well as labels which can't be decoded remain as is.
+func (n Name) Unicode() string {
+	if !strings.Contains(n.h, "-") {
+		return n.h
+	}
+
+	var offs [MaxLabels]int
+	m, err := markLabels(n.h, offs[:])
+	if err != nil {
+		return n.h
+	}
+
+	var b strings.Builder
+	for i := 0; i < m; i++ {
+		label, sep := splitLabel(n.h, offs[:m], i)
+		if len(label) > len(acePrefix) && strings.EqualFold(label[:len(acePrefix)], acePrefix) {
+			if a, err := unescapeLabel(label); err == nil {
+				if u, err := idna.Punycode.ToUnicode(strings.ToLower(a)); err == nil {
+					label = escapeUnicodeLabel(u)
+				}
+			}
+		}
+
+		b.WriteString(label)
+		b.WriteString(sep)
+	}
+
+	return b.String()
+}
+
+// splitLabel returns i-th label of the name marked by markLabels without ending dot and the dot separately if the label has one.
+func splitLabel(s string, offs []int, i int) (string, string) {
+	end := len(s)
+	if i+1 < len(offs) {
+		end = offs[i+1]
+	}
+
+	label := s[offs[i]:end]
+	if !strings.HasSuffix(label, ".") {
+		return label, ""
+	}
+
+	// Only the last label can end with escaped dot. The dot is escaped if odd number of backslashes goes before it.
+	j := len(label) - 1
+	for j > 0 && label[j-1] == '\\' {
+		j--
+	}
+
+	if (len(label)-1-j)&1 != 0 {
+		return label, ""
+	}
+
+	return label[:len(label)-1], "."
+}
+
+// unescapeLabel resolves RFC 4343 escape sequences in the label. Unlike MakeLabel it doesn't limit length of the label so Unicode labels longer than 63 bytes can be converted to ASCII compatible encoding which fits the limit.
+func unescapeLabel(s string) (string, error) {
+	out := make([]byte, len(s)+1)
+	n, err := getLabel(s, out)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out[1:n]), nil
+}
+
+// escapeUnicodeLabel escapes ASCII characters of the label in the same way as MakeHumanReadableLabel but keeps case of letters and leaves Unicode characters as is.
+func escapeUnicodeLabel(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+
+		case c < '!' || c == 0x7f:
+			fmt.Fprintf(&b, "\\%03d", c)
+
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+
+	return true
+}
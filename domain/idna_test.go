@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMakeNameFromUnicode(t *testing.T) {
+	assertUnicodeName(t, "Bücher.example", "xn--bcher-kva.example", "bücher.example")
+	assertUnicodeName(t, "bücher.example.", "xn--bcher-kva.example.", "bücher.example.")
+	assertUnicodeName(t, "_sip._tcp.bücher.example", "_sip._tcp.xn--bcher-kva.example", "_sip._tcp.bücher.example")
+	assertUnicodeName(t, "faß.de", "xn--fa-hia.de", "faß.de")
+	assertUnicodeName(t, "ＢＵＣＨＥＲ。example", "bucher.example", "bucher.example")
+	assertUnicodeName(t, "a\\.b.bücher.de", "a\\.b.xn--bcher-kva.de", "a\\.b.bücher.de")
+	assertUnicodeName(t, "Wiki.Example.COM", "Wiki.Example.COM", "Wiki.Example.COM")
+	assertUnicodeName(t, "XN--bcher-kva.Example", "XN--bcher-kva.Example", "bücher.Example")
+	assertUnicodeName(t, "xn--zz.example", "xn--zz.example", "xn--zz.example")
+	assertUnicodeName(t, ".", ".", ".")
+	assertUnicodeName(t, "ü\\032a.com", "xn--\\032a-wka.com", "ü\\032a.com")
+	assertUnicodeName(t, "a\\\\.bücher.de", "a\\\\.xn--bcher-kva.de", "a\\\\.bücher.de")
+	assertUnicodeName(t, "bücher.ex\\.ample", "xn--bcher-kva.ex\\.ample", "bücher.ex\\.ample")
+	assertUnicodeName(t, "bücher.de\\.", "xn--bcher-kva.de\\.", "bücher.de\\.")
+	assertUnicodeName(t, "b\\252cher.de", "b\\252cher.de", "b\\252cher.de")
+
+	if _, err := MakeNameFromUnicode("bad‍.com"); !errors.Is(err, ErrInvalidIDN) {
+		t.Errorf("expected %q error for name with zero width joiner but got %v", ErrInvalidIDN, err)
+	}
+
+	for _, s := range []string{"bücher\\.x.example", "ex\\.ämple.com", "bücher\\046x.example"} {
+		if _, err := MakeNameFromUnicode(s); !errors.Is(err, ErrInvalidIDN) {
+			t.Errorf("expected %q error for %q with escaped dot in Unicode label but got %v", ErrInvalidIDN, s, err)
+		}
+	}
+
+	if _, err := MakeNameFromUnicode("bücher..example"); err == nil {
+		t.Error("expected error for name with empty label")
+	}
+}
+
+func assertUnicodeName(t *testing.T, s, ea, eu string) {
+	n, err := MakeNameFromUnicode(s)
+	if err != nil {
+		t.Errorf("expected no error for %q but got %s", s, err)
+		return
+	}
+
+	if n.String() != ea {
+		t.Errorf("expected %q as human-readable form of %q but got %q", ea, s, n)
+	}
+
+	if u := n.Unicode(); u != eu {
+		t.Errorf("expected %q as Unicode form of %q but got %q", eu, s, u)
+	}
+
+	a, err := MakeNameFromString(ea)
+	if err != nil {
+		t.Errorf("expected no error for %q but got %s", ea, err)
+		return
+	}
+
+	if a.c != n.c {
+		t.Errorf("expected the same labels for %q and %q but got %q and %q", s, ea, n.c, a.c)
+	}
+}
@@ -2,9 +2,12 @@
 package domain
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"iter"
 	"reflect"
+	"strings"
 )
 
 var (
@@ -141,6 +144,35 @@ func (n Name) GetLabel(off int) (string, int) {
 	return n.c[start:end], end
 }
 
+// Suffix returns domain name which consists of given number of the last labels of the name. For example 2 labels of "www.example.com" give "example.com". It returns root domain if the number isn't positive and the name itself if the number isn't less than number of its labels. The method doesn't allocate.
+func (n Name) Suffix(labels int) Name {
+	if labels <= 0 {
+		return Name{h: "."}
+	}
+
+	off := 0
+	for i := 0; i < labels; i++ {
+		if off >= len(n.c) {
+			return n
+		}
+
+		off += int(n.c[off]) + 1
+	}
+
+	if off >= len(n.c) {
+		return n
+	}
+
+	out := Name{c: n.c[:off]}
+
+	var offs [MaxLabels]int
+	if m, err := markLabels(n.h, offs[:]); err == nil && m >= labels {
+		out.h = n.h[offs[m-labels]:]
+	}
+
+	return out
+}
+
 // GetLabels iterate through name labels in reversed order.
 func (n Name) GetLabels(f func(string) error) error {
 	off := 0
@@ -157,3 +189,143 @@ func (n Name) GetLabels(f func(string) error) error {
 
 	return nil
 }
+
+// LabelCount returns number of labels in the name. Root domain has no labels.
+func (n Name) LabelCount() int {
+	m := 0
+	for off := 0; off < len(n.c); off += int(n.c[off]) + 1 {
+		m++
+	}
+
+	return m
+}
+
+// Parent returns the name without its first (leftmost) label. Parent of root domain is root domain. The method doesn't allocate.
+func (n Name) Parent() Name {
+	return n.Suffix(n.LabelCount() - 1)
+}
+
+// Prepend returns new name with given human-readable label (RFC 4343 escapes are allowed) added before the first label of the name.
+func (n Name) Prepend(label string) (Name, error) {
+	var offs [2]int
+
+	m, err := markLabels(label, offs[:])
+	if err != nil {
+		return n, err
+	}
+
+	if m > 1 {
+		return n, ErrTooManyLabels
+	}
+
+	if m < 1 {
+		return n, ErrEmptyLabel
+	}
+
+	var buf [MaxLabel + 1]byte
+
+	size, err := getLabel(label, buf[:])
+	if err != nil {
+		return n, err
+	}
+
+	if len(n.c)+size > MaxName {
+		return n, ErrNameTooLong
+	}
+
+	if hasFinalDot(label) {
+		label = label[:len(label)-1]
+	}
+
+	if len(n.c) > 0 {
+		label += "." + n.h
+	}
+
+	return Name{h: label, c: n.c + string(buf[:size])}, nil
+}
+
+// Labels returns iterator over labels of the name from the first (leftmost) one to the last one. It yields labels in the same uppercase binary form as GetLabels. The iterator doesn't allocate.
+func (n Name) Labels() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		var offs [MaxLabels]int
+
+		m := 0
+		for off := 0; off < len(n.c) && m < len(offs); off += int(n.c[off]) + 1 {
+			offs[m] = off
+			m++
+		}
+
+		for i := m - 1; i >= 0; i-- {
+			off := offs[i] + 1
+			if !yield(n.c[off : off+int(n.c[offs[i]])]) {
+				return
+			}
+		}
+	}
+}
+
+// IsSubdomainOf checks if the name is equal to or is a subdomain of given name. Any name is a subdomain of root domain.
+func (n Name) IsSubdomainOf(other Name) bool {
+	return strings.HasPrefix(n.c, other.c)
+}
+
+// Compare compares the name with given name in canonical DNS name order defined by RFC 4034 section 6.1. Labels are compared starting from the last (rightmost) one as case-insensitive byte strings and name which runs out of labels sorts first. It returns -1 if the name goes before given name, 1 if it goes after and 0 if names are equal.
+func (n Name) Compare(other Name) int {
+	i, j := 0, 0
+	for i < len(n.c) && j < len(other.c) {
+		a := n.c[i+1 : i+1+int(n.c[i])]
+		b := other.c[j+1 : j+1+int(other.c[j])]
+		if r := compareLabels(a, b); r != 0 {
+			return r
+		}
+
+		i += len(a) + 1
+		j += len(b) + 1
+	}
+
+	if i < len(n.c) {
+		return 1
+	}
+
+	if j < len(other.c) {
+		return -1
+	}
+
+	return 0
+}
+
+func compareLabels(a, b string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := toLower(a[i]), toLower(b[i])
+		if x < y {
+			return -1
+		}
+
+		if x > y {
+			return 1
+		}
+	}
+
+	return cmp.Compare(len(a), len(b))
+}
+
+func toLower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c | 0x20
+	}
+
+	return c
+}
+
+func hasFinalDot(s string) bool {
+	if len(s) < 1 || s[len(s)-1] != '.' {
+		return false
+	}
+
+	esc := 0
+	for i := len(s) - 2; i >= 0 && s[i] == '\\'; i-- {
+		esc++
+	}
+
+	return esc%2 == 0
+}
@@ -310,6 +310,235 @@ func TestGetLabelsWithError(t *testing.T) {
 	assertLabels(t, lbls, []string{"COM", "EXAMPLE"})
 }
 
+func TestSuffix(t *testing.T) {
+	n, err := MakeNameFromString("Wiki.Ex\\.ample.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertSuffix(t, n, 1, "com.", []string{"COM"})
+	assertSuffix(t, n, 2, "Ex\\.ample.com.", []string{"COM", "EX.AMPLE"})
+	assertSuffix(t, n, 3, "Wiki.Ex\\.ample.com.", []string{"COM", "EX.AMPLE", "WIKI"})
+	assertSuffix(t, n, 4, "Wiki.Ex\\.ample.com.", []string{"COM", "EX.AMPLE", "WIKI"})
+	assertSuffix(t, n, 0, ".", []string{})
+	assertSuffix(t, n, -1, ".", []string{})
+
+	if a := testing.AllocsPerRun(10, func() { n.Suffix(2) }); a > 0 {
+		t.Errorf("expected no allocations but got %g", a)
+	}
+}
+
+func assertSuffix(t *testing.T, n Name, labels int, e string, el []string) {
+	s := n.Suffix(labels)
+	if s.String() != e {
+		t.Errorf("expected %q for %d labels of %q but got %q", e, labels, n, s)
+	}
+
+	lbls := []string{}
+	if err := s.GetLabels(func(lbl string) error {
+		lbls = append(lbls, lbl)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertLabels(t, lbls, el)
+}
+
+func TestLabelCount(t *testing.T) {
+	for s, e := range map[string]int{"": 0, ".": 0, "com": 1, "example.com.": 2, "wiki.ex\\.ample.com": 3} {
+		n, err := MakeNameFromString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c := n.LabelCount(); c != e {
+			t.Errorf("expected %d labels in %q but got %d", e, s, c)
+		}
+	}
+}
+
+func TestParent(t *testing.T) {
+	n, err := MakeNameFromString("Wiki.Example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range []string{"Example.com.", "com.", ".", "."} {
+		n = n.Parent()
+		if n.String() != e {
+			t.Errorf("expected %q as parent but got %q", e, n)
+		}
+	}
+
+	if a := testing.AllocsPerRun(10, func() { n.Parent() }); a > 0 {
+		t.Errorf("expected no allocations but got %g", a)
+	}
+}
+
+func TestPrepend(t *testing.T) {
+	n, err := MakeNameFromString("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertPrepend(t, n, "Wiki", "Wiki.example.com", []string{"COM", "EXAMPLE", "WIKI"})
+	assertPrepend(t, n, "a\\.b.", "a\\.b.example.com", []string{"COM", "EXAMPLE", "A.B"})
+	assertPrepend(t, n, "a\\\\.", "a\\\\.example.com", []string{"COM", "EXAMPLE", "A\\"})
+	assertPrepend(t, Name{}, "com", "com", []string{"COM"})
+
+	for _, s := range []string{"", ".", "a.b", "a\\", strings.Repeat("a", MaxLabel+1)} {
+		if m, err := n.Prepend(s); err == nil {
+			t.Errorf("expected error for label %q but got %q", s, m)
+		}
+	}
+
+	long, err := MakeNameFromString(strings.Repeat(strings.Repeat("a", MaxLabel)+".", 3) + strings.Repeat("a", MaxLabel-2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m, err := long.Prepend("a"); err != ErrNameTooLong {
+		t.Errorf("expected %q error but got %v (%q)", ErrNameTooLong, err, m)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	n, err := MakeNameFromString("wiki.ex\\.ample.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lbls := []string{}
+	for lbl := range n.Labels() {
+		lbls = append(lbls, lbl)
+	}
+
+	assertLabels(t, lbls, []string{"WIKI", "EX.AMPLE", "COM"})
+
+	lbls = []string{}
+	for lbl := range n.Labels() {
+		lbls = append(lbls, lbl)
+		break
+	}
+
+	assertLabels(t, lbls, []string{"WIKI"})
+
+	for lbl := range (Name{}).Labels() {
+		t.Errorf("expected no labels for root domain but got %q", lbl)
+	}
+
+	if a := testing.AllocsPerRun(10, func() {
+		for range n.Labels() {
+		}
+	}); a > 0 {
+		t.Errorf("expected no allocations but got %g", a)
+	}
+}
+
+func TestIsSubdomainOf(t *testing.T) {
+	assertSubdomain(t, "www.example.com", "Example.COM.", true)
+	assertSubdomain(t, "example.com", "example.com", true)
+	assertSubdomain(t, "example.com", ".", true)
+	assertSubdomain(t, "example.com", "www.example.com", false)
+	assertSubdomain(t, "wwwexample.com", "example.com", false)
+	assertSubdomain(t, "www.example.com", "ample.com", false)
+	assertSubdomain(t, ".", "com", false)
+}
+
+func TestCompare(t *testing.T) {
+	// Example from RFC 4034 section 6.1.
+	ordered := []string{
+		"example",
+		"a.example",
+		"yljkjljk.a.example",
+		"Z.a.example",
+		"zABC.a.EXAMPLE",
+		"z.example",
+		"\\001.z.example",
+		"*.z.example",
+		"\\200.z.example",
+	}
+
+	names := make([]Name, len(ordered))
+	for i, s := range ordered {
+		n, err := MakeNameFromString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names[i] = n
+	}
+
+	for i, a := range names {
+		for j, b := range names {
+			if r, e := a.Compare(b), cmpInts(i, j); r != e {
+				t.Errorf("expected %d for %q compared to %q but got %d", e, a, b, r)
+			}
+		}
+	}
+
+	a, _ := MakeNameFromString("a_.example")
+	b, _ := MakeNameFromString("aZ.example")
+	if r := a.Compare(b); r != -1 {
+		t.Errorf("expected %q to go before %q in canonical order but got %d", a, b, r)
+	}
+
+	if a := testing.AllocsPerRun(10, func() { names[3].Compare(names[4]) }); a > 0 {
+		t.Errorf("expected no allocations but got %g", a)
+	}
+}
+
+func assertPrepend(t *testing.T, n Name, label, e string, el []string) {
+	m, err := n.Prepend(label)
+	if err != nil {
+		t.Errorf("expected no error for label %q but got %s", label, err)
+		return
+	}
+
+	if m.String() != e {
+		t.Errorf("expected %q after prepending %q to %q but got %q", e, label, n, m)
+	}
+
+	lbls := []string{}
+	if err := m.GetLabels(func(lbl string) error {
+		lbls = append(lbls, lbl)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertLabels(t, lbls, el)
+}
+
+func assertSubdomain(t *testing.T, a, b string, e bool) {
+	n, err := MakeNameFromString(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := MakeNameFromString(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := n.IsSubdomainOf(m); r != e {
+		t.Errorf("expected %v for %q as subdomain of %q but got %v", e, a, b, r)
+	}
+}
+
+func cmpInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+
+	if a > b {
+		return 1
+	}
+
+	return 0
+}
+
 func assertLabels(t *testing.T, v, e []string) {
 	if len(v) != len(e) {
 		t.Errorf("expected %d labels\n\t%#v\nbut got %d\n\t%#v", len(e), e, len(v), v)
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalText implements encoding.TextMarshaler interface. It returns domain name in human-readable format.
+func (n Name) MarshalText() ([]byte, error) {
+	return []byte(n.h), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler interface. It parses human-readable domain name and returns an error for invalid one leaving the name unchanged.
+func (n *Name) UnmarshalText(b []byte) error {
+	m, err := MakeNameFromString(string(b))
+	if err != nil {
+		return fmt.Errorf("can't unmarshal %q as domain name: %w", b, err)
+	}
+
+	*n = m
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface. It encodes domain name as JSON string in human-readable format.
+func (n Name) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.h)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. It expects JSON string with domain name and ignores null as encoding/json does for other types.
+func (n *Name) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("can't unmarshal %s as domain name: %w", b, err)
+	}
+
+	return n.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+type testConfig struct {
+	Zone  Name   `json:"zone" yaml:"zone"`
+	Hosts []Name `json:"hosts" yaml:"hosts"`
+}
+
+func TestNameText(t *testing.T) {
+	var n Name
+	if err := n.UnmarshalText([]byte("Wiki.Example.com.")); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := n.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "Wiki.Example.com." {
+		t.Errorf("expected %q but got %q", "Wiki.Example.com.", b)
+	}
+
+	assertNameLabels(t, n, []string{"COM", "EXAMPLE", "WIKI"})
+
+	err = n.UnmarshalText([]byte("www..example.com"))
+	if !errors.Is(err, ErrEmptyLabel) {
+		t.Errorf("expected %q error but got %v", ErrEmptyLabel, err)
+	}
+
+	if n.String() != "Wiki.Example.com." {
+		t.Errorf("expected name to remain %q after error but got %q", "Wiki.Example.com.", n)
+	}
+}
+
+func TestNameJSON(t *testing.T) {
+	var c testConfig
+	if err := json.Unmarshal([]byte(`{"zone":"Example.com","hosts":["www.example.com","mail\\.box.example.com",null]}`), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNameLabels(t, c.Zone, []string{"COM", "EXAMPLE"})
+	if len(c.Hosts) != 3 {
+		t.Fatalf("expected 3 hosts but got %d", len(c.Hosts))
+	}
+
+	assertNameLabels(t, c.Hosts[1], []string{"COM", "EXAMPLE", "MAIL.BOX"})
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := `{"zone":"Example.com","hosts":["www.example.com","mail\\.box.example.com",""]}`
+	if string(b) != e {
+		t.Errorf("expected %s but got %s", e, b)
+	}
+
+	for _, s := range []string{
+		`{"zone":"www..example.com"}`,
+		`{"zone":"` + strings.Repeat("a", MaxLabel+1) + `.com"}`,
+		`{"zone":1}`,
+	} {
+		if err := json.Unmarshal([]byte(s), &c); err == nil {
+			t.Errorf("expected error for %s but got nothing", s)
+		}
+	}
+
+	err = json.Unmarshal([]byte(`{"zone":"www.ex\\9x.com"}`), &c)
+	if !errors.Is(err, ErrInvalidEscape) {
+		t.Errorf("expected %q error but got %v", ErrInvalidEscape, err)
+	}
+}
+
+func TestNameYAML(t *testing.T) {
+	var c testConfig
+	if err := yaml.Unmarshal([]byte("zone: Example.com\nhosts:\n- www.example.com\n- mail.example.com\n"), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNameLabels(t, c.Zone, []string{"COM", "EXAMPLE"})
+	if len(c.Hosts) != 2 {
+		t.Fatalf("expected 2 hosts but got %d", len(c.Hosts))
+	}
+
+	assertNameLabels(t, c.Hosts[1], []string{"COM", "EXAMPLE", "MAIL"})
+
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := "zone: Example.com\nhosts:\n- www.example.com\n- mail.example.com\n"
+	if string(b) != e {
+		t.Errorf("expected %q but got %q", e, b)
+	}
+
+	if err := yaml.Unmarshal([]byte("zone: www..example.com\n"), &c); err == nil {
+		t.Error("expected error for invalid domain name but got nothing")
+	}
+}
+
+func assertNameLabels(t *testing.T, n Name, e []string) {
+	lbls := []string{}
+	if err := n.GetLabels(func(lbl string) error {
+		lbls = append(lbls, lbl)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertLabels(t, lbls, e)
+}
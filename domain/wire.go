@@ -0,0 +1,177 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrUnexpectedEnd is returned when wire format domain name goes beyond end of the message.
+	ErrUnexpectedEnd = errors.New("unexpected end of message")
+	// ErrInvalidPointer is returned for compression pointer which doesn't point before all parts of the name read so far (such pointers may cause loops).
+	ErrInvalidPointer = errors.New("invalid compression pointer")
+	// ErrInvalidLabelType is returned for wire format label which is neither regular label nor compression pointer.
+	ErrInvalidLabelType = errors.New("invalid label type")
+)
+
+const (
+	wireLabelTypeMask = 0xc0
+	wirePointer       = 0xc0
+)
+
+// MakeNameFromWire creates a Name from RFC 1035 wire format domain name which starts at given offset of the message. Compression pointers are followed only if they point before all parts of the name read so far so malformed message can't cause a loop. The name is limited to MaxName bytes of labels with their length bytes (without final zero byte) as names made by MakeNameFromString. The method returns the name and offset of the first byte after the name at its original position.
+func MakeNameFromWire(msg []byte, off int) (Name, int, error) {
+	var offs [MaxLabels]int
+
+	n := 0
+	size := 0
+	next := -1
+	start := off
+	for {
+		if off < 0 || off >= len(msg) {
+			return Name{}, 0, ErrUnexpectedEnd
+		}
+
+		c := int(msg[off])
+		if c&wireLabelTypeMask == wirePointer {
+			if off+1 >= len(msg) {
+				return Name{}, 0, ErrUnexpectedEnd
+			}
+
+			if next < 0 {
+				next = off + 2
+			}
+
+			ptr := (c&^wireLabelTypeMask)<<8 | int(msg[off+1])
+			if ptr >= start {
+				return Name{}, 0, ErrInvalidPointer
+			}
+
+			off = ptr
+			start = ptr
+			continue
+		}
+
+		if c&wireLabelTypeMask != 0 {
+			return Name{}, 0, ErrInvalidLabelType
+		}
+
+		if c == 0 {
+			if next < 0 {
+				next = off + 1
+			}
+
+			break
+		}
+
+		if off+1+c > len(msg) {
+			return Name{}, 0, ErrUnexpectedEnd
+		}
+
+		size += c + 1
+		if size > MaxName {
+			return Name{}, 0, ErrNameTooLong
+		}
+
+		if n >= len(offs) {
+			return Name{}, 0, ErrTooManyLabels
+		}
+
+		offs[n] = off
+		n++
+
+		off += c + 1
+	}
+
+	if n <= 0 {
+		return Name{h: "."}, next, nil
+	}
+
+	var (
+		name  [MaxName]byte
+		human [4 * MaxName]byte
+	)
+
+	j := 0
+	for i := n - 1; i >= 0; i-- {
+		label := msg[offs[i] : offs[i]+1+int(msg[offs[i]])]
+
+		name[j] = label[0]
+		j++
+
+		for _, c := range label[1:] {
+			if c >= 'a' && c <= 'z' {
+				c &= 0xdf
+			}
+
+			name[j] = c
+			j++
+		}
+	}
+
+	k := 0
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			human[k] = '.'
+			k++
+		}
+
+		k += escapeLabel(human[k:], msg[offs[i]+1:offs[i]+1+int(msg[offs[i]])])
+	}
+
+	return Name{h: string(human[:k]), c: string(name[:j])}, next, nil
+}
+
+// AppendWire appends RFC 1035 wire format of the name without compression to given buffer. Labels are written in lower case as in canonical form of RFC 4034.
+func (n Name) AppendWire(buf []byte) []byte {
+	var offs [MaxLabels]int
+
+	m := 0
+	for off := 0; off < len(n.c) && m < len(offs); off += int(n.c[off]) + 1 {
+		offs[m] = off
+		m++
+	}
+
+	for i := m - 1; i >= 0; i-- {
+		off := offs[i]
+		end := off + 1 + int(n.c[off])
+		if end > len(n.c) {
+			end = len(n.c)
+		}
+
+		buf = append(buf, byte(end-off-1))
+		for k := off + 1; k < end; k++ {
+			c := n.c[k]
+			if c >= 'A' && c <= 'Z' {
+				c |= 0x20
+			}
+
+			buf = append(buf, c)
+		}
+	}
+
+	return append(buf, 0)
+}
+
+// escapeLabel writes human-readable representation of given label to out and returns number of bytes written. It escapes characters in the same way as MakeHumanReadableLabel but keeps case of letters. The out buffer should be at least 4 times longer than the label.
+func escapeLabel(out []byte, label []byte) int {
+	j := 0
+	for _, c := range label {
+		switch {
+		case c == '.' || c == '\\':
+			out[j] = '\\'
+			out[j+1] = c
+			j += 2
+
+		case c < '!' || c > '~':
+			out[j] = '\\'
+			out[j+1] = c/100 + '0'
+			out[j+2] = c/10%10 + '0'
+			out[j+3] = c%10 + '0'
+			j += 4
+
+		default:
+			out[j] = c
+			j++
+		}
+	}
+
+	return j
+}
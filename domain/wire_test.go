@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMakeNameFromWire(t *testing.T) {
+	msg := []byte{
+		0xff, 0xff,
+		3, 'W', 'w', 'w', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0,
+		4, 'm', 'a', 'i', 'l', 0xc0, 6,
+		0xc0, 19,
+		3, 'a', '.', 'b', 1, ' ', 0xc0, 14,
+		0,
+	}
+
+	assertWireName(t, msg, 2, "Www.example.com", 19)
+	assertWireName(t, msg, 19, "mail.example.com", 26)
+	assertWireName(t, msg, 26, "mail.example.com", 28)
+	assertWireName(t, msg, 28, "a\\.b.\\032.com", 36)
+	assertWireName(t, msg, 36, ".", 37)
+
+	a, _, err := MakeNameFromWire(msg, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := MakeNameFromString("EXAMPLE.COM.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.c != b.c {
+		t.Errorf("expected the same labels for wire and string names but got %q and %q", a.c, b.c)
+	}
+
+	if a := testing.AllocsPerRun(10, func() { MakeNameFromWire(msg, 19) }); a > 2 {
+		t.Errorf("expected at most 2 allocations but got %g", a)
+	}
+}
+
+func TestMakeNameFromWireWithErrors(t *testing.T) {
+	assertWireError(t, []byte{}, 0, ErrUnexpectedEnd)
+	assertWireError(t, []byte{3, 'c', 'o', 'm'}, 0, ErrUnexpectedEnd)
+	assertWireError(t, []byte{3, 'c', 'o'}, 0, ErrUnexpectedEnd)
+	assertWireError(t, []byte{0xc0}, 0, ErrUnexpectedEnd)
+	assertWireError(t, []byte{0}, -1, ErrUnexpectedEnd)
+	assertWireError(t, []byte{0}, 1, ErrUnexpectedEnd)
+	assertWireError(t, []byte{0xc0, 0}, 0, ErrInvalidPointer)
+	assertWireError(t, []byte{0, 0xc0, 2}, 1, ErrInvalidPointer)
+	assertWireError(t, []byte{1, 'a', 0xc0, 0}, 0, ErrInvalidPointer)
+	assertWireError(t, []byte{1, 'a', 0xc0, 4, 1, 'b', 0xc0, 0}, 4, ErrInvalidPointer)
+	assertWireError(t, []byte{0x40, 0}, 0, ErrInvalidLabelType)
+	assertWireError(t, []byte{0x80, 0}, 0, ErrInvalidLabelType)
+
+	long := []byte{}
+	for i := 0; i < 4; i++ {
+		long = append(long, 63)
+		long = append(long, bytes.Repeat([]byte{'a'}, 63)...)
+	}
+	assertWireError(t, append(long, 0), 0, ErrNameTooLong)
+
+	many := bytes.Repeat([]byte{1, 'a'}, MaxLabels)
+	if _, _, err := MakeNameFromWire(append(many, 0), 0); err != nil {
+		t.Errorf("expected no error for name with %d labels but got %s", MaxLabels, err)
+	}
+
+	assertWireError(t, append(append(many, 1, 'a'), 0), 0, ErrNameTooLong)
+}
+
+func TestAppendWire(t *testing.T) {
+	n, err := MakeNameFromString("Www.Ex\\.ample.COM.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := n.AppendWire([]byte{0xff})
+	e := []byte{0xff, 3, 'w', 'w', 'w', 8, 'e', 'x', '.', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0}
+	if !bytes.Equal(b, e) {
+		t.Errorf("expected %q for %q but got %q", e, n, b)
+	}
+
+	m, off, err := MakeNameFromWire(b, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if off != len(b) || m.c != n.c || m.String() != "www.ex\\.ample.com" {
+		t.Errorf("expected %q at %d after round trip but got %q at %d", "www.ex\\.ample.com", len(b), m, off)
+	}
+
+	var r Name
+	if b := r.AppendWire(nil); !bytes.Equal(b, []byte{0}) {
+		t.Errorf("expected single zero byte for root domain but got %q", b)
+	}
+
+	if a := testing.AllocsPerRun(10, func() { n.AppendWire(b[:0]) }); a > 0 {
+		t.Errorf("expected no allocations but got %g", a)
+	}
+}
+
+func TestWireRoundTripAtMaxName(t *testing.T) {
+	s := strings.Repeat(strings.Repeat("a", MaxLabel)+".", 3) + strings.Repeat("b", MaxLabel-1)
+
+	n, err := MakeNameFromString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(n.c) != MaxName {
+		t.Fatalf("expected %d bytes of labels for %q but got %d", MaxName, s, len(n.c))
+	}
+
+	b := n.AppendWire(nil)
+	m, off, err := MakeNameFromWire(b, 0)
+	if err != nil {
+		t.Fatalf("expected no error for name of maximum length but got %s", err)
+	}
+
+	if off != len(b) || m.c != n.c || m.String() != s {
+		t.Errorf("expected %q at %d after round trip but got %q at %d", s, len(b), m, off)
+	}
+
+	if _, err := MakeNameFromString("b." + s); err != ErrNameTooLong {
+		t.Errorf("expected %q error for name longer than maximum but got %v", ErrNameTooLong, err)
+	}
+
+	assertWireError(t, append([]byte{1, 'b'}, b...), 0, ErrNameTooLong)
+}
+
+func assertWireName(t *testing.T, msg []byte, off int, e string, enext int) {
+	n, next, err := MakeNameFromWire(msg, off)
+	if err != nil {
+		t.Errorf("expected no error for name at %d but got %s", off, err)
+		return
+	}
+
+	if n.String() != e || next != enext {
+		t.Errorf("expected %q with next offset %d for name at %d but got %q and %d", e, enext, off, n, next)
+	}
+
+	s, err := MakeNameFromString(e)
+	if err != nil {
+		t.Errorf("expected no error for %q but got %s", e, err)
+		return
+	}
+
+	if s.c != n.c {
+		t.Errorf("expected the same labels for %q from wire and string but got %q and %q", e, n.c, s.c)
+	}
+}
+
+func assertWireError(t *testing.T, msg []byte, off int, e error) {
+	n, _, err := MakeNameFromWire(msg, off)
+	if err != e {
+		t.Errorf("expected %q error for % x at %d but got %v (%q)", e, msg, off, err, n)
+	}
+}
@@ -3,41 +3,96 @@ package domaintree
 
 import (
 	"errors"
+	"iter"
+	"unsafe"
 
 	"github.com/infobloxopen/go-trees/dltree"
 	"github.com/infobloxopen/go-trees/domain"
 )
 
-// Node is a radix tree for domain names.
-type Node struct {
-	branches *dltree.Tree
+// NodeOf is a radix tree for domain names with values of type V.
+type NodeOf[V any] struct {
+	branches *dltree.TreeOf[*NodeOf[V]]
+
+	hasValue  bool
+	value     V
+	exactOnly bool
 
-	hasValue bool
-	value    interface{}
+	hasWildcard bool
+	wildcard    V
+
+	count int
 }
 
-// Pair represents a key-value pair returned by Enumerate method.
-type Pair struct {
+// Node is a radix tree for domain names.
+type Node = NodeOf[interface{}]
+
+// PairOf represents a key-value pair returned by Enumerate method.
+type PairOf[V any] struct {
 	// Key is a human-readable representation of domain name.
 	Key string
 	// Value stores data related to the name.
-	Value interface{}
+	Value V
+	// Kind shows which names the value matches. Values added by Insert have MatchBoth kind.
+	Kind MatchKind
+}
+
+// Pair represents a key-value pair returned by Enumerate method.
+type Pair = PairOf[interface{}]
+
+// MatchKind defines which names match domain of a tree entry. It's also used to select entries for GetWithKind.
+type MatchKind uint8
+
+const (
+	// MatchExact is a kind of entry which matches the domain itself only.
+	MatchExact MatchKind = 1 << iota
+	// MatchWildcard is a kind of entry which matches subdomains of the domain but not the domain itself (like "*.example.com" record of DNS RPZ). Such entries are listed by Enumerate with "*." prefix.
+	MatchWildcard
+	// MatchBoth is a kind of entry which matches the domain and all its subdomains. Insert and InplaceInsert add entries of the kind.
+	MatchBoth = MatchExact | MatchWildcard
+)
+
+// Stats holds tree statistics returned by Stats method.
+type Stats struct {
+	// Nodes is a number of all nodes in the tree (one node per domain label).
+	Nodes int
+	// Leaves is a number of nodes which hold values.
+	Leaves int
+	// MaxDepth is a number of nodes on the longest path from the root (root itself has depth 1).
+	MaxDepth int
+	// Bytes is an estimated memory size of the nodes and their label trees. It doesn't include memory referenced by values.
+	Bytes int
 }
 
 var errStopIterations = errors.New("stop iterations")
 
-// Insert puts value using given domain as a key. The method returns new tree (old one remains unaffected).
-func (n *Node) Insert(d domain.Name, v interface{}) *Node {
+// Insert puts value using given domain as a key. The value matches the domain and all its subdomains. The method returns new tree (old one remains unaffected).
+func (n *NodeOf[V]) Insert(d domain.Name, v V) *NodeOf[V] {
+	return n.InsertWithKind(d, v, MatchBoth)
+}
+
+// InsertWithKind puts value of given kind using given domain as a key. Exact and wildcard values of the same domain are kept separately while MatchBoth value replaces both of them. The method returns new tree (old one remains unaffected). It returns the same tree if kind is not one of MatchExact, MatchWildcard or MatchBoth.
+func (n *NodeOf[V]) InsertWithKind(d domain.Name, v V, k MatchKind) *NodeOf[V] {
+	if k == 0 || k&^MatchBoth != 0 {
+		return n
+	}
+
+	var path [domain.MaxLabels + 1]*NodeOf[V]
+
 	n = n.copy()
 	r := n
 
+	i := 0
 	d.GetLabels(func(label string) error {
+		path[i] = n
+		i++
+
 		item, ok := n.branches.RawGet(label)
-		var next *Node
+		var next *NodeOf[V]
 		if ok {
-			next = item.(*Node).copy()
+			next = item.copy()
 		} else {
-			next = new(Node)
+			next = new(NodeOf[V])
 		}
 
 		n.branches = n.branches.RawInsert(label, next)
@@ -46,24 +101,37 @@ func (n *Node) Insert(d domain.Name, v interface{}) *Node {
 		return nil
 	})
 
-	n.hasValue = true
-	n.value = v
-
+	addCount(path[:i], n, n.set(v, k))
 	return r
 }
 
-// InplaceInsert puts or replaces value using given domain as a key. The method inserts data directly to current tree so make sure you have exclusive access to it.
-func (n *Node) InplaceInsert(d domain.Name, v interface{}) {
+// InplaceInsert puts or replaces value using given domain as a key. The value matches the domain and all its subdomains. The method inserts data directly to current tree so make sure you have exclusive access to it.
+func (n *NodeOf[V]) InplaceInsert(d domain.Name, v V) {
+	n.InplaceInsertWithKind(d, v, MatchBoth)
+}
+
+// InplaceInsertWithKind puts or replaces value of given kind using given domain as a key in the same way as InsertWithKind. The method inserts data directly to current tree so make sure you have exclusive access to it.
+func (n *NodeOf[V]) InplaceInsertWithKind(d domain.Name, v V, k MatchKind) {
+	if k == 0 || k&^MatchBoth != 0 {
+		return
+	}
+
 	if n.branches == nil {
-		n.branches = dltree.NewTree()
+		n.branches = dltree.NewTreeOf[*NodeOf[V]]()
 	}
 
+	var path [domain.MaxLabels + 1]*NodeOf[V]
+
+	i := 0
 	d.GetLabels(func(label string) error {
+		path[i] = n
+		i++
+
 		item, ok := n.branches.RawGet(label)
 		if ok {
-			n = item.(*Node)
+			n = item
 		} else {
-			next := &Node{branches: dltree.NewTree()}
+			next := &NodeOf[V]{branches: dltree.NewTreeOf[*NodeOf[V]]()}
 			n.branches.RawInplaceInsert(label, next)
 			n = next
 		}
@@ -71,150 +139,438 @@ func (n *Node) InplaceInsert(d domain.Name, v interface{}) {
 		return nil
 	})
 
-	n.hasValue = true
-	n.value = v
+	addCount(path[:i], n, n.set(v, k))
 }
 
 // Enumerate returns key-value pairs in given tree. It lists domains in the same order for the same tree.
-func (n *Node) Enumerate() chan Pair {
-	ch := make(chan Pair)
+func (n *NodeOf[V]) Enumerate() chan PairOf[V] {
+	ch := make(chan PairOf[V])
 
 	go func() {
 		defer close(ch)
-		n.enumerate("", ch)
+
+		n.walk("", func(k string, v V, kind MatchKind) bool {
+			ch <- PairOf[V]{Key: k, Value: v, Kind: kind}
+			return true
+		})
 	}()
 
 	return ch
 }
 
-// Get gets value for given domain which is equal to domain in the tree or is a subdomain of existing domain.
-func (n *Node) Get(d domain.Name) (interface{}, bool) {
-	if n == nil {
-		return nil, false
+// All returns iterator over key-value pairs in given tree. It lists domains in the same order as Enumerate.
+func (n *NodeOf[V]) All() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		n.Walk(yield)
 	}
+}
 
-	var value interface{}
-	hasValue := false
+// Walk calls f for key-value pairs in given tree until f returns false. It lists domains in the same order as Enumerate and reports if all the pairs have been visited.
+func (n *NodeOf[V]) Walk(f func(string, V) bool) bool {
+	return n.walk("", func(k string, v V, _ MatchKind) bool {
+		return f(k, v)
+	})
+}
 
-	d.GetLabels(func(label string) error {
-		item, ok := n.branches.RawGet(label)
-		if !ok {
-			return errStopIterations
-		}
+// EnumerateSubdomains returns channel which is populated by key-value pairs for given domain and all its subdomains. Keys are fully qualified domains in the same form as Enumerate returns.
+func (n *NodeOf[V]) EnumerateSubdomains(d domain.Name) chan PairOf[V] {
+	ch := make(chan PairOf[V])
 
-		n = item.(*Node)
-		if n.hasValue {
-			value = n.value
-			hasValue = true
-		}
-		return nil
-	})
+	go func() {
+		defer close(ch)
 
-	return value, hasValue
+		n.walkSubdomains(d, func(k string, v V, kind MatchKind) bool {
+			ch <- PairOf[V]{Key: k, Value: v, Kind: kind}
+			return true
+		})
+	}()
+
+	return ch
+}
+
+// Subdomains returns iterator over key-value pairs for given domain and all its subdomains. It lists domains in the same order as EnumerateSubdomains and walks only branch of the tree which starts at given domain.
+func (n *NodeOf[V]) Subdomains(d domain.Name) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		n.walkSubdomains(d, func(k string, v V, _ MatchKind) bool {
+			return yield(k, v)
+		})
+	}
+}
+
+// Get gets value for given domain which is equal to domain in the tree or is a subdomain of existing domain.
+func (n *NodeOf[V]) Get(d domain.Name) (V, bool) {
+	v, _, ok := n.GetWithKind(d, MatchBoth)
+	return v, ok
+}
+
+// GetWithKind gets value for given domain considering only entries which match names in the way allowed by given kind. With MatchExact it looks for value of the domain itself, with MatchWildcard for value of the closest parent domain which covers its subdomains and with MatchBoth for the closest of them. It returns the value, MatchExact or MatchWildcard depending on which kind of entry has matched and flag if any value has been found.
+func (n *NodeOf[V]) GetWithKind(d domain.Name, k MatchKind) (V, MatchKind, bool) {
+	v, kind, _ := n.get(d, k)
+	return v, kind, kind != 0
+}
+
+// GetWithName gets value for given domain in the same way as Get. Additionally it returns domain of the matched entry (which is the domain itself or one of its parents) and number of labels in the matched domain.
+func (n *NodeOf[V]) GetWithName(d domain.Name) (V, domain.Name, int, bool) {
+	v, kind, depth := n.get(d, MatchBoth)
+	if kind == 0 {
+		return v, domain.Name{}, 0, false
+	}
+
+	return v, d.Suffix(depth), depth, true
 }
 
 // DeleteSubdomains removes current domain and all its subdomains if any. It returns new tree and flag if deletion indeed occurs.
-func (n *Node) DeleteSubdomains(d domain.Name) (*Node, bool) {
+func (n *NodeOf[V]) DeleteSubdomains(d domain.Name) (*NodeOf[V], bool) {
 	if n == nil {
 		return nil, false
 	}
 
 	var (
 		labels [domain.MaxLabels]string
-		nodes  [domain.MaxLabels]*Node
+		nodes  [domain.MaxLabels]*NodeOf[V]
 	)
 
 	i := n.getBranch(d, labels[:], nodes[:])
-	if i >= len(nodes) || !nodes[i].hasValue && n.branches.IsEmpty() {
+	if i >= len(nodes) || nodes[i].entries() == 0 && n.branches.IsEmpty() {
 		return n, false
 	}
 
 	i++
 	if i >= len(nodes) {
-		return new(Node), true
+		return new(NodeOf[V]), true
 	}
 
+	c := nodes[i-1].count
+
 	n = nodes[i].copy()
 	n.branches, _ = n.branches.RawDelete(labels[i])
+	n.count -= c
 	i++
 
 	return n.copyBranch(labels[i:], nodes[i:]), true
 }
 
 // Delete removes current domain only. It returns new tree and flag if deletion indeed occurs.
-func (n *Node) Delete(d domain.Name) (*Node, bool) {
-	if n == nil {
-		return nil, false
+func (n *NodeOf[V]) Delete(d domain.Name) (*NodeOf[V], bool) {
+	return n.DeleteWithKind(d, MatchBoth)
+}
+
+// DeleteWithKind removes entries of given kind for current domain only. With MatchExact it removes value of the domain itself, with MatchWildcard value for its subdomains and with MatchBoth both of them (as Delete does). If MatchBoth entry loses one of its parts, the other part remains in the tree (for example after deletion of exact part subdomains of the domain still match its value). The method returns new tree and flag if deletion indeed occurs. It returns the same tree and false if kind is not one of MatchExact, MatchWildcard or MatchBoth.
+func (n *NodeOf[V]) DeleteWithKind(d domain.Name, k MatchKind) (*NodeOf[V], bool) {
+	if n == nil || k == 0 || k&^MatchBoth != 0 {
+		return n, false
 	}
 
 	var (
 		labels [domain.MaxLabels]string
-		nodes  [domain.MaxLabels]*Node
+		nodes  [domain.MaxLabels]*NodeOf[V]
 	)
 
 	i := n.getBranch(d, labels[:], nodes[:])
-	if i >= len(nodes) || !nodes[i].hasValue {
+	if i >= len(nodes) {
 		return n, false
 	}
 
-	n = nodes[i]
+	m := nodes[i].copy()
+	c, ok := m.unset(k)
+	if !ok {
+		return n, false
+	}
+
+	m.count += c
 	i++
 
-	branches := n.branches
 	if i >= len(nodes) {
-		if branches.IsEmpty() {
-			return new(Node), true
+		if m.entries() == 0 && m.branches.IsEmpty() {
+			return new(NodeOf[V]), true
 		}
 
-		return &Node{branches: branches}, true
+		return m, true
 	}
 
-	n = nodes[i].copy()
-	if branches.IsEmpty() {
-		n.branches, _ = n.branches.RawDelete(labels[i])
-	} else {
-		n.branches = n.branches.RawInsert(labels[i], &Node{branches: branches})
+	return m.copyBranch(labels[i:], nodes[i:]), true
+}
+
+// Merge returns new tree which contains domains of both the tree and given tree. For domain present in both trees resolve is called with the domain (in the same form as Enumerate keys) and both values (value from the tree goes first) to get value for the result. Neither tree is modified and branches which have no counterpart in the other tree are shared with the result.
+func (n *NodeOf[V]) Merge(m *NodeOf[V], resolve func(d string, a, b V) V) *NodeOf[V] {
+	return n.merge("", m, resolve)
+}
+
+// Len returns number of entries in the tree. Exact and wildcard entries of the same domain are counted separately. It takes constant time.
+func (n *NodeOf[V]) Len() int {
+	if n == nil {
+		return 0
 	}
-	i++
 
-	return n.copyBranch(labels[i:], nodes[i:]), true
+	return n.count
 }
 
-func (n *Node) copy() *Node {
+// Stats walks the tree and collects its statistics.
+func (n *NodeOf[V]) Stats() Stats {
+	var s Stats
+	n.stats(1, &s)
+	return s
+}
+
+func (n *NodeOf[V]) walkSubdomains(d domain.Name, f func(string, V, MatchKind) bool) bool {
 	if n == nil {
-		return new(Node)
+		return true
 	}
 
-	return &Node{
-		branches: n.branches,
-		hasValue: n.hasValue,
-		value:    n.value,
+	var (
+		labels [domain.MaxLabels]string
+		nodes  [domain.MaxLabels]*NodeOf[V]
+	)
+
+	i := n.getBranch(d, labels[:], nodes[:])
+	if i >= len(nodes) {
+		return true
+	}
+
+	s := ""
+	for _, label := range labels[i+1:] {
+		if len(s) > 0 {
+			s += "."
+		}
+
+		s += domain.MakeHumanReadableLabel(label)
 	}
+
+	return nodes[i].walk(s, f)
 }
 
-func (n *Node) enumerate(s string, ch chan Pair) {
+func (n *NodeOf[V]) get(d domain.Name, k MatchKind) (V, MatchKind, int) {
+	var (
+		value V
+		kind  MatchKind
+	)
+
 	if n == nil {
-		return
+		return value, kind, 0
+	}
+
+	i := 0
+	depth := 0
+	err := d.GetLabels(func(label string) error {
+		if i > 0 && k&MatchWildcard != 0 {
+			if v, ok := n.wildcardValue(); ok {
+				value = v
+				kind = MatchWildcard
+				depth = i
+			}
+		}
+
+		item, ok := n.branches.RawGet(label)
+		if !ok {
+			return errStopIterations
+		}
+
+		n = item
+		i++
+		return nil
+	})
+
+	if err == nil && i > 0 && k&MatchExact != 0 && n.hasValue {
+		value = n.value
+		kind = MatchExact
+		depth = i
+	}
+
+	return value, kind, depth
+}
+
+func (n *NodeOf[V]) copy() *NodeOf[V] {
+	if n == nil {
+		return new(NodeOf[V])
 	}
 
+	return &NodeOf[V]{
+		branches:    n.branches,
+		hasValue:    n.hasValue,
+		value:       n.value,
+		exactOnly:   n.exactOnly,
+		hasWildcard: n.hasWildcard,
+		wildcard:    n.wildcard,
+		count:       n.count,
+	}
+}
+
+func (n *NodeOf[V]) entries() int {
+	e := 0
 	if n.hasValue {
-		ch <- Pair{
-			Key:   s,
-			Value: n.value}
+		e++
+	}
+
+	if n.hasWildcard {
+		e++
+	}
+
+	return e
+}
+
+func (n *NodeOf[V]) wildcardValue() (V, bool) {
+	if n.hasWildcard {
+		return n.wildcard, true
+	}
+
+	return n.value, n.hasValue && !n.exactOnly
+}
+
+// set puts value of given kind to the node and returns change in number of the node entries.
+func (n *NodeOf[V]) set(v V, k MatchKind) int {
+	e := n.entries()
+
+	switch k {
+	case MatchBoth:
+		var w V
+
+		n.exactOnly = false
+		n.hasWildcard = false
+		n.wildcard = w
+
+	case MatchExact:
+		if w, ok := n.wildcardValue(); ok {
+			n.hasWildcard = true
+			n.wildcard = w
+		}
+
+		n.exactOnly = true
+
+	case MatchWildcard:
+		n.exactOnly = true
+		n.hasWildcard = true
+		n.wildcard = v
+		return n.entries() - e
+	}
+
+	n.hasValue = true
+	n.value = v
+	return n.entries() - e
+}
+
+// unset removes values of given kind from the node. It returns change in number of the node entries and flag if any value has been removed.
+func (n *NodeOf[V]) unset(k MatchKind) (int, bool) {
+	e := n.entries()
+	ok := false
+
+	if k&MatchWildcard != 0 {
+		if n.hasWildcard {
+			var w V
+
+			n.hasWildcard = false
+			n.wildcard = w
+			ok = true
+		} else if n.hasValue && !n.exactOnly {
+			n.exactOnly = true
+			ok = true
+		}
+	}
+
+	if k&MatchExact != 0 && n.hasValue {
+		if !n.exactOnly {
+			n.hasWildcard = true
+			n.wildcard = n.value
+			n.exactOnly = true
+		}
+
+		var v V
+
+		n.hasValue = false
+		n.value = v
+		ok = true
+	}
+
+	return n.entries() - e, ok
+}
+
+func (n *NodeOf[V]) walk(s string, f func(string, V, MatchKind) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if n.hasValue {
+		k := MatchBoth
+		if n.exactOnly {
+			k = MatchExact
+		}
+
+		if !f(s, n.value, k) {
+			return false
+		}
+	}
+
+	if n.hasWildcard && !f(makeWildcardKey(s), n.wildcard, MatchWildcard) {
+		return false
 	}
 
-	for item := range n.branches.Enumerate() {
-		sub := item.Key
+	for k, v := range n.branches.All() {
 		if len(s) > 0 {
-			sub += "." + s
+			k += "." + s
 		}
-		node := item.Value.(*Node)
 
-		node.enumerate(sub, ch)
+		if !v.walk(k, f) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (n *NodeOf[V]) merge(s string, m *NodeOf[V], resolve func(d string, a, b V) V) *NodeOf[V] {
+	if n == nil {
+		return m
+	}
+
+	if m == nil {
+		return n
+	}
+
+	r := n.copy()
+	if n.entries() == 0 {
+		r.hasValue = m.hasValue
+		r.value = m.value
+		r.exactOnly = m.exactOnly
+		r.hasWildcard = m.hasWildcard
+		r.wildcard = m.wildcard
+	} else if n.hasValue && !n.exactOnly && m.hasValue && !m.exactOnly {
+		r.value = resolve(s, n.value, m.value)
+	} else if m.entries() > 0 {
+		e, eok := mergeValue(s, n.value, n.hasValue, m.value, m.hasValue, resolve)
+
+		nw, nok := n.wildcardValue()
+		mw, mok := m.wildcardValue()
+		w, wok := mergeValue(makeWildcardKey(s), nw, nok, mw, mok, resolve)
+
+		r = &NodeOf[V]{branches: n.branches, count: n.count}
+		if eok {
+			r.set(e, MatchExact)
+		}
+
+		if wok {
+			r.set(w, MatchWildcard)
+		}
 	}
+	r.count += r.entries() - n.entries()
+
+	for k, v := range m.branches.RawAll() {
+		if c, ok := n.branches.RawGet(k); ok {
+			d := domain.MakeHumanReadableLabel(k)
+			if len(s) > 0 {
+				d += "." + s
+			}
+
+			v = c.merge(d, v, resolve)
+			r.count -= c.Len()
+		}
+
+		r.branches = r.branches.RawInsert(k, v)
+		r.count += v.Len()
+	}
+
+	return r
 }
 
-func (n *Node) getBranch(d domain.Name, labels []string, nodes []*Node) int {
+func (n *NodeOf[V]) getBranch(d domain.Name, labels []string, nodes []*NodeOf[V]) int {
 	i := len(labels) - 1
 	nodes[i] = n
 
@@ -226,7 +582,7 @@ func (n *Node) getBranch(d domain.Name, labels []string, nodes []*Node) int {
 			return errStopIterations
 		}
 
-		n = next.(*Node)
+		n = next
 
 		i--
 		nodes[i] = n
@@ -238,10 +594,13 @@ func (n *Node) getBranch(d domain.Name, labels []string, nodes []*Node) int {
 	return i
 }
 
-func (n *Node) copyBranch(labels []string, nodes []*Node) *Node {
+func (n *NodeOf[V]) copyBranch(labels []string, nodes []*NodeOf[V]) *NodeOf[V] {
 	for i, p := range nodes {
+		old, _ := p.branches.RawGet(labels[i])
+
 		p = p.copy()
-		if !n.hasValue && n.branches.IsEmpty() {
+		p.count += n.Len() - old.Len()
+		if n.entries() == 0 && n.branches.IsEmpty() {
 			p.branches, _ = p.branches.RawDelete(labels[i])
 		} else {
 			p.branches = p.branches.RawInsert(labels[i], n)
@@ -252,3 +611,59 @@ func (n *Node) copyBranch(labels []string, nodes []*Node) *Node {
 
 	return n
 }
+
+func (n *NodeOf[V]) stats(depth int, s *Stats) {
+	if n == nil {
+		return
+	}
+
+	s.Nodes++
+	if n.entries() > 0 {
+		s.Leaves++
+	}
+
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	s.Bytes += int(unsafe.Sizeof(*n))
+	if n.branches != nil {
+		s.Bytes += int(unsafe.Sizeof(*n.branches)) + n.branches.Stats().Bytes
+	}
+
+	for _, c := range n.branches.RawAll() {
+		c.stats(depth+1, s)
+	}
+}
+
+func addCount[V any](path []*NodeOf[V], n *NodeOf[V], c int) {
+	if c == 0 {
+		return
+	}
+
+	for _, p := range path {
+		p.count += c
+	}
+
+	n.count += c
+}
+
+func mergeValue[V any](d string, a V, aok bool, b V, bok bool, resolve func(d string, a, b V) V) (V, bool) {
+	if aok && bok {
+		return resolve(d, a, b), true
+	}
+
+	if bok {
+		return b, true
+	}
+
+	return a, aok
+}
+
+func makeWildcardKey(s string) string {
+	if len(s) > 0 {
+		return "*." + s
+	}
+
+	return "*"
+}
@@ -314,6 +314,509 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestMerge(t *testing.T) {
+	resolve := func(d string, a, b interface{}) interface{} {
+		return fmt.Sprintf("%s: %s+%s", d, a, b)
+	}
+
+	var a *Node
+	a = a.Insert(makeTestDN(t, "com"), "a")
+	a = a.Insert(makeTestDN(t, "test.com"), "a")
+	a = a.Insert(makeTestDN(t, "www.test.com"), "a")
+	a = a.Insert(makeTestDN(t, "test.net"), "a")
+
+	var b *Node
+	b = b.Insert(makeTestDN(t, "."), "b")
+	b = b.Insert(makeTestDN(t, "test.com"), "b")
+	b = b.Insert(makeTestDN(t, "example.com"), "b")
+	b = b.Insert(makeTestDN(t, "test.org"), "b")
+
+	assertTree(a.Merge(nil, resolve), "merge with empty tree", t,
+		"\"com\": \"a\"\n",
+		"\"test.com\": \"a\"\n",
+		"\"www.test.com\": \"a\"\n",
+		"\"test.net\": \"a\"\n")
+
+	assertTree(a.Merge(b, resolve), "merged tree", t,
+		"\"\": \"b\"\n",
+		"\"com\": \"a\"\n",
+		"\"test.com\": \"test.com: a+b\"\n",
+		"\"www.test.com\": \"a\"\n",
+		"\"example.com\": \"b\"\n",
+		"\"test.net\": \"a\"\n",
+		"\"test.org\": \"b\"\n")
+
+	assertTree(a, "first tree after merge", t,
+		"\"com\": \"a\"\n",
+		"\"test.com\": \"a\"\n",
+		"\"www.test.com\": \"a\"\n",
+		"\"test.net\": \"a\"\n")
+
+	assertTree(b, "second tree after merge", t,
+		"\"\": \"b\"\n",
+		"\"test.com\": \"b\"\n",
+		"\"example.com\": \"b\"\n",
+		"\"test.org\": \"b\"\n")
+}
+
+func TestLen(t *testing.T) {
+	var r *Node
+	assertLen(r, 0, "nil tree", t)
+
+	r = r.Insert(makeTestDN(t, "com"), "1")
+	r = r.Insert(makeTestDN(t, "test.com"), "2")
+	r = r.Insert(makeTestDN(t, "test.net"), "3")
+	r = r.Insert(makeTestDN(t, "example.com"), "4")
+	r = r.Insert(makeTestDN(t, "www.test.com"), "5")
+	r = r.Insert(makeTestDN(t, "www.test.org"), "6")
+	assertLen(r, 6, "tree", t)
+
+	assertLen(r.Insert(makeTestDN(t, "test.com"), "7"), 6, "tree with replaced value", t)
+
+	c := new(Node)
+	for _, k := range []string{"com", "test.com", "test.net", "example.com", "www.test.com", "www.test.org"} {
+		c.InplaceInsert(makeTestDN(t, k), k)
+	}
+
+	c.InplaceInsert(makeTestDN(t, "ns.test.com"), "8")
+	c.InplaceInsert(makeTestDN(t, "www.test.org"), "9")
+	c.InplaceInsert(makeTestDN(t, "."), "10")
+	assertLen(c, 8, "tree after inplace insert", t)
+
+	d, ok := c.Delete(makeTestDN(t, "test.com"))
+	if !ok {
+		t.Error("Expected \"test.com\" to be deleted")
+	}
+	assertLen(d, 7, "tree after delete", t)
+
+	d, ok = d.Delete(makeTestDN(t, "test.com"))
+	if ok {
+		t.Error("Expected \"test.com\" to be not deleted as it's absent in the tree")
+	}
+	assertLen(d, 7, "tree after delete of absent domain", t)
+
+	d, ok = d.Delete(makeTestDN(t, "."))
+	if !ok {
+		t.Error("Expected root domain to be deleted")
+	}
+	assertLen(d, 6, "tree after root domain delete", t)
+
+	d, ok = d.Delete(makeTestDN(t, "www.test.org"))
+	if !ok {
+		t.Error("Expected \"www.test.org\" to be deleted")
+	}
+	assertLen(d, 5, "tree after leaf delete", t)
+
+	d, ok = d.DeleteSubdomains(makeTestDN(t, "test.com"))
+	if !ok {
+		t.Error("Expected \"test.com\" subdomains to be deleted")
+	}
+	assertLen(d, 3, "tree after subdomains delete", t)
+
+	d, _ = d.DeleteSubdomains(makeTestDN(t, "."))
+	assertLen(d, 0, "tree after cleanup", t)
+	assertLen(c, 8, "source tree after deletions", t)
+
+	var b *Node
+	b = b.Insert(makeTestDN(t, "."), "b")
+	b = b.Insert(makeTestDN(t, "test.com"), "b")
+	b = b.Insert(makeTestDN(t, "www.example.com"), "b")
+	b = b.Insert(makeTestDN(t, "test.org"), "b")
+
+	m := r.Merge(b, func(d string, a, b interface{}) interface{} { return a })
+	assertLen(m, 9, "merged tree", t)
+}
+
+func TestStats(t *testing.T) {
+	var r *Node
+	if s := r.Stats(); s != (Stats{}) {
+		t.Errorf("Expected empty stats for nil tree but got %#v", s)
+	}
+
+	r = r.Insert(makeTestDN(t, "com"), "1")
+	r = r.Insert(makeTestDN(t, "www.test.com"), "2")
+	r = r.Insert(makeTestDN(t, "test.net"), "3")
+
+	s := r.Stats()
+	if s.Nodes != 6 || s.Leaves != 3 || s.MaxDepth != 4 || s.Bytes <= 0 {
+		t.Errorf("Expected 6 nodes, 3 leaves and depth 4 but got %#v", s)
+	}
+}
+
+func TestInsertWithKind(t *testing.T) {
+	var r *Node
+
+	r = r.InsertWithKind(makeTestDN(t, "abc.com"), "exact", MatchExact)
+	r = r.InsertWithKind(makeTestDN(t, "abc.com"), "wildcard", MatchWildcard)
+	r = r.InsertWithKind(makeTestDN(t, "test.com"), "both", MatchBoth)
+	r = r.InsertWithKind(makeTestDN(t, "test.net"), "wildcard", MatchWildcard)
+	r = r.InsertWithKind(makeTestDN(t, "test.org"), "invalid", 0)
+	r = r.InsertWithKind(makeTestDN(t, "test.org"), "invalid", MatchBoth+1)
+
+	assertTree(r, "tree with exact and wildcard entries", t,
+		"\"abc.com\": \"exact\"\n",
+		"\"*.abc.com\": \"wildcard\"\n",
+		"\"test.com\": \"both\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(r, 4, "tree with exact and wildcard entries", t)
+
+	kinds := []MatchKind{}
+	for p := range r.Enumerate() {
+		kinds = append(kinds, p.Kind)
+	}
+
+	if fmt.Sprint(kinds) != fmt.Sprint([]MatchKind{MatchExact, MatchWildcard, MatchBoth, MatchWildcard}) {
+		t.Errorf("Expected kinds of exact, wildcard, both and wildcard entries but got %v", kinds)
+	}
+
+	c := r.InsertWithKind(makeTestDN(t, "test.com"), "exact", MatchExact)
+	assertTree(c, "tree with both entry replaced by exact one", t,
+		"\"abc.com\": \"exact\"\n",
+		"\"*.abc.com\": \"wildcard\"\n",
+		"\"test.com\": \"exact\"\n",
+		"\"*.test.com\": \"both\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(c, 5, "tree with both entry replaced by exact one", t)
+
+	c = r.InsertWithKind(makeTestDN(t, "test.com"), "wildcard", MatchWildcard)
+	assertTree(c, "tree with both entry replaced by wildcard one", t,
+		"\"abc.com\": \"exact\"\n",
+		"\"*.abc.com\": \"wildcard\"\n",
+		"\"test.com\": \"both\"\n",
+		"\"*.test.com\": \"wildcard\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(c, 5, "tree with both entry replaced by wildcard one", t)
+
+	c = r.Insert(makeTestDN(t, "abc.com"), "both")
+	assertTree(c, "tree with exact and wildcard entries replaced by both one", t,
+		"\"abc.com\": \"both\"\n",
+		"\"test.com\": \"both\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(c, 3, "tree with exact and wildcard entries replaced by both one", t)
+
+	c = new(Node)
+	c.InplaceInsertWithKind(makeTestDN(t, "abc.com"), "exact", MatchExact)
+	c.InplaceInsertWithKind(makeTestDN(t, "abc.com"), "wildcard", MatchWildcard)
+	c.InplaceInsertWithKind(makeTestDN(t, "test.com"), "both", MatchBoth)
+	c.InplaceInsertWithKind(makeTestDN(t, "test.net"), "wildcard", MatchWildcard)
+	c.InplaceInsertWithKind(makeTestDN(t, "test.org"), "invalid", 0)
+	assertTree(c, "tree with inplace exact and wildcard entries", t,
+		"\"abc.com\": \"exact\"\n",
+		"\"*.abc.com\": \"wildcard\"\n",
+		"\"test.com\": \"both\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(c, 4, "tree with inplace exact and wildcard entries", t)
+
+	d, ok := r.Delete(makeTestDN(t, "abc.com"))
+	if !ok {
+		t.Error("Expected \"abc.com\" to be deleted")
+	}
+
+	assertTree(d, "tree without \"abc.com\"", t,
+		"\"test.com\": \"both\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(d, 2, "tree without \"abc.com\"", t)
+
+	d, ok = d.Delete(makeTestDN(t, "test.net"))
+	if !ok {
+		t.Error("Expected \"test.net\" to be deleted")
+	}
+	assertLen(d, 1, "tree without \"test.net\"", t)
+}
+
+func TestDeleteWithKind(t *testing.T) {
+	var r *Node
+
+	r = r.InsertWithKind(makeTestDN(t, "abc.com"), "exact", MatchExact)
+	r = r.InsertWithKind(makeTestDN(t, "abc.com"), "wildcard", MatchWildcard)
+	r = r.InsertWithKind(makeTestDN(t, "test.com"), "both", MatchBoth)
+	r = r.InsertWithKind(makeTestDN(t, "www.test.com"), "exact", MatchExact)
+	r = r.InsertWithKind(makeTestDN(t, "test.net"), "wildcard", MatchWildcard)
+
+	d, ok := r.DeleteWithKind(makeTestDN(t, "abc.com"), MatchWildcard)
+	if !ok {
+		t.Error("Expected \"*.abc.com\" to be deleted")
+	}
+
+	assertTree(d, "tree without \"*.abc.com\"", t,
+		"\"abc.com\": \"exact\"\n",
+		"\"test.com\": \"both\"\n",
+		"\"www.test.com\": \"exact\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(d, 4, "tree without \"*.abc.com\"", t)
+	assertValueWithKind(d, "www.abc.com", MatchBoth, "", 0, t)
+
+	d, ok = r.DeleteWithKind(makeTestDN(t, "abc.com"), MatchExact)
+	if !ok {
+		t.Error("Expected \"abc.com\" to be deleted")
+	}
+
+	assertTree(d, "tree without exact \"abc.com\"", t,
+		"\"*.abc.com\": \"wildcard\"\n",
+		"\"test.com\": \"both\"\n",
+		"\"www.test.com\": \"exact\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(d, 4, "tree without exact \"abc.com\"", t)
+
+	d, ok = r.DeleteWithKind(makeTestDN(t, "test.com"), MatchExact)
+	if !ok {
+		t.Error("Expected exact part of \"test.com\" to be deleted")
+	}
+
+	assertTree(d, "tree without exact part of \"test.com\"", t,
+		"\"abc.com\": \"exact\"\n",
+		"\"*.abc.com\": \"wildcard\"\n",
+		"\"*.test.com\": \"both\"\n",
+		"\"www.test.com\": \"exact\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(d, 5, "tree without exact part of \"test.com\"", t)
+	assertValueWithKind(d, "test.com", MatchBoth, "", 0, t)
+	assertValueWithKind(d, "ns.test.com", MatchBoth, "both", MatchWildcard, t)
+
+	d, ok = r.DeleteWithKind(makeTestDN(t, "test.com"), MatchWildcard)
+	if !ok {
+		t.Error("Expected wildcard part of \"test.com\" to be deleted")
+	}
+
+	assertTree(d, "tree without wildcard part of \"test.com\"", t,
+		"\"abc.com\": \"exact\"\n",
+		"\"*.abc.com\": \"wildcard\"\n",
+		"\"test.com\": \"both\"\n",
+		"\"www.test.com\": \"exact\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(d, 5, "tree without wildcard part of \"test.com\"", t)
+	assertValueWithKind(d, "test.com", MatchBoth, "both", MatchExact, t)
+	assertValueWithKind(d, "ns.test.com", MatchBoth, "", 0, t)
+
+	d, ok = d.DeleteWithKind(makeTestDN(t, "test.com"), MatchExact)
+	if !ok {
+		t.Error("Expected \"test.com\" to be deleted")
+	}
+
+	assertTree(d, "tree without \"test.com\"", t,
+		"\"abc.com\": \"exact\"\n",
+		"\"*.abc.com\": \"wildcard\"\n",
+		"\"www.test.com\": \"exact\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(d, 4, "tree without \"test.com\"", t)
+
+	d, ok = r.DeleteWithKind(makeTestDN(t, "test.net"), MatchWildcard)
+	if !ok {
+		t.Error("Expected \"*.test.net\" to be deleted")
+	}
+
+	assertTree(d, "tree without \"*.test.net\"", t,
+		"\"abc.com\": \"exact\"\n",
+		"\"*.abc.com\": \"wildcard\"\n",
+		"\"test.com\": \"both\"\n",
+		"\"www.test.com\": \"exact\"\n")
+	assertLen(d, 4, "tree without \"*.test.net\"", t)
+
+	d, ok = r.DeleteWithKind(makeTestDN(t, "test.net"), MatchExact)
+	if ok {
+		t.Error("Expected nothing to be deleted for exact \"test.net\"")
+	}
+
+	if d != r {
+		t.Error("Expected the same tree after failed deletion of exact \"test.net\"")
+	}
+
+	for _, k := range []MatchKind{0, MatchBoth + 1} {
+		d, ok = r.DeleteWithKind(makeTestDN(t, "test.com"), k)
+		if ok {
+			t.Errorf("Expected nothing to be deleted with invalid kind %d", k)
+		}
+
+		if d != r {
+			t.Errorf("Expected the same tree after deletion with invalid kind %d", k)
+		}
+	}
+
+	d, ok = r.DeleteWithKind(makeTestDN(t, "test.org"), MatchBoth)
+	if ok {
+		t.Error("Expected nothing to be deleted for \"test.org\"")
+	}
+
+	if d != r {
+		t.Error("Expected the same tree after failed deletion of \"test.org\"")
+	}
+
+	assertTree(r, "original tree", t,
+		"\"abc.com\": \"exact\"\n",
+		"\"*.abc.com\": \"wildcard\"\n",
+		"\"test.com\": \"both\"\n",
+		"\"www.test.com\": \"exact\"\n",
+		"\"*.test.net\": \"wildcard\"\n")
+	assertLen(r, 5, "original tree", t)
+
+	var e *Node
+	if d, ok := e.DeleteWithKind(makeTestDN(t, "test.com"), MatchBoth); ok || d != nil {
+		t.Errorf("Expected nothing to be deleted from empty tree but got %t and %#v", ok, d)
+	}
+}
+
+func TestGetWithKind(t *testing.T) {
+	var r *Node
+
+	r = r.InsertWithKind(makeTestDN(t, "abc.com"), "exact", MatchExact)
+	r = r.InsertWithKind(makeTestDN(t, "abc.com"), "wildcard", MatchWildcard)
+	r = r.InsertWithKind(makeTestDN(t, "test.com"), "both", MatchBoth)
+	r = r.InsertWithKind(makeTestDN(t, "www.test.com"), "exact", MatchExact)
+	r = r.InsertWithKind(makeTestDN(t, "test.net"), "wildcard", MatchWildcard)
+
+	assertValueWithKind(r, "abc.com", MatchBoth, "exact", MatchExact, t)
+	assertValueWithKind(r, "abc.com", MatchExact, "exact", MatchExact, t)
+	assertValueWithKind(r, "abc.com", MatchWildcard, "", 0, t)
+	assertValueWithKind(r, "www.abc.com", MatchBoth, "wildcard", MatchWildcard, t)
+	assertValueWithKind(r, "www.abc.com", MatchExact, "", 0, t)
+	assertValueWithKind(r, "ns.www.abc.com", MatchWildcard, "wildcard", MatchWildcard, t)
+
+	assertValueWithKind(r, "test.com", MatchBoth, "both", MatchExact, t)
+	assertValueWithKind(r, "test.com", MatchWildcard, "", 0, t)
+	assertValueWithKind(r, "www.test.com", MatchBoth, "exact", MatchExact, t)
+	assertValueWithKind(r, "www.test.com", MatchWildcard, "both", MatchWildcard, t)
+	assertValueWithKind(r, "ns.www.test.com", MatchBoth, "both", MatchWildcard, t)
+	assertValueWithKind(r, "ns.test.com", MatchExact, "", 0, t)
+
+	assertValueWithKind(r, "test.net", MatchBoth, "", 0, t)
+	assertValueWithKind(r, "www.test.net", MatchBoth, "wildcard", MatchWildcard, t)
+	assertValueWithKind(r, "test.org", MatchBoth, "", 0, t)
+	assertValueWithKind(r, "abc.com", 0, "", 0, t)
+
+	v, ok := r.Get(makeTestDN(t, "test.net"))
+	assertValue(v, ok, "", false, "fetching \"test.net\" from tree", t)
+
+	v, ok = r.Get(makeTestDN(t, "www.abc.com"))
+	assertValue(v, ok, "wildcard", true, "fetching \"www.abc.com\" from tree", t)
+
+	var e *Node
+	assertValueWithKind(e, "abc.com", MatchBoth, "", 0, t)
+}
+
+func TestMergeWithKind(t *testing.T) {
+	resolve := func(d string, a, b interface{}) interface{} {
+		return fmt.Sprintf("%s: %s+%s", d, a, b)
+	}
+
+	var a *Node
+	a = a.InsertWithKind(makeTestDN(t, "abc.com"), "a", MatchBoth)
+	a = a.InsertWithKind(makeTestDN(t, "test.com"), "a", MatchExact)
+	a = a.InsertWithKind(makeTestDN(t, "test.net"), "a", MatchBoth)
+
+	var b *Node
+	b = b.InsertWithKind(makeTestDN(t, "abc.com"), "b", MatchWildcard)
+	b = b.InsertWithKind(makeTestDN(t, "test.com"), "b", MatchWildcard)
+	b = b.InsertWithKind(makeTestDN(t, "test.net"), "b", MatchBoth)
+	b = b.InsertWithKind(makeTestDN(t, "test.org"), "b", MatchWildcard)
+
+	m := a.Merge(b, resolve)
+	assertTree(m, "merged tree", t,
+		"\"abc.com\": \"a\"\n",
+		"\"*.abc.com\": \"*.abc.com: a+b\"\n",
+		"\"test.com\": \"a\"\n",
+		"\"*.test.com\": \"b\"\n",
+		"\"test.net\": \"test.net: a+b\"\n",
+		"\"*.test.org\": \"b\"\n")
+	assertLen(m, 6, "merged tree", t)
+}
+
+func TestGetWithName(t *testing.T) {
+	var r *Node
+
+	r = r.Insert(makeTestDN(t, "com"), "1")
+	r = r.Insert(makeTestDN(t, "test.com"), "2")
+	r = r.InsertWithKind(makeTestDN(t, "example.com"), "3", MatchExact)
+	r = r.InsertWithKind(makeTestDN(t, "test.net"), "4", MatchWildcard)
+
+	assertValueWithName(r, "Www.Test.Com.", "2", "Test.Com.", 2, t)
+	assertValueWithName(r, "test.com", "2", "test.com", 2, t)
+	assertValueWithName(r, "example.com", "3", "example.com", 2, t)
+	assertValueWithName(r, "www.example.com", "1", "com", 1, t)
+	assertValueWithName(r, "ns.www.test.net", "4", "test.net", 2, t)
+	assertValueWithName(r, "test.net", "", "", 0, t)
+	assertValueWithName(r, "test.org", "", "", 0, t)
+
+	var e *Node
+	assertValueWithName(e, "test.com", "", "", 0, t)
+}
+
+func TestSubdomains(t *testing.T) {
+	var r *Node
+
+	for range r.Subdomains(makeTestDN(t, "test.com")) {
+		t.Error("Expected no subdomains in empty tree")
+	}
+
+	r = r.Insert(makeTestDN(t, "com"), "1")
+	r = r.Insert(makeTestDN(t, "test.com"), "2")
+	r = r.Insert(makeTestDN(t, "www.test.com"), "3")
+	r = r.Insert(makeTestDN(t, "ns.corp.test.com"), "4")
+	r = r.InsertWithKind(makeTestDN(t, "corp.test.com"), "5", MatchWildcard)
+	r = r.Insert(makeTestDN(t, "example.com"), "6")
+	r = r.Insert(makeTestDN(t, "test.net"), "7")
+	r = r.Insert(makeTestDN(t, "a\\.b.corp.test.com"), "8")
+
+	assertSubdomains(r, "Corp.Test.Com.", "subdomains of \"corp.test.com\"", t,
+		"\"*.corp.test.com\": \"5\"\n",
+		"\"ns.corp.test.com\": \"4\"\n",
+		"\"a\\\\.b.corp.test.com\": \"8\"\n")
+
+	assertSubdomains(r, "test.com", "subdomains of \"test.com\"", t,
+		"\"test.com\": \"2\"\n",
+		"\"www.test.com\": \"3\"\n",
+		"\"*.corp.test.com\": \"5\"\n",
+		"\"ns.corp.test.com\": \"4\"\n",
+		"\"a\\\\.b.corp.test.com\": \"8\"\n")
+
+	assertSubdomains(r, "www.test.com", "subdomains of \"www.test.com\"", t,
+		"\"www.test.com\": \"3\"\n")
+
+	assertSubdomains(r, "ftp.test.com", "subdomains of absent domain", t)
+	assertSubdomains(r, "test.org", "subdomains of absent top level domain", t)
+
+	e := []string{}
+	for p := range r.Enumerate() {
+		e = append(e, fmt.Sprintf("%q: %q\n", p.Key, p.Value))
+	}
+	assertSubdomains(r, ".", "subdomains of root domain", t, e...)
+
+	n := 0
+	for k, v := range r.Subdomains(makeTestDN(t, "test.com")) {
+		if k != "test.com" || v != "2" {
+			t.Errorf("Expected \"test.com\": \"2\" as the first subdomain but got %q: %#v", k, v)
+		}
+
+		n++
+		break
+	}
+
+	if n != 1 {
+		t.Errorf("Expected iterations to stop after the first subdomain but got %d", n)
+	}
+}
+
+func TestUnicodeDomains(t *testing.T) {
+	u, err := domain.MakeNameFromUnicode("bücher.example")
+	if err != nil {
+		t.Fatalf("can't create domain name from unicode string: %s", err)
+	}
+
+	var r *Node
+	r = r.Insert(u, "1")
+	r = r.Insert(makeTestDN(t, "xn--fa-hia.de"), "2")
+
+	v, ok := r.Get(makeTestDN(t, "www.XN--BCHER-KVA.example"))
+	assertValue(v, ok, "1", true, "fetching ASCII compatible encoded domain from tree", t)
+
+	d, err := domain.MakeNameFromUnicode("WWW.FAß.DE")
+	if err != nil {
+		t.Fatalf("can't create domain name from unicode string: %s", err)
+	}
+
+	v, ok = r.Get(d)
+	assertValue(v, ok, "2", true, "fetching unicode domain from tree", t)
+}
+
 func makeTestDN(t *testing.T, s string) domain.Name {
 	d, err := domain.MakeNameFromString(s)
 	if err != nil {
@@ -373,3 +876,58 @@ func assertValue(v interface{}, vok bool, e string, eok bool, desc string, t *te
 		}
 	}
 }
+
+func assertLen(r *Node, e int, desc string, t *testing.T) {
+	if n := r.Len(); n != e {
+		t.Errorf("Expected %d domains in %s but got %d", e, desc, n)
+	}
+
+	n := 0
+	for range r.All() {
+		n++
+	}
+
+	if n != r.Len() {
+		t.Errorf("Expected length of %s to match %d enumerated domains but got %d", desc, n, r.Len())
+	}
+}
+
+func assertValueWithKind(r *Node, s string, k MatchKind, e string, ek MatchKind, t *testing.T) {
+	v, kind, ok := r.GetWithKind(makeTestDN(t, s), k)
+	assertValue(v, ok, e, ek != 0, fmt.Sprintf("fetching %q with kind %d from tree", s, k), t)
+
+	if kind != ek {
+		t.Errorf("Expected kind %d for %q with kind %d but got %d", ek, s, k, kind)
+	}
+}
+
+func assertValueWithName(r *Node, s string, e, en string, ed int, t *testing.T) {
+	v, n, depth, ok := r.GetWithName(makeTestDN(t, s))
+	assertValue(v, ok, e, ed > 0, fmt.Sprintf("fetching %q with name from tree", s), t)
+
+	if n.String() != en || depth != ed {
+		t.Errorf("Expected %q (%d) as matched domain for %q but got %q (%d)", en, ed, s, n, depth)
+	}
+}
+
+func assertSubdomains(r *Node, s, desc string, t *testing.T, e ...string) {
+	pairs := []string{}
+	for p := range r.EnumerateSubdomains(makeTestDN(t, s)) {
+		pairs = append(pairs, fmt.Sprintf("%q: %q\n", p.Key, p.Value))
+	}
+
+	ctx := difflib.ContextDiff{
+		A:        e,
+		B:        pairs,
+		FromFile: "Expected",
+		ToFile:   "Got"}
+
+	diff, err := difflib.GetContextDiffString(ctx)
+	if err != nil {
+		panic(fmt.Errorf("can't compare \"%s\": %s", desc, err))
+	}
+
+	if len(diff) > 0 {
+		t.Errorf("\"%s\" doesn't match:\n%s", desc, diff)
+	}
+}
@@ -0,0 +1,118 @@
+package domaintree
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNodeOf(t *testing.T) {
+	var r *NodeOf[int]
+
+	if v, ok := r.Get(makeTestDN(t, "com")); ok {
+		t.Errorf("Expected nothing in empty tree but got %d", v)
+	}
+
+	r = r.Insert(makeTestDN(t, "com"), 1)
+	r = r.Insert(makeTestDN(t, "test.com"), 2)
+	r = r.Insert(makeTestDN(t, "www.test.com"), 3)
+	r.InplaceInsert(makeTestDN(t, "test.net"), 4)
+	r.InplaceInsert(makeTestDN(t, "example.com"), 5)
+
+	assertNodeOf(r, "typed tree", t,
+		"\"com\": 1\n",
+		"\"test.com\": 2\n",
+		"\"www.test.com\": 3\n",
+		"\"example.com\": 5\n",
+		"\"test.net\": 4\n")
+
+	assertNodeOfValue(r, "com", 1, true, t)
+	assertNodeOfValue(r, "www.example.com", 5, true, t)
+	assertNodeOfValue(r, "ns.www.test.com", 3, true, t)
+	assertNodeOfValue(r, "example.net", 0, false, t)
+
+	r1, ok := r.Delete(makeTestDN(t, "test.com"))
+	if !ok {
+		t.Error("Expected \"test.com\" to be deleted")
+	}
+
+	assertNodeOfValue(r1, "test.com", 1, true, t)
+	assertNodeOfValue(r1, "www.test.com", 3, true, t)
+
+	r2, ok := r.DeleteSubdomains(makeTestDN(t, "test.com"))
+	if !ok {
+		t.Error("Expected \"test.com\" and its subdomains to be deleted")
+	}
+
+	assertNodeOf(r2, "typed tree without test.com subdomains", t,
+		"\"com\": 1\n",
+		"\"example.com\": 5\n",
+		"\"test.net\": 4\n")
+
+	assertNodeOfValue(r, "www.test.com", 3, true, t)
+}
+
+func TestNodeOfAll(t *testing.T) {
+	var r *NodeOf[int]
+
+	for k, v := range r.All() {
+		t.Errorf("Expected no pairs in empty tree but got %q: %d", k, v)
+	}
+
+	r = r.Insert(makeTestDN(t, "com"), 1)
+	r = r.Insert(makeTestDN(t, "test.com"), 2)
+	r = r.Insert(makeTestDN(t, "www.test.com"), 3)
+	r = r.Insert(makeTestDN(t, "test.net"), 4)
+
+	keys := []string{}
+	for k := range r.All() {
+		keys = append(keys, k)
+		if len(keys) >= 2 {
+			break
+		}
+	}
+
+	if s, e := fmt.Sprintf("%q", keys), "[\"com\" \"test.com\"]"; s != e {
+		t.Errorf("Expected %s keys but got %s", e, s)
+	}
+
+	count := 0
+	if r.Walk(func(k string, v int) bool {
+		count++
+		return v != 3
+	}) {
+		t.Error("Expected interrupted walk")
+	}
+
+	if count != 3 {
+		t.Errorf("Expected walk to stop after 3 pairs but got %d", count)
+	}
+}
+
+func assertNodeOf(r *NodeOf[int], desc string, t *testing.T, e ...string) {
+	t.Helper()
+
+	pairs := []string{}
+	for p := range r.Enumerate() {
+		pairs = append(pairs, fmt.Sprintf("%q: %d\n", p.Key, p.Value))
+	}
+
+	if len(pairs) != len(e) {
+		t.Errorf("Expected %d pairs for %s but got %d:\n%q", len(e), desc, len(pairs), pairs)
+		return
+	}
+
+	for i, p := range pairs {
+		if p != e[i] {
+			t.Errorf("Expected %q at %d for %s but got %q", e[i], i, desc, p)
+		}
+	}
+}
+
+func assertNodeOfValue(r *NodeOf[int], s string, e int, eok bool, t *testing.T) {
+	t.Helper()
+
+	v, ok := r.Get(makeTestDN(t, s))
+	if ok != eok || v != e {
+		t.Errorf("Expected %d (%v) for %q but got %d (%v)", e, eok, s, v, ok)
+	}
+}
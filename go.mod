@@ -1,8 +1,11 @@
 module github.com/infobloxopen/go-trees
 
-go 1.13
+go 1.23
 
 require (
 	github.com/pmezard/go-difflib v1.0.0
+	golang.org/x/net v0.34.0
 	gopkg.in/yaml.v2 v2.3.0
 )
+
+require golang.org/x/text v0.21.0 // indirect
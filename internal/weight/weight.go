@@ -0,0 +1,10 @@
+// Package weight lets trees of the module count a numtree leaf as several items in Len (for example a leaf which holds another tree) without giving the same ability to values of users.
+package weight
+
+// Int is a number of items a leaf stands for. It's a distinct type of internal package so types outside of the module can't implement Weigher.
+type Int int
+
+// Weigher is implemented by pointers to values which stand for several items. Weight of a value is taken when the value is put to the tree so the value should be inserted again if its weight changes.
+type Weigher interface {
+	Weight() Int
+}
@@ -0,0 +1,252 @@
+// Package intervaltree implements persistent interval tree for arbitrary (not aligned) closed intervals of ordered keys like port or ASN ranges. The tree is an augmented strtree red-black tree ordered by interval start and end where each node additionally keeps the greatest end in its subtree.
+package intervaltree
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+
+	"github.com/infobloxopen/go-trees/strtree"
+)
+
+// Interval is a closed interval from Lo to Hi inclusive.
+type Interval[K cmp.Ordered] struct {
+	Lo K
+	Hi K
+}
+
+// TreeOf is an interval tree for key-value pairs where key is an interval of values of type K and value has type V.
+type TreeOf[K cmp.Ordered, V any] struct {
+	tree *strtree.TreeOf[Interval[K], entry[K, V]]
+}
+
+// entry is a value of underlying red-black tree. It keeps the greatest end of intervals in subtree of the node along with user's value.
+type entry[K cmp.Ordered, V any] struct {
+	value V
+	max   K
+}
+
+// Tree is an interval tree for intervals of unsigned integers.
+type Tree = TreeOf[uint64, interface{}]
+
+// PairOf is a key-value pair representing tree node content.
+type PairOf[K cmp.Ordered, V any] struct {
+	Key   Interval[K]
+	Value V
+}
+
+// Pair is a key-value pair representing tree node content.
+type Pair = PairOf[uint64, interface{}]
+
+// Stats holds tree statistics returned by Stats method.
+type Stats = strtree.Stats
+
+// NewTree creates empty tree.
+func NewTree() *Tree {
+	return NewTreeOf[uint64, interface{}]()
+}
+
+// NewTreeOf creates empty tree for intervals of type K with values of type V.
+func NewTreeOf[K cmp.Ordered, V any]() *TreeOf[K, V] {
+	return &TreeOf[K, V]{tree: newTree[K, V]()}
+}
+
+// String returns interval in form "[lo, hi]".
+func (i Interval[K]) String() string {
+	return fmt.Sprintf("[%v, %v]", i.Lo, i.Hi)
+}
+
+// Contains reports if the interval contains given point.
+func (i Interval[K]) Contains(x K) bool {
+	return i.Lo <= x && x <= i.Hi
+}
+
+// Overlaps reports if the interval has common points with given one.
+func (i Interval[K]) Overlaps(j Interval[K]) bool {
+	return i.Lo <= j.Hi && j.Lo <= i.Hi
+}
+
+// Insert puts value for interval from lo to hi inclusive and returns pointer to new root. Value for the same interval is replaced. Intervals with lo greater than hi are ignored.
+func (t *TreeOf[K, V]) Insert(lo, hi K, value V) *TreeOf[K, V] {
+	if lo > hi {
+		return t
+	}
+
+	var r *strtree.TreeOf[Interval[K], entry[K, V]]
+	if t != nil {
+		r = t.tree
+	}
+
+	if r == nil {
+		r = newTree[K, V]()
+	}
+
+	return &TreeOf[K, V]{tree: r.Insert(Interval[K]{Lo: lo, Hi: hi}, entry[K, V]{value: value})}
+}
+
+// InplaceInsert inserts or replaces value for interval from lo to hi inclusive. The method inserts data directly to current tree so make sure you have exclusive access to it.
+func (t *TreeOf[K, V]) InplaceInsert(lo, hi K, value V) {
+	if lo > hi {
+		return
+	}
+
+	if t.tree == nil {
+		t.tree = newTree[K, V]()
+	}
+
+	t.tree.InplaceInsert(Interval[K]{Lo: lo, Hi: hi}, entry[K, V]{value: value})
+}
+
+// Get returns value for exactly the interval from lo to hi.
+func (t *TreeOf[K, V]) Get(lo, hi K) (V, bool) {
+	if t == nil {
+		var v V
+		return v, false
+	}
+
+	e, ok := t.tree.Get(Interval[K]{Lo: lo, Hi: hi})
+	return e.value, ok
+}
+
+// Stab returns all intervals containing given point in order of intervals.
+func (t *TreeOf[K, V]) Stab(x K) []PairOf[K, V] {
+	return t.Overlap(x, x)
+}
+
+// Overlap returns all intervals which have common points with interval from lo to hi in order of intervals.
+func (t *TreeOf[K, V]) Overlap(lo, hi K) []PairOf[K, V] {
+	var r []PairOf[K, V]
+	for k, v := range t.Overlapping(lo, hi) {
+		r = append(r, PairOf[K, V]{Key: k, Value: v})
+	}
+
+	return r
+}
+
+// Overlapping returns iterator over intervals which have common points with interval from lo to hi in order of intervals.
+func (t *TreeOf[K, V]) Overlapping(lo, hi K) iter.Seq2[Interval[K], V] {
+	return func(yield func(Interval[K], V) bool) {
+		if t == nil || lo > hi {
+			return
+		}
+
+		t.tree.WalkPruned(func(e entry[K, V]) bool {
+			return e.max < lo
+		}, func(k Interval[K], e entry[K, V]) bool {
+			if k.Lo > hi {
+				// All following intervals start after hi as well.
+				return false
+			}
+
+			return k.Hi < lo || yield(k, e.value)
+		})
+	}
+}
+
+// Enumerate returns channel which is populated by key pair values in order of intervals.
+func (t *TreeOf[K, V]) Enumerate() chan PairOf[K, V] {
+	ch := make(chan PairOf[K, V])
+
+	go func() {
+		defer close(ch)
+
+		for k, v := range t.All() {
+			ch <- PairOf[K, V]{Key: k, Value: v}
+		}
+	}()
+
+	return ch
+}
+
+// All returns iterator over key-value pairs in order of intervals.
+func (t *TreeOf[K, V]) All() iter.Seq2[Interval[K], V] {
+	return func(yield func(Interval[K], V) bool) {
+		t.Walk(yield)
+	}
+}
+
+// Walk calls f for key-value pairs in order of intervals until f returns false. It reports if all the pairs have been visited.
+func (t *TreeOf[K, V]) Walk(f func(Interval[K], V) bool) bool {
+	if t == nil {
+		return true
+	}
+
+	return t.tree.Walk(func(k Interval[K], e entry[K, V]) bool {
+		return f(k, e.value)
+	})
+}
+
+// Delete removes exactly the interval from lo to hi. It returns copy of tree and true if node has been indeed deleted otherwise copy of tree and false.
+func (t *TreeOf[K, V]) Delete(lo, hi K) (*TreeOf[K, V], bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	r, ok := t.tree.Delete(Interval[K]{Lo: lo, Hi: hi})
+	return &TreeOf[K, V]{tree: r}, ok
+}
+
+// Len returns number of intervals in the tree. It takes constant time.
+func (t *TreeOf[K, V]) Len() int {
+	if t == nil {
+		return 0
+	}
+
+	return t.tree.Len()
+}
+
+// Stats walks the tree and collects its statistics.
+func (t *TreeOf[K, V]) Stats() Stats {
+	if t == nil {
+		return Stats{}
+	}
+
+	return t.tree.Stats()
+}
+
+// IsEmpty returns true if given tree has no nodes.
+func (t *TreeOf[K, V]) IsEmpty() bool {
+	return t == nil || t.tree.IsEmpty()
+}
+
+// Dot dumps tree to Graphviz .dot format.
+func (t *TreeOf[K, V]) Dot() string {
+	var r *strtree.TreeOf[Interval[K], entry[K, V]]
+	if t != nil {
+		r = t.tree
+	}
+
+	return r.Dot()
+}
+
+// String returns value and the greatest end of intervals in subtree for node labels of Dot output.
+func (e entry[K, V]) String() string {
+	return fmt.Sprintf("%v (max %v)", e.value, e.max)
+}
+
+func newTree[K cmp.Ordered, V any]() *strtree.TreeOf[Interval[K], entry[K, V]] {
+	return strtree.NewTreeOfWithAugmentation(compareIntervals[K], augmentMax[K, V])
+}
+
+// augmentMax sets max of the node to the greatest end of its interval and intervals in subtrees of its children.
+func augmentMax[K cmp.Ordered, V any](key Interval[K], e entry[K, V], left, right *entry[K, V]) entry[K, V] {
+	e.max = key.Hi
+	if left != nil && left.max > e.max {
+		e.max = left.max
+	}
+
+	if right != nil && right.max > e.max {
+		e.max = right.max
+	}
+
+	return e
+}
+
+// compareIntervals orders intervals by start and then by end.
+func compareIntervals[K cmp.Ordered](a, b Interval[K]) int {
+	if r := cmp.Compare(a.Lo, b.Lo); r != 0 {
+		return r
+	}
+
+	return cmp.Compare(a.Hi, b.Hi)
+}
@@ -0,0 +1,219 @@
+package intervaltree
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestInsert(t *testing.T) {
+	var r *Tree
+
+	r = r.Insert(80, 80, "http")
+	r = r.Insert(1024, 65535, "ephemeral")
+	r = r.Insert(0, 1023, "well-known")
+	r = r.Insert(443, 443, "https")
+	r = r.Insert(5, 1, "invalid")
+	u := r.Insert(443, 443, "tls")
+
+	assertTree(r, "[0, 1023]: \"well-known\", [80, 80]: \"http\", [443, 443]: \"https\", [1024, 65535]: \"ephemeral\"",
+		"tree", t)
+	assertTree(u, "[0, 1023]: \"well-known\", [80, 80]: \"http\", [443, 443]: \"tls\", [1024, 65535]: \"ephemeral\"",
+		"tree with replaced value", t)
+
+	v, ok := u.Get(443, 443)
+	if !ok || v != "tls" {
+		t.Errorf("Expected \"tls\" for [443, 443] but got %#v (%v)", v, ok)
+	}
+
+	if v, ok := u.Get(443, 444); ok {
+		t.Errorf("Expected nothing for [443, 444] but got %#v", v)
+	}
+
+	if s := NewTree().Insert(80, 80, "http").Dot(); !strings.Contains(s, `k: \"[80, 80]\" v: \"http (max 80)\"`) {
+		t.Errorf("Expected value and max in node label but got:\n%s", s)
+	}
+
+	n := NewTreeOf[int, string]()
+	n.InplaceInsert(10, 20, "a")
+	n.InplaceInsert(15, 30, "b")
+	n.InplaceInsert(30, 10, "c")
+
+	if s := fmt.Sprint(n.Stab(20)); s != "[{[10, 20] a} {[15, 30] b}]" {
+		t.Errorf("Expected both intervals for 20 but got %s", s)
+	}
+}
+
+func TestStabAndOverlap(t *testing.T) {
+	var r *Tree
+	if s := r.Stab(1); len(s) > 0 {
+		t.Errorf("Expected nothing in empty tree but got %v", s)
+	}
+
+	r = r.Insert(64512, 65534, "private")
+	r = r.Insert(4200000000, 4294967294, "private-32")
+	r = r.Insert(0, 0, "reserved")
+	r = r.Insert(65000, 65100, "lab")
+	r = r.Insert(64496, 64511, "documentation")
+	r = r.Insert(65535, 65535, "reserved")
+
+	assertPairs(r.Stab(65050), "[64512, 65534]: \"private\", [65000, 65100]: \"lab\"", "stab 65050", t)
+	assertPairs(r.Stab(64511), "[64496, 64511]: \"documentation\"", "stab 64511", t)
+	assertPairs(r.Stab(100), "", "stab 100", t)
+	assertPairs(r.Overlap(64500, 65000), "[64496, 64511]: \"documentation\", [64512, 65534]: \"private\", "+
+		"[65000, 65100]: \"lab\"", "overlap [64500, 65000]", t)
+	assertPairs(r.Overlap(65535, 4200000000), "[65535, 65535]: \"reserved\", [4200000000, 4294967294]: \"private-32\"",
+		"overlap [65535, 4200000000]", t)
+	assertPairs(r.Overlap(10, 1), "", "invalid overlap", t)
+
+	n := 0
+	for range r.Overlapping(0, 65535) {
+		n++
+		break
+	}
+
+	if n != 1 {
+		t.Errorf("Expected single iteration but got %d", n)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	var r *Tree
+	if _, ok := r.Delete(1, 2); ok {
+		t.Error("Expected no deletion from empty tree but got one")
+	}
+
+	r = r.Insert(1, 10, "a")
+	r = r.Insert(5, 20, "b")
+	r = r.Insert(15, 100, "c")
+
+	u, ok := r.Delete(15, 100)
+	if !ok {
+		t.Error("Expected deletion of [15, 100] but got nothing")
+	}
+
+	if _, ok := u.Delete(15, 99); ok {
+		t.Error("Expected no deletion of [15, 99] but got one")
+	}
+
+	assertPairs(u.Stab(50), "", "stab 50 after deletion", t)
+	assertPairs(r.Stab(50), "[15, 100]: \"c\"", "stab 50 in original tree", t)
+	assertTree(u, "[1, 10]: \"a\", [5, 20]: \"b\"", "tree after deletion", t)
+
+	u, _ = u.Delete(1, 10)
+	u, _ = u.Delete(5, 20)
+	if !u.IsEmpty() {
+		t.Errorf("Expected empty tree but got:\n%s", u.Dot())
+	}
+}
+
+func TestLenAndStats(t *testing.T) {
+	var r *Tree
+	if n := r.Len(); n != 0 {
+		t.Errorf("Expected no intervals in empty tree but got %d", n)
+	}
+
+	if s := r.Stats(); s != (Stats{}) {
+		t.Errorf("Expected empty stats for empty tree but got %#v", s)
+	}
+
+	r = r.Insert(0, 1023, "well-known")
+	r = r.Insert(80, 80, "http")
+	r = r.Insert(1024, 65535, "ephemeral")
+	r = r.Insert(80, 80, "www")
+	if n := r.Len(); n != 3 {
+		t.Errorf("Expected 3 intervals but got %d", n)
+	}
+
+	if s := r.Stats(); s.Nodes != 3 || s.Childless != 2 || s.MaxDepth != 2 || s.Bytes <= 0 {
+		t.Errorf("Expected 3 nodes, 2 childless nodes, depth 2 and some bytes but got %#v", s)
+	}
+
+	r, _ = r.Delete(80, 80)
+	if n := r.Len(); n != 2 {
+		t.Errorf("Expected 2 intervals after deletion but got %d", n)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	var (
+		r  *TreeOf[int, int]
+		in = NewTreeOf[int, int]()
+		m  = map[Interval[int]]int{}
+	)
+
+	for i := 0; i < 2000; i++ {
+		lo := rnd.Intn(1000)
+		k := Interval[int]{Lo: lo, Hi: lo + rnd.Intn(100)}
+		if rnd.Intn(3) > 0 {
+			r = r.Insert(k.Lo, k.Hi, i)
+			in.InplaceInsert(k.Lo, k.Hi, i)
+			m[k] = i
+		} else {
+			r, _ = r.Delete(k.Lo, k.Hi)
+			in, _ = in.Delete(k.Lo, k.Hi)
+			delete(m, k)
+		}
+
+		if i%100 == 0 {
+			assertStab(r, m, "persistent tree", t)
+			assertStab(in, m, "inplace tree", t)
+		}
+	}
+
+	assertStab(r, m, "persistent tree", t)
+	assertStab(in, m, "inplace tree", t)
+}
+
+func assertStab(r *TreeOf[int, int], m map[Interval[int]]int, desc string, t *testing.T) {
+	t.Helper()
+
+	for x := -1; x < 1101; x += 7 {
+		e := 0
+		for k := range m {
+			if k.Contains(x) {
+				e++
+			}
+		}
+
+		s := r.Stab(x)
+		if len(s) != e {
+			t.Fatalf("Expected %d intervals for %d in %s but got %d", e, x, desc, len(s))
+		}
+
+		for _, p := range s {
+			if v, ok := m[p.Key]; !ok || v != p.Value || !p.Key.Contains(x) {
+				t.Fatalf("Unexpected interval %s: %d for %d in %s", p.Key, p.Value, x, desc)
+			}
+		}
+	}
+}
+
+func assertTree(r *Tree, e, desc string, t *testing.T) {
+	t.Helper()
+
+	items := []string{}
+	for p := range r.Enumerate() {
+		items = append(items, fmt.Sprintf("%s: %#v", p.Key, p.Value))
+	}
+
+	if s := strings.Join(items, ", "); s != e {
+		t.Errorf("Expected following intervals for %s:\n\t%s\nbut got:\n\t%s", desc, e, s)
+	}
+}
+
+func assertPairs(p []Pair, e, desc string, t *testing.T) {
+	t.Helper()
+
+	items := make([]string, len(p))
+	for i, p := range p {
+		items[i] = fmt.Sprintf("%s: %#v", p.Key, p.Value)
+	}
+
+	if s := strings.Join(items, ", "); s != e {
+		t.Errorf("Expected following intervals for %s:\n\t%s\nbut got:\n\t%s", desc, e, s)
+	}
+}
@@ -0,0 +1,177 @@
+package iptree
+
+import (
+	"sort"
+
+	"github.com/infobloxopen/go-trees/numtree"
+)
+
+// Aggregate returns new tree with the same lookup results for every address but without redundant networks. Sibling networks with equal values (like two /25 halves of a /24 network) are replaced by their parent from the longest networks to the shortest ones so merged parents can merge further. After that networks which repeat value of the closest containing network are removed. Values are compared with given equal function.
+func (t *TreeOf[V]) Aggregate(equal func(a, b V) bool) *TreeOf[V] {
+	r := NewTreeOf[V]()
+	if t == nil {
+		return r
+	}
+
+	var p4 []aggPrefix[V]
+	for n := range t.root32.All() {
+		p4 = append(p4, newAggPrefix(uint64(n.Key)<<32, 0, int(n.Bits), n.Value))
+	}
+
+	for _, p := range aggregate(p4, numtree.Key32BitSize, equal) {
+		r.root32 = r.root32.InplaceInsert(uint32(p.hi>>32), p.bits, p.value)
+	}
+
+	var p6 []aggPrefix[V]
+	for n := range t.root64.All() {
+		if s := n.Value.sub; s != nil {
+			for m := range s.All() {
+				p6 = append(p6, newAggPrefix(n.Key, m.Key, numtree.Key64BitSize+int(m.Bits), m.Value))
+			}
+		} else {
+			p6 = append(p6, newAggPrefix(n.Key, 0, int(n.Bits), n.Value.value))
+		}
+	}
+
+	for _, p := range aggregate(p6, iPv6Bits, equal) {
+		if p.bits > numtree.Key64BitSize {
+			r.inplaceInsert64(p.hi, numtree.Key64BitSize, p.lo, p.bits-numtree.Key64BitSize, p.value)
+		} else {
+			r.inplaceInsert64(p.hi, p.bits, 0, 0, p.value)
+		}
+	}
+
+	return r
+}
+
+// aggPrefix is a network with up to 128 bits key used by Aggregate. Both IPv4 and IPv6 keys are aligned to the most significant bit of hi.
+type aggPrefix[V any] struct {
+	hi    uint64
+	lo    uint64
+	bits  int
+	value V
+}
+
+type aggKey struct {
+	hi   uint64
+	lo   uint64
+	bits int
+}
+
+func newAggPrefix[V any](hi, lo uint64, bits int, value V) aggPrefix[V] {
+	k := newAggKey(hi, lo, bits)
+	return aggPrefix[V]{hi: k.hi, lo: k.lo, bits: k.bits, value: value}
+}
+
+// newAggKey makes key of network with given number of bits clearing the rest of bits.
+func newAggKey(hi, lo uint64, bits int) aggKey {
+	k := aggKey{hi: hi, lo: lo, bits: bits}
+	if bits < numtree.Key64BitSize {
+		k.hi &^= ^uint64(0) >> bits
+		k.lo = 0
+	} else if bits < 2*numtree.Key64BitSize {
+		k.lo &^= ^uint64(0) >> (bits - numtree.Key64BitSize)
+	}
+
+	return k
+}
+
+func (p aggPrefix[V]) key() aggKey {
+	return aggKey{hi: p.hi, lo: p.lo, bits: p.bits}
+}
+
+// flip returns key of sibling (with set to true) or parent (with set to false) network.
+func (k aggKey) flip(set bool) aggKey {
+	var hi, lo uint64
+	if k.bits > numtree.Key64BitSize {
+		lo = 1 << (2*numtree.Key64BitSize - k.bits)
+	} else {
+		hi = 1 << (numtree.Key64BitSize - k.bits)
+	}
+
+	if set {
+		return aggKey{hi: k.hi ^ hi, lo: k.lo ^ lo, bits: k.bits}
+	}
+
+	return aggKey{hi: k.hi &^ hi, lo: k.lo &^ lo, bits: k.bits - 1}
+}
+
+func (k aggKey) contains(p aggKey) bool {
+	if k.bits > p.bits {
+		return false
+	}
+
+	q := newAggKey(p.hi, p.lo, k.bits)
+	return q.hi == k.hi && q.lo == k.lo
+}
+
+func aggregate[V any](p []aggPrefix[V], size int, equal func(a, b V) bool) []aggPrefix[V] {
+	m := make(map[aggKey]V, len(p))
+	byBits := make([][]aggKey, size+1)
+	for _, p := range p {
+		k := p.key()
+		m[k] = p.value
+		byBits[k.bits] = append(byBits[k.bits], k)
+	}
+
+	// Replace sibling networks with equal values by their parent starting from the longest ones. Parent's own value doesn't matter as both halves hide it.
+	for bits := size; bits > 0; bits-- {
+		for _, k := range byBits[bits] {
+			a, ok := m[k]
+			if !ok {
+				continue
+			}
+
+			s := k.flip(true)
+			b, ok := m[s]
+			if !ok || !equal(a, b) {
+				continue
+			}
+
+			delete(m, k)
+			delete(m, s)
+
+			k = k.flip(false)
+			if _, ok := m[k]; !ok {
+				byBits[k.bits] = append(byBits[k.bits], k)
+			}
+
+			m[k] = a
+		}
+	}
+
+	p = p[:0]
+	for k, v := range m {
+		p = append(p, aggPrefix[V]{hi: k.hi, lo: k.lo, bits: k.bits, value: v})
+	}
+
+	sort.Slice(p, func(i, j int) bool {
+		if p[i].hi != p[j].hi {
+			return p[i].hi < p[j].hi
+		}
+
+		if p[i].lo != p[j].lo {
+			return p[i].lo < p[j].lo
+		}
+
+		return p[i].bits < p[j].bits
+	})
+
+	// Drop networks which repeat value of the closest containing network. Sorted networks go after their containers so the stack holds chain of containers for current one.
+	r := p[:0]
+	var stack []aggPrefix[V]
+	for _, n := range p {
+		for len(stack) > 0 && !stack[len(stack)-1].key().contains(n.key()) {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) > 0 && equal(stack[len(stack)-1].value, n.value) {
+			continue
+		}
+
+		stack = append(stack, n)
+		r = append(r, n)
+	}
+
+	return r
+}
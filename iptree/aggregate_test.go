@@ -0,0 +1,64 @@
+package iptree
+
+import (
+	"net"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	equal := func(a, b interface{}) bool {
+		return a == b
+	}
+
+	var r *Tree
+	assertTreeEnumerate(r.Aggregate(equal).Enumerate(), "", "empty tree", t)
+
+	for _, p := range []Pair{
+		{Key: parseTestNet("10.0.0.0/25"), Value: "a"},
+		{Key: parseTestNet("10.0.0.128/26"), Value: "a"},
+		{Key: parseTestNet("10.0.0.192/26"), Value: "a"},
+		{Key: parseTestNet("10.0.1.0/24"), Value: "b"},
+		{Key: parseTestNet("10.0.1.0/25"), Value: "b"},
+		{Key: parseTestNet("10.0.1.128/25"), Value: "c"},
+		{Key: parseTestNet("192.0.2.0/24"), Value: "x"},
+		{Key: parseTestNet("192.0.2.0/25"), Value: "d"},
+		{Key: parseTestNet("192.0.2.128/25"), Value: "d"},
+		{Key: parseTestNet("192.0.2.1/32"), Value: "d"},
+		{Key: parseTestNet("2001:db8::/32"), Value: "e"},
+		{Key: parseTestNet("2001:db8::/64"), Value: "e"},
+		{Key: parseTestNet("2001:db8:1::/64"), Value: "f"},
+		{Key: parseTestNet("2001:db8::ff:0:0/97"), Value: "g"},
+		{Key: parseTestNet("2001:db8::ff:8000:0/97"), Value: "g"},
+		{Key: parseTestNet("2001:db8:0:1::/64"), Value: "h"},
+		{Key: parseTestNet("2001:db8:0:0:8000::/65"), Value: "h"},
+	} {
+		r = r.InsertNet(p.Key, p.Value)
+	}
+
+	a := r.Aggregate(equal)
+	assertTreeEnumerate(a.Enumerate(), "10.0.0.0/24: \"a\", 10.0.1.0/24: \"b\", 10.0.1.128/25: \"c\", "+
+		"192.0.2.0/24: \"d\", 2001:db8::/32: \"e\", 2001:db8::ff:0:0/96: \"g\", 2001:db8:0:0:8000::/65: \"h\", "+
+		"2001:db8:0:1::/64: \"h\", 2001:db8:1::/64: \"f\"", "aggregated tree", t)
+
+	for _, s := range []string{
+		"10.0.0.1", "10.0.0.200", "10.0.1.1", "10.0.1.200", "10.0.2.1", "192.0.2.1", "192.0.2.200",
+		"2001:db8::1", "2001:db8::ff:0:1", "2001:db8::ff:8000:1", "2001:db8::8000:0:0:1", "2001:db8:0:1::1",
+		"2001:db8:1::1", "2001:db8:2::1", "2001:db9::1",
+	} {
+		ip := net.ParseIP(s)
+		e, eok := r.GetByIP(ip)
+		v, ok := a.GetByIP(ip)
+		if v != e || ok != eok {
+			t.Errorf("Expected %#v, %v for %s in aggregated tree but got %#v, %v", e, eok, s, v, ok)
+		}
+	}
+}
+
+func parseTestNet(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return n
+}
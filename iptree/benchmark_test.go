@@ -1,8 +1,11 @@
 package iptree
 
 import (
+	"fmt"
 	"net"
 	"testing"
+
+	"github.com/infobloxopen/go-trees/numtree"
 )
 
 var (
@@ -2058,11 +2061,13 @@ var (
 	}
 
 	addrs []*net.IPNet
+	ips   []net.IP
 	tree  *Tree
 )
 
 func init() {
 	addrs = make([]*net.IPNet, len(strs))
+	ips = make([]net.IP, len(strs))
 	tree = NewTree()
 
 	for i, s := range strs {
@@ -2072,6 +2077,7 @@ func init() {
 		}
 
 		addrs[i] = n
+		ips[i] = n.IP
 		tree.InplaceInsertNet(n, "test")
 	}
 }
@@ -2111,3 +2117,59 @@ func BenchmarkIPTreeGetWithConversion(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkIPTreeGetByIP(b *testing.B) {
+	for _, ip := range allocsCheckIPs() {
+		if _, ok := tree.GetByIP(ip); !ok {
+			b.Fatalf("can't find data for %q", ip)
+		}
+
+		if n := testing.AllocsPerRun(100, func() { tree.GetByIP(ip) }); n > 0 {
+			b.Fatalf("expected no allocations for GetByIP(%s) but got %g", ip, n)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		i := n & 2047
+		ip := ips[i]
+
+		v, ok := tree.GetByIP(ip)
+		if !ok {
+			b.Fatalf("can't find data for %q (%q) at %d (%d)", strs[i], ip, n, i)
+		}
+
+		if _, ok := v.(string); !ok {
+			b.Fatalf("expected string for %q (%q) at %d (%d) but got %T (%#v)", strs[i], ip, n, i, v, v)
+		}
+	}
+}
+
+// allocsCheckIPs returns IPv4 address, IPv6 address of network up to 64 bits long and IPv6 address of network longer than 64 bits (it's looked up in a subtree of IPv6 tree) from benchmark data.
+func allocsCheckIPs() []net.IP {
+	var r [3]net.IP
+	for i, n := range addrs {
+		ones, bits := n.Mask.Size()
+		k := 0
+		if bits == iPv6Bits {
+			k = 1
+			if ones > numtree.Key64BitSize {
+				k = 2
+			}
+		}
+
+		if r[k] == nil {
+			r[k] = ips[i]
+		}
+	}
+
+	for i, ip := range r {
+		if ip == nil {
+			panic(fmt.Errorf("no address of kind %d in benchmark data", i))
+		}
+	}
+
+	return r[:]
+}
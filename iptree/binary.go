@@ -0,0 +1,321 @@
+package iptree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/infobloxopen/go-trees/numtree"
+)
+
+// BinaryVersion is a version of binary format produced by MarshalBinary.
+const BinaryVersion = 1
+
+var (
+	// ErrInvalidBinary is returned when data passed to UnmarshalBinary doesn't look like a marshalled tree.
+	ErrInvalidBinary = errors.New("invalid binary tree data")
+	// ErrUnsupportedVersion is returned when data passed to UnmarshalBinary has unknown binary format version.
+	ErrUnsupportedVersion = errors.New("unsupported binary tree version")
+)
+
+var binaryMagic = []byte("IPT")
+
+const (
+	binaryValue byte = iota
+	binarySubTree
+)
+
+// ValueCodec converts tree values to and from binary form for MarshalBinaryWithCodec and UnmarshalBinaryWithCodec.
+type ValueCodec interface {
+	MarshalValue(v interface{}) ([]byte, error)
+	UnmarshalValue(b []byte) (interface{}, error)
+}
+
+// GobCodec is a ValueCodec based on encoding/gob. Strings, byte slices and integers of built-in types are encoded directly without gob so each of them takes only a tag byte besides its data. Values of custom types should be registered with gob.Register. It's used by MarshalBinary and UnmarshalBinary.
+type GobCodec struct{}
+
+// Tags of values which GobCodec encodes without gob. Data encoded by gob starts with message length which first byte is either less than 0x80 or greater than 0xf7 so the tags can't be confused with it.
+const (
+	gobTagString byte = 0x80 + iota
+	gobTagBytes
+	gobTagInt
+	gobTagInt8
+	gobTagInt16
+	gobTagInt32
+	gobTagInt64
+	gobTagUint
+	gobTagUint8
+	gobTagUint16
+	gobTagUint32
+	gobTagUint64
+)
+
+// MarshalValue encodes value to binary form. Nil value is encoded as empty data. String and byte slice are encoded as a tag byte (0x80 for string and 0x81 for byte slice) followed by raw bytes of the value. Integers of built-in types are encoded as a tag byte followed by varint (zig-zag varint of encoding/binary for int, int8, int16, int32 and int64 with tags from 0x82 to 0x86 and uvarint for uint, uint8, uint16, uint32 and uint64 with tags from 0x87 to 0x8b). Values of other types are encoded by gob as interface value so their data never starts with a byte from 0x80 to 0xf7.
+func (GobCodec) MarshalValue(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+
+	case string:
+		return append([]byte{gobTagString}, v...), nil
+
+	case []byte:
+		return append([]byte{gobTagBytes}, v...), nil
+
+	case int:
+		return binary.AppendVarint([]byte{gobTagInt}, int64(v)), nil
+
+	case int8:
+		return binary.AppendVarint([]byte{gobTagInt8}, int64(v)), nil
+
+	case int16:
+		return binary.AppendVarint([]byte{gobTagInt16}, int64(v)), nil
+
+	case int32:
+		return binary.AppendVarint([]byte{gobTagInt32}, int64(v)), nil
+
+	case int64:
+		return binary.AppendVarint([]byte{gobTagInt64}, v), nil
+
+	case uint:
+		return binary.AppendUvarint([]byte{gobTagUint}, uint64(v)), nil
+
+	case uint8:
+		return binary.AppendUvarint([]byte{gobTagUint8}, uint64(v)), nil
+
+	case uint16:
+		return binary.AppendUvarint([]byte{gobTagUint16}, uint64(v)), nil
+
+	case uint32:
+		return binary.AppendUvarint([]byte{gobTagUint32}, uint64(v)), nil
+
+	case uint64:
+		return binary.AppendUvarint([]byte{gobTagUint64}, v), nil
+	}
+
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(&v); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// UnmarshalValue decodes value encoded by MarshalValue. It returns nil for empty data, decodes data which starts with a tag byte from 0x80 to 0x8b as string, byte slice or integer of the type selected by the tag and passes any other data to gob. Integer which varint has trailing bytes or doesn't fit the type results in ErrInvalidBinary.
+func (GobCodec) UnmarshalValue(b []byte) (interface{}, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	switch t := b[0]; t {
+	case gobTagString:
+		return string(b[1:]), nil
+
+	case gobTagBytes:
+		return append([]byte(nil), b[1:]...), nil
+
+	case gobTagInt, gobTagInt8, gobTagInt16, gobTagInt32, gobTagInt64:
+		x, n := binary.Varint(b[1:])
+		if n <= 0 || n != len(b)-1 {
+			return nil, fmt.Errorf("%w: invalid integer value", ErrInvalidBinary)
+		}
+
+		return unmarshalGobInt(t, x)
+
+	case gobTagUint, gobTagUint8, gobTagUint16, gobTagUint32, gobTagUint64:
+		x, n := binary.Uvarint(b[1:])
+		if n <= 0 || n != len(b)-1 {
+			return nil, fmt.Errorf("%w: invalid unsigned integer value", ErrInvalidBinary)
+		}
+
+		return unmarshalGobUint(t, x)
+	}
+
+	var v interface{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Values are encoded with GobCodec.
+func (t *TreeOf[V]) MarshalBinary() ([]byte, error) {
+	return t.MarshalBinaryWithCodec(GobCodec{})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. Values are decoded with GobCodec.
+func (t *TreeOf[V]) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalBinaryWithCodec(data, GobCodec{})
+}
+
+// MarshalBinaryWithCodec encodes the tree to binary form using given codec for values. The data starts with "IPT" and format version followed by IPv4 and IPv6 radix trees in numtree binary format.
+func (t *TreeOf[V]) MarshalBinaryWithCodec(c ValueCodec) ([]byte, error) {
+	b := append(append([]byte{}, binaryMagic...), BinaryVersion)
+	if t == nil {
+		return append(b, 0, 0), nil
+	}
+
+	enc := func(b []byte, v V) ([]byte, error) {
+		data, err := c.MarshalValue(v)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(binary.AppendUvarint(b, uint64(len(data))), data...), nil
+	}
+
+	b, err := t.root32.AppendBinary(b, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.root64.AppendBinary(b, func(b []byte, v entry64[V]) ([]byte, error) {
+		if v.sub != nil {
+			return v.sub.AppendBinary(append(b, binarySubTree), enc)
+		}
+
+		return enc(append(b, binaryValue), v.value)
+	})
+}
+
+// UnmarshalBinaryWithCodec replaces content of the tree with data produced by MarshalBinaryWithCodec using given codec for values.
+func (t *TreeOf[V]) UnmarshalBinaryWithCodec(data []byte, c ValueCodec) error {
+	if len(data) < len(binaryMagic)+1 || !bytes.Equal(data[:len(binaryMagic)], binaryMagic) {
+		return ErrInvalidBinary
+	}
+
+	if data[len(binaryMagic)] != BinaryVersion {
+		return ErrUnsupportedVersion
+	}
+
+	dec := func(b []byte) (V, int, error) {
+		var v V
+
+		size, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b)-n) < size {
+			return v, 0, numtree.ErrUnexpectedEnd
+		}
+
+		x, err := c.UnmarshalValue(b[n : n+int(size)])
+		if err != nil {
+			return v, 0, err
+		}
+
+		if x != nil {
+			var ok bool
+			if v, ok = x.(V); !ok {
+				return v, 0, fmt.Errorf("%w: expected %T value but got %T", ErrInvalidBinary, v, x)
+			}
+		}
+
+		return v, n + int(size), nil
+	}
+
+	off := len(binaryMagic) + 1
+	r32, n, err := numtree.DecodeNode32Of(data[off:], dec)
+	if err != nil {
+		return err
+	}
+
+	off += n
+	r64, n, err := numtree.DecodeNode64Of(data[off:], func(b []byte) (entry64[V], int, error) {
+		if len(b) < 1 {
+			return entry64[V]{}, 0, numtree.ErrUnexpectedEnd
+		}
+
+		switch b[0] {
+		case binaryValue:
+			v, n, err := dec(b[1:])
+			return entry64[V]{value: v}, n + 1, err
+
+		case binarySubTree:
+			s, n, err := numtree.DecodeNode64Of(b[1:], dec)
+			if err == nil && s == nil {
+				err = ErrInvalidBinary
+			}
+
+			return entry64[V]{sub: s}, n + 1, err
+		}
+
+		return entry64[V]{}, 0, ErrInvalidBinary
+	})
+	if err != nil {
+		return err
+	}
+
+	if off+n != len(data) {
+		return ErrInvalidBinary
+	}
+
+	for n := range r64.All() {
+		if (n.Value.sub != nil) != (n.Bits >= numtree.Key64BitSize) {
+			return ErrInvalidBinary
+		}
+	}
+
+	t.root32 = r32
+	t.root64 = r64
+	return nil
+}
+
+func unmarshalGobInt(t byte, x int64) (interface{}, error) {
+	switch t {
+	case gobTagInt:
+		if x >= math.MinInt && x <= math.MaxInt {
+			return int(x), nil
+		}
+
+	case gobTagInt8:
+		if x >= math.MinInt8 && x <= math.MaxInt8 {
+			return int8(x), nil
+		}
+
+	case gobTagInt16:
+		if x >= math.MinInt16 && x <= math.MaxInt16 {
+			return int16(x), nil
+		}
+
+	case gobTagInt32:
+		if x >= math.MinInt32 && x <= math.MaxInt32 {
+			return int32(x), nil
+		}
+
+	default:
+		return x, nil
+	}
+
+	return nil, fmt.Errorf("%w: integer %d is out of range", ErrInvalidBinary, x)
+}
+
+func unmarshalGobUint(t byte, x uint64) (interface{}, error) {
+	switch t {
+	case gobTagUint:
+		if x <= math.MaxUint {
+			return uint(x), nil
+		}
+
+	case gobTagUint8:
+		if x <= math.MaxUint8 {
+			return uint8(x), nil
+		}
+
+	case gobTagUint16:
+		if x <= math.MaxUint16 {
+			return uint16(x), nil
+		}
+
+	case gobTagUint32:
+		if x <= math.MaxUint32 {
+			return uint32(x), nil
+		}
+
+	default:
+		return x, nil
+	}
+
+	return nil, fmt.Errorf("%w: unsigned integer %d is out of range", ErrInvalidBinary, x)
+}
@@ -0,0 +1,185 @@
+package iptree
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	var r *Tree
+
+	b, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected no error for empty tree but got %s", err)
+	}
+
+	u := NewTree()
+	if err := u.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Expected no error for empty tree data but got %s", err)
+	}
+
+	assertTreeEnumerate(u.Enumerate(), "", "unmarshalled empty tree", t)
+
+	for i, s := range []string{
+		"10.0.0.0/8",
+		"10.0.0.0/16",
+		"192.0.2.0/24",
+		"2001:db8::/32",
+		"2001:db8::/64",
+		"2001:db8::ff:0:0/96",
+		"2001:db8:1::1/128",
+	} {
+		_, n, _ := net.ParseCIDR(s)
+		if i == 1 {
+			r = r.InsertNet(n, nil)
+		} else if i&1 == 0 {
+			r = r.InsertNet(n, s)
+		} else {
+			r = r.InsertNet(n, i)
+		}
+	}
+
+	b, err = r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	u = NewTree()
+	if err := u.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	e := "10.0.0.0/8: \"10.0.0.0/8\", 10.0.0.0/16: <nil> (<nil>), 192.0.2.0/24: \"192.0.2.0/24\", " +
+		"2001:db8::/32: int (3), 2001:db8::/64: \"2001:db8::/64\", 2001:db8::ff:0:0/96: int (5), " +
+		"2001:db8:1::1/128: \"2001:db8:1::1/128\""
+	assertTreeEnumerate(u.Enumerate(), e, "unmarshalled tree", t)
+
+	_, n, _ := net.ParseCIDR("2001:db8::ff:0:0/112")
+	u = u.InsertNet(n, "test")
+	v, ok := u.GetByIP(net.ParseIP("2001:db8::ff:0:1"))
+	assertResult(v, ok, "test", "unmarshalled tree after insertion", t)
+}
+
+func TestGobCodec(t *testing.T) {
+	var c GobCodec
+	for _, v := range []interface{}{
+		"test", "", []byte{1, 2, 3},
+		int(-1 << 40), int8(math.MinInt8), int16(math.MaxInt16), int32(-5), int64(math.MinInt64),
+		uint(1 << 40), uint8(math.MaxUint8), uint16(7), uint32(math.MaxUint32), uint64(math.MaxUint64),
+		3.5, true, []string{"a", "b"},
+	} {
+		b, err := c.MarshalValue(v)
+		if err != nil {
+			t.Errorf("Expected no error for %T (%#v) but got %s", v, v, err)
+			continue
+		}
+
+		u, err := c.UnmarshalValue(b)
+		if err != nil {
+			t.Errorf("Expected no error for %T (%#v) but got %s", v, v, err)
+		} else if !reflect.DeepEqual(u, v) {
+			t.Errorf("Expected %T (%#v) but got %T (%#v)", v, v, u, u)
+		}
+	}
+
+	b, err := c.MarshalValue("test")
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if len(b) != 5 {
+		t.Errorf("Expected string encoded to tag and data (5 bytes) but got %d bytes", len(b))
+	}
+
+	for _, b := range [][]byte{
+		{gobTagInt},
+		{gobTagInt8, 0x80},
+		{gobTagInt8, 0x02, 0x00},
+		{gobTagInt8, 0x80, 0x02},
+		{gobTagUint16, 0x80, 0x80, 0x04},
+		{gobTagUint32, 0x80, 0x80, 0x80, 0x80, 0x10},
+	} {
+		if _, err := c.UnmarshalValue(b); !errors.Is(err, ErrInvalidBinary) {
+			t.Errorf("Expected %q error for %x but got %v", ErrInvalidBinary, b, err)
+		}
+	}
+}
+
+type testCodec struct{}
+
+func (testCodec) MarshalValue(v interface{}) ([]byte, error) {
+	i, ok := v.(int)
+	if !ok {
+		return nil, fmt.Errorf("expected int but got %T", v)
+	}
+
+	return []byte(strconv.Itoa(i)), nil
+}
+
+func (testCodec) UnmarshalValue(b []byte) (interface{}, error) {
+	return strconv.Atoi(string(b))
+}
+
+func TestMarshalBinaryWithCodec(t *testing.T) {
+	var r *Tree
+	for i, s := range []string{"192.0.2.0/24", "2001:db8::/32", "2001:db8::1/128"} {
+		_, n, _ := net.ParseCIDR(s)
+		r = r.InsertNet(n, i)
+	}
+
+	b, err := r.MarshalBinaryWithCodec(testCodec{})
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	u := NewTree()
+	if err := u.UnmarshalBinaryWithCodec(b, testCodec{}); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	assertTreeEnumerate(u.Enumerate(), "192.0.2.0/24: int (0), 2001:db8::/32: int (1), 2001:db8::1/128: int (2)",
+		"tree unmarshalled with custom codec", t)
+
+	r = r.InsertIP(net.ParseIP("2001:db8::2"), "test")
+	if _, err := r.MarshalBinaryWithCodec(testCodec{}); err == nil {
+		t.Error("Expected error for value unsupported by codec but got nothing")
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	r := NewTree().InsertIP(net.ParseIP("192.0.2.1"), "test").InsertIP(net.ParseIP("2001:db8::1"), "test")
+	b, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	u := NewTree()
+	for _, c := range []struct {
+		desc string
+		data []byte
+		err  error
+	}{
+		{desc: "empty data", data: nil, err: ErrInvalidBinary},
+		{desc: "wrong magic", data: append([]byte("XYZ"), b[3:]...), err: ErrInvalidBinary},
+		{desc: "wrong version", data: append([]byte("IPT\xff"), b[4:]...), err: ErrUnsupportedVersion},
+		{desc: "truncated data", data: b[:len(b)-1], err: nil},
+		{desc: "trailing data", data: append(append([]byte{}, b...), 0), err: ErrInvalidBinary},
+		{desc: "invalid node flags", data: []byte("IPT\x01\xff"), err: nil},
+	} {
+		err := u.UnmarshalBinary(c.data)
+		if err == nil {
+			t.Errorf("Expected error for %s but got nothing", c.desc)
+		} else if c.err != nil && !errors.Is(err, c.err) {
+			t.Errorf("Expected %q error for %s but got %q", c.err, c.desc, err)
+		}
+	}
+
+	if u.root32 != nil || u.root64 != nil {
+		t.Error("Expected tree to remain empty after failed unmarshalling")
+	}
+}
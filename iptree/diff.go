@@ -0,0 +1,152 @@
+package iptree
+
+import (
+	"net"
+	"reflect"
+
+	"github.com/infobloxopen/go-trees/numtree"
+)
+
+// DiffKind is a kind of difference between two trees for a network.
+type DiffKind int
+
+const (
+	// DiffAdded marks network which is present in new tree only.
+	DiffAdded DiffKind = iota
+	// DiffRemoved marks network which is present in old tree only.
+	DiffRemoved
+	// DiffChanged marks network which has different values in old and new trees.
+	DiffChanged
+)
+
+// ChangeOf represents a difference between two trees for a network returned by Diff function. Old value is zero for added network and New value is zero for removed one.
+type ChangeOf[V any] struct {
+	Kind DiffKind
+	Key  *net.IPNet
+	Old  V
+	New  V
+}
+
+// Change represents a difference between two trees with interface{} values. Old value is nil for added network and New value is nil for removed one.
+type Change = ChangeOf[interface{}]
+
+// Diff returns changes which turn old tree to new one in order of networks. Values are compared with reflect.DeepEqual. Subtrees shared by both trees (as it happens for a tree and a result of its Insert or Delete) are skipped without visiting their nodes so the function is cheap for snapshots with few differences.
+func Diff[V any](old, new *TreeOf[V]) []ChangeOf[V] {
+	var (
+		o32, n32 *numtree.Node32Of[V]
+		o64, n64 *numtree.Node64Of[entry64[V]]
+	)
+
+	if old != nil {
+		o32, o64 = old.root32, old.root64
+	}
+
+	if new != nil {
+		n32, n64 = new.root32, new.root64
+	}
+
+	var r []ChangeOf[V]
+	o32.Diff(n32, func(a, b *numtree.Node32Of[V]) bool {
+		if c, ok := newChange32(a, b); ok {
+			r = append(r, c)
+		}
+
+		return true
+	})
+
+	o64.Diff(n64, func(a, b *numtree.Node64Of[entry64[V]]) bool {
+		if a != nil && a.Bits >= numtree.Key64BitSize || b != nil && b.Bits >= numtree.Key64BitSize {
+			var MSKey uint64
+			if a != nil {
+				MSKey = a.Key
+			} else {
+				MSKey = b.Key
+			}
+
+			subTree64Node(a).Diff(subTree64Node(b), func(a, b *numtree.Node64Of[V]) bool {
+				if c, ok := newChange64(MSKey, numtree.Key64BitSize, a, b, func(v V) V { return v }); ok {
+					r = append(r, c)
+				}
+
+				return true
+			})
+
+			return true
+		}
+
+		if c, ok := newChange64(0, 0, a, b, func(e entry64[V]) V { return e.value }); ok {
+			r = append(r, c)
+		}
+
+		return true
+	})
+
+	return r
+}
+
+func newChange32[V any](a, b *numtree.Node32Of[V]) (ChangeOf[V], bool) {
+	if a != nil && b != nil && reflect.DeepEqual(a.Value, b.Value) {
+		return ChangeOf[V]{}, false
+	}
+
+	n := a
+	if n == nil {
+		n = b
+	}
+
+	c := ChangeOf[V]{Kind: DiffChanged, Key: newIPNetFromUint32(n.Key, int(n.Bits))}
+	if a != nil {
+		c.Old = a.Value
+	} else {
+		c.Kind = DiffAdded
+	}
+
+	if b != nil {
+		c.New = b.Value
+	} else {
+		c.Kind = DiffRemoved
+	}
+
+	return c, true
+}
+
+// newChange64 makes change for nodes of IPv6 tree (if MSBits is zero) or its subtree attached at given key. Value gets network value from node value.
+func newChange64[V, T any](MSKey uint64, MSBits int, a, b *numtree.Node64Of[T], value func(T) V) (ChangeOf[V], bool) {
+	if a != nil && b != nil && reflect.DeepEqual(value(a.Value), value(b.Value)) {
+		return ChangeOf[V]{}, false
+	}
+
+	n := a
+	if n == nil {
+		n = b
+	}
+
+	c := ChangeOf[V]{Kind: DiffChanged}
+	if MSBits > 0 {
+		c.Key = newIPNetFromUint64Pair(MSKey, MSBits, n.Key, int(n.Bits))
+	} else {
+		c.Key = newIPNetFromUint64Pair(n.Key, int(n.Bits), 0, 0)
+	}
+
+	if a != nil {
+		c.Old = value(a.Value)
+	} else {
+		c.Kind = DiffAdded
+	}
+
+	if b != nil {
+		c.New = value(b.Value)
+	} else {
+		c.Kind = DiffRemoved
+	}
+
+	return c, true
+}
+
+func subTree64Node[V any](n *numtree.Node64Of[entry64[V]]) *numtree.Node64Of[V] {
+	if n == nil {
+		return nil
+	}
+
+	return n.Value.sub
+}
@@ -0,0 +1,66 @@
+package iptree
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	var r *Tree
+	assertDiff(Diff(r, r), "", "empty trees", t)
+
+	for _, s := range []string{
+		"10.0.0.0/8",
+		"192.0.2.0/24",
+		"2001:db8::/32",
+		"2001:db8::/64",
+		"2001:db8::ff:0:0/96",
+	} {
+		_, n, _ := net.ParseCIDR(s)
+		r = r.InsertNet(n, s)
+	}
+
+	assertDiff(Diff(nil, r), "+10.0.0.0/8: <nil> -> \"10.0.0.0/8\", +192.0.2.0/24: <nil> -> \"192.0.2.0/24\", "+
+		"+2001:db8::/32: <nil> -> \"2001:db8::/32\", +2001:db8::/64: <nil> -> \"2001:db8::/64\", "+
+		"+2001:db8::ff:0:0/96: <nil> -> \"2001:db8::ff:0:0/96\"", "new tree", t)
+	assertDiff(Diff(r, NewTree()), "-10.0.0.0/8: \"10.0.0.0/8\" -> <nil>, -192.0.2.0/24: \"192.0.2.0/24\" -> <nil>, "+
+		"-2001:db8::/32: \"2001:db8::/32\" -> <nil>, -2001:db8::/64: \"2001:db8::/64\" -> <nil>, "+
+		"-2001:db8::ff:0:0/96: \"2001:db8::ff:0:0/96\" -> <nil>", "removed tree", t)
+	assertDiff(Diff(r, r), "", "same tree", t)
+
+	_, n, _ := net.ParseCIDR("10.0.0.0/8")
+	u := r.InsertNet(n, "10.0.0.0/8")
+	assertDiff(Diff(r, u), "", "tree with the same value", t)
+
+	u = r.InsertNet(n, []string{"changed"})
+	_, n, _ = net.ParseCIDR("10.1.0.0/16")
+	u = u.InsertNet(n, "10.1.0.0/16")
+	_, n, _ = net.ParseCIDR("192.0.2.0/24")
+	u, _ = u.DeleteByNet(n)
+	_, n, _ = net.ParseCIDR("2001:db8::/64")
+	u, _ = u.DeleteByNet(n)
+	_, n, _ = net.ParseCIDR("2001:db8::ff:0:0/96")
+	u = u.InsertNet(n, 1)
+	_, n, _ = net.ParseCIDR("2001:db8:1::/64")
+	u = u.InsertNet(n, 2)
+
+	assertDiff(Diff(r, u), "*10.0.0.0/8: \"10.0.0.0/8\" -> []string{\"changed\"}, "+
+		"+10.1.0.0/16: <nil> -> \"10.1.0.0/16\", -192.0.2.0/24: \"192.0.2.0/24\" -> <nil>, "+
+		"-2001:db8::/64: \"2001:db8::/64\" -> <nil>, *2001:db8::ff:0:0/96: \"2001:db8::ff:0:0/96\" -> 1, "+
+		"+2001:db8:1::/64: <nil> -> 2", "changed tree", t)
+}
+
+func assertDiff(c []Change, e, desc string, t *testing.T) {
+	t.Helper()
+
+	items := make([]string, len(c))
+	for i, c := range c {
+		items[i] = fmt.Sprintf("%c%s: %#v -> %#v", "+-*"[c.Kind], c.Key, c.Old, c.New)
+	}
+
+	if s := strings.Join(items, ", "); s != e {
+		t.Errorf("Expected following changes for %s:\n\t%s\nbut got:\n\t%s", desc, e, s)
+	}
+}
@@ -0,0 +1,338 @@
+package iptree
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestTreeOfInsertNet(t *testing.T) {
+	r := NewTreeOf[int]()
+
+	newR := r.InsertNet(nil, 1)
+	if newR != r {
+		t.Errorf("Expected no changes inserting nil network but got:\n%s\n", newR.root32.Dot())
+	}
+
+	newR = r.InsertNet(&net.IPNet{IP: nil, Mask: nil}, 1)
+	if newR != r {
+		t.Errorf("Expected no changes inserting invalid network but got:\n%s\n", newR.root32.Dot())
+	}
+
+	_, n4, _ := net.ParseCIDR("192.0.2.0/24")
+	r1 := r.InsertNet(n4, 1)
+
+	_, n6, _ := net.ParseCIDR("2001:db8::/32")
+	r2 := r1.InsertNet(n6, 2)
+
+	_, n6Long, _ := net.ParseCIDR("2001:db8:0:0:0:ff::/96")
+	r3 := r2.InsertNet(n6Long, 3)
+
+	assertTreeOfEnumerate(r, "", "empty tree", t)
+	assertTreeOfEnumerate(r1, "192.0.2.0/24: 1", "tree with IPv4 network", t)
+	assertTreeOfEnumerate(r2, "192.0.2.0/24: 1, 2001:db8::/32: 2", "tree with IPv4 and IPv6 networks", t)
+	assertTreeOfEnumerate(r3, "192.0.2.0/24: 1, 2001:db8::/32: 2, 2001:db8::ff:0:0/96: 3",
+		"tree with long IPv6 network", t)
+}
+
+func TestTreeOfInplaceInsertNet(t *testing.T) {
+	r := NewTreeOf[string]()
+
+	r.InplaceInsertNet(nil, "test")
+	if r.root32 != nil || r.root64 != nil {
+		t.Error("Expected empty tree after inserting nil network")
+	}
+
+	_, n, _ := net.ParseCIDR("192.0.2.0/24")
+	r.InplaceInsertNet(n, "test 1")
+
+	_, n, _ = net.ParseCIDR("2001:db8::/32")
+	r.InplaceInsertNet(n, "test 2")
+
+	_, n, _ = net.ParseCIDR("2001:db8:0:0:0:ff::/96")
+	r.InplaceInsertNet(n, "test 3.1")
+
+	_, n, _ = net.ParseCIDR("2001:db8:0:0:0:fe::/96")
+	r.InplaceInsertNet(n, "test 3.2")
+
+	_, n, _ = net.ParseCIDR("2001:db8:0:0:0:ff::/96")
+	r.InplaceInsertNet(n, "test 3.3")
+
+	assertTreeOfEnumerate(r, "192.0.2.0/24: test 1, 2001:db8::/32: test 2, "+
+		"2001:db8::fe:0:0/96: test 3.2, 2001:db8::ff:0:0/96: test 3.3", "tree with inplace insertions", t)
+}
+
+func TestTreeOfGetByNet(t *testing.T) {
+	r := NewTreeOf[string]()
+
+	_, n4, _ := net.ParseCIDR("192.0.2.0/24")
+	r = r.InsertNet(n4, "test 1")
+
+	_, n6Short1, _ := net.ParseCIDR("2001:db8::/32")
+	r = r.InsertNet(n6Short1, "test 2.1")
+
+	_, n6Short2, _ := net.ParseCIDR("2001:db8:1::/48")
+	r = r.InsertNet(n6Short2, "test 2.2")
+
+	_, n6Long, _ := net.ParseCIDR("2001:db8:0:0:0:ff::/96")
+	r = r.InsertNet(n6Long, "test 3")
+
+	if v, ok := r.GetByNet(nil); ok {
+		t.Errorf("Expected no result for nil network but got %q", v)
+	}
+
+	assertTreeOfResult(r, "192.0.2.0/24", "test 1", t)
+	assertTreeOfResult(r, "192.0.2.0/28", "test 1", t)
+	assertTreeOfResult(r, "2001:db8::/32", "test 2.1", t)
+	assertTreeOfResult(r, "2001:db8:0:0:0:ff::/96", "test 3", t)
+	assertTreeOfResult(r, "2001:db8:0:0:0:ff::/112", "test 3", t)
+	assertTreeOfResult(r, "2001:db8:1::/64", "test 2.2", t)
+	assertTreeOfResult(r, "2001:db8:0:0:0:fe::/96", "test 2.1", t)
+	assertTreeOfResult(r, "2001:db8::/64", "test 2.1", t)
+
+	_, n, _ := net.ParseCIDR("198.51.100.0/24")
+	if v, ok := r.GetByNet(n); ok {
+		t.Errorf("Expected no result for %s but got %q", n, v)
+	}
+
+	_, n, _ = net.ParseCIDR("2001:db9::/32")
+	if v, ok := r.GetByNet(n); ok {
+		t.Errorf("Expected no result for %s but got %q", n, v)
+	}
+}
+
+func TestTreeOfDeleteByNet(t *testing.T) {
+	var r *TreeOf[string]
+
+	_, n4, _ := net.ParseCIDR("192.0.2.0/24")
+	r, ok := r.DeleteByNet(n4)
+	if ok {
+		t.Errorf("Expected no deletion in empty tree but got one")
+	}
+
+	r = r.InsertNet(n4, "test 1")
+
+	_, n6Short1, _ := net.ParseCIDR("2001:db8::/32")
+	r = r.InsertNet(n6Short1, "test 2.1")
+
+	_, n6Short2, _ := net.ParseCIDR("2001:db8:1::/48")
+	r = r.InsertNet(n6Short2, "test 2.2")
+
+	_, n6Long1, _ := net.ParseCIDR("2001:db8:0:0:0:ff::/96")
+	r = r.InsertNet(n6Long1, "test 3.1")
+
+	_, n6Long2, _ := net.ParseCIDR("2001:db8:0:0:0:fe::/96")
+	r = r.InsertNet(n6Long2, "test 3.2")
+
+	old := r
+
+	r, ok = r.DeleteByNet(n6Long2)
+	if !ok {
+		t.Errorf("Expected deletion by %s but got nothing", n6Long2)
+	}
+
+	r, ok = r.DeleteByNet(n6Long1)
+	if !ok {
+		t.Errorf("Expected deletion by %s but got nothing", n6Long1)
+	}
+
+	if v, ok := r.root64.ExactMatch(0x20010db800000000, 64); ok {
+		t.Errorf("Expected no subtree node at 0x%016x, %d after deleting all long mask addresses but got %#v",
+			0x20010db800000000, 64, v)
+	}
+
+	r, ok = r.DeleteByNet(n6Short1)
+	if !ok {
+		t.Errorf("Expected deletion by %s but got nothing", n6Short1)
+	}
+
+	r, ok = r.DeleteByNet(n4)
+	if !ok {
+		t.Errorf("Expected deletion by %s but got nothing", n4)
+	}
+
+	if r.root32 != nil || r.root64 != nil {
+		t.Errorf("Expected expected empty tree at the end but have root32: %#v and root64: %#v", r.root32, r.root64)
+	}
+
+	assertTreeOfEnumerate(old, "192.0.2.0/24: test 1, 2001:db8::/32: test 2.1, 2001:db8::fe:0:0/96: test 3.2, "+
+		"2001:db8::ff:0:0/96: test 3.1, 2001:db8:1::/48: test 2.2", "original tree after deletions", t)
+}
+
+func TestTreeOfByIP(t *testing.T) {
+	ip := net.ParseIP("2001:db8::1")
+
+	var r *TreeOf[uint16]
+	r = r.InsertIP(ip, 1)
+
+	v, ok := r.GetByIP(ip)
+	if !ok || v != 1 {
+		t.Errorf("Expected 1 for address %s but got %d (%v)", ip, v, ok)
+	}
+
+	if n := testing.AllocsPerRun(100, func() { r.GetByIP(ip) }); n > 0 {
+		t.Errorf("Expected no allocations for address %s but got %g", ip, n)
+	}
+
+	r, ok = r.DeleteByIP(ip)
+	if !ok {
+		t.Errorf("Expected deletion by address %s but got nothing", ip)
+	}
+
+	r.InplaceInsertIP(ip, 2)
+	v, ok = r.GetByIP(ip)
+	if !ok || v != 2 {
+		t.Errorf("Expected 2 for address %s but got %d (%v)", ip, v, ok)
+	}
+}
+
+func TestTreeOfUpdateDescendants(t *testing.T) {
+	r := NewTreeOf[string]()
+	for i, s := range []string{
+		"10.0.0.0/8",
+		"10.0.0.0/16",
+		"10.0.0.0/24",
+		"10.1.0.0/16",
+		"11.0.0.0/8",
+		"2001:db8::/32",
+		"2001:db8::/48",
+		"2001:db8::/64",
+		"2001:db8::/96",
+		"2001:db8:0:1::/64",
+		"2001:db9::/32",
+	} {
+		_, n, _ := net.ParseCIDR(s)
+		r.InplaceInsertNet(n, fmt.Sprintf("%d", i))
+	}
+
+	update := func(p PairOf[string]) (string, bool) {
+		return p.Value + "u", true
+	}
+
+	_, n, _ := net.ParseCIDR("10.0.0.0/8")
+	r.UpdateDescendants(n, update)
+
+	_, n, _ = net.ParseCIDR("2001:db8::/48")
+	r.UpdateDescendants(n, update)
+
+	_, n, _ = net.ParseCIDR("2001:db8:0:1::/64")
+	r.UpdateDescendants(n, update)
+
+	_, n, _ = net.ParseCIDR("192.0.2.0/24")
+	r.UpdateDescendants(n, update)
+
+	assertTreeOfEnumerate(r, "10.0.0.0/8: 0, 10.0.0.0/16: 1u, 10.0.0.0/24: 2u, 10.1.0.0/16: 3u, 11.0.0.0/8: 4, "+
+		"2001:db8::/32: 5, 2001:db8::/48: 6, 2001:db8::/64: 7u, 2001:db8::/96: 8u, 2001:db8:0:1::/64: 9u, "+
+		"2001:db9::/32: 10", "tree with updated descendants", t)
+}
+
+func TestTreeOfAll(t *testing.T) {
+	r := NewTreeOf[int]()
+	for i, s := range []string{
+		"192.0.2.0/24",
+		"2001:db8::/32",
+		"2001:db8:1::/48",
+		"2001:db8:0:0:0:ff::/96",
+	} {
+		_, n, _ := net.ParseCIDR(s)
+		r = r.InsertNet(n, i)
+	}
+
+	items := []string{}
+	for k, v := range r.All() {
+		items = append(items, fmt.Sprintf("%s: %d", k, v))
+		if len(items) >= 3 {
+			break
+		}
+	}
+
+	if s, e := strings.Join(items, ", "), "192.0.2.0/24: 0, 2001:db8::/32: 1, 2001:db8::ff:0:0/96: 3"; s != e {
+		t.Errorf("Expected following nodes %q but got %q", e, s)
+	}
+
+	count := 0
+	if r.Walk(func(n *net.IPNet, v int) bool {
+		count++
+		return count < 2
+	}) {
+		t.Error("Expected interrupted walk")
+	}
+
+	if count != 2 {
+		t.Errorf("Expected walk to stop after 2 nodes but got %d", count)
+	}
+}
+
+func TestTreeOfSharedFeatures(t *testing.T) {
+	var r *TreeOf[int]
+	for i, s := range []string{"10.0.0.0/8", "10.0.1.0/24", "2001:db8::/32", "2001:db8::ff:0:0/96"} {
+		_, n, _ := net.ParseCIDR(s)
+		r = r.InsertNet(n, i)
+	}
+
+	if n, v, ok := r.GetByIPWithNet(net.ParseIP("2001:db8::ff:0:1")); !ok || v != 3 || n.String() != "2001:db8::ff:0:0/96" {
+		t.Errorf("Expected 2001:db8::ff:0:0/96: 3 match but got %s: %d (%v)", n, v, ok)
+	}
+
+	if l := r.Len(); l != 4 {
+		t.Errorf("Expected 4 networks but got %d", l)
+	}
+
+	_, n, _ := net.ParseCIDR("2001:db8::/32")
+	items := []string{}
+	for k, v := range r.Subnets(n) {
+		items = append(items, fmt.Sprintf("%s: %d", k, v))
+	}
+
+	if s, e := strings.Join(items, ", "), "2001:db8::/32: 2, 2001:db8::ff:0:0/96: 3"; s != e {
+		t.Errorf("Expected subnets %q but got %q", e, s)
+	}
+
+	b, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	u := NewTreeOf[int]()
+	if err := u.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	assertTreeOfEnumerate(u, "10.0.0.0/8: 0, 10.0.1.0/24: 1, 2001:db8::/32: 2, 2001:db8::ff:0:0/96: 3",
+		"unmarshalled tree", t)
+
+	u = u.InsertIP(net.ParseIP("10.0.1.1"), 4)
+	if d := Diff(r, u); len(d) != 1 || d[0].Kind != DiffAdded || d[0].New != 4 {
+		t.Errorf("Expected single added network with value 4 but got %#v", d)
+	}
+
+	m := Merge(r, u, func(n *net.IPNet, a, b int) int { return a + b })
+	assertTreeOfEnumerate(m, "10.0.0.0/8: 0, 10.0.1.0/24: 2, 10.0.1.1/32: 4, 2001:db8::/32: 4, 2001:db8::ff:0:0/96: 6",
+		"merged tree", t)
+}
+
+func assertTreeOfEnumerate[V any](r *TreeOf[V], e, desc string, t *testing.T) {
+	t.Helper()
+
+	items := []string{}
+	for p := range r.Enumerate() {
+		items = append(items, fmt.Sprintf("%s: %v", p.Key, p.Value))
+	}
+
+	if s := strings.Join(items, ", "); s != e {
+		t.Errorf("Expected following nodes for %s:\n\t%q\nbut got:\n\t%q", desc, e, s)
+	}
+}
+
+func assertTreeOfResult(r *TreeOf[string], s, e string, t *testing.T) {
+	t.Helper()
+
+	_, n, _ := net.ParseCIDR(s)
+	v, ok := r.GetByNet(n)
+	if !ok {
+		t.Errorf("Expected string %q at %s but got nothing", e, s)
+	} else if v != e {
+		t.Errorf("Expected string %q at %s but got %q", e, s, v)
+	}
+}
@@ -3,8 +3,11 @@ package iptree
 
 import (
 	"fmt"
+	"iter"
 	"net"
+	"unsafe"
 
+	"github.com/infobloxopen/go-trees/internal/weight"
 	"github.com/infobloxopen/go-trees/numtree"
 )
 
@@ -18,119 +21,138 @@ var (
 	iPv6MaxMask = net.CIDRMask(iPv6Bits, iPv6Bits)
 )
 
-// Tree is a radix tree for IPv4 and IPv6 networks.
-type Tree struct {
-	root32 *numtree.Node32
-	root64 *numtree.Node64
+// TreeOf is a radix tree for IPv4 and IPv6 networks with values of type V. Values aren't boxed to interface{} so no type assertion is required on lookup.
+type TreeOf[V any] struct {
+	root32 *numtree.Node32Of[V]
+	root64 *numtree.Node64Of[entry64[V]]
 }
 
-// Pair represents a key-value pair returned by Enumerate method.
-type Pair struct {
+// Tree is a radix tree for IPv4 and IPv6 networks with interface{} values.
+type Tree = TreeOf[interface{}]
+
+// PairOf represents a key-value pair returned by Enumerate method of TreeOf.
+type PairOf[V any] struct {
 	Key   *net.IPNet
-	Value interface{}
+	Value V
+}
+
+// Pair represents a key-value pair returned by Enumerate method.
+type Pair = PairOf[interface{}]
+
+// Stats holds tree statistics returned by Stats method. IPv6 networks longer than 64 bits are kept in separate radix trees attached to the nodes of the main IPv6 tree so nodes of such trees are counted as well and their depth is added to depth of node they are attached to.
+type Stats = numtree.Stats
+
+// entry64 is a value of IPv6 radix tree node. Node for network up to 63 bits long holds value of the network while node with 64 significant bits holds subtree with the rest of bits of networks which start with the 64 bits (including the 64 bits network itself with zero bits key).
+type entry64[V any] struct {
+	value V
+	sub   *numtree.Node64Of[V]
 }
 
-type subTree64 *numtree.Node64
+// Weight returns number of networks in the entry so length of IPv6 tree includes networks of its subtrees.
+func (e *entry64[V]) Weight() weight.Int {
+	if e.sub != nil {
+		return weight.Int(e.sub.Len())
+	}
+
+	return 1
+}
+
+// NewTreeOf creates empty tree with values of type V.
+func NewTreeOf[V any]() *TreeOf[V] {
+	return &TreeOf[V]{}
+}
 
 // NewTree creates empty tree.
 func NewTree() *Tree {
-	return &Tree{}
+	return NewTreeOf[interface{}]()
 }
 
 // InsertNet inserts value using given network as a key. The method returns new tree (old one remains unaffected).
-func (t *Tree) InsertNet(n *net.IPNet, value interface{}) *Tree {
+func (t *TreeOf[V]) InsertNet(n *net.IPNet, value V) *TreeOf[V] {
 	if n == nil {
 		return t
 	}
 
 	if key, bits := iPv4NetToUint32(n); bits >= 0 {
-		var (
-			r32 *numtree.Node32
-			r64 *numtree.Node64
-		)
-
-		if t != nil {
-			r32 = t.root32
-			r64 = t.root64
-		}
-
-		return &Tree{root32: r32.Insert(key, bits, value), root64: r64}
+		return t.insert32(key, bits, value)
 	}
 
 	if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
-		var (
-			r32 *numtree.Node32
-			r64 *numtree.Node64
-		)
-
-		if t != nil {
-			r32 = t.root32
-			r64 = t.root64
-		}
-
-		if MSBits < numtree.Key64BitSize {
-			return &Tree{root32: r32, root64: r64.Insert(MSKey, MSBits, value)}
-		}
+		return t.insert64(MSKey, MSBits, LSKey, LSBits, value)
+	}
 
-		var r *numtree.Node64
-		if v, ok := r64.ExactMatch(MSKey, MSBits); ok {
-			s, ok := v.(subTree64)
-			if !ok {
-				err := fmt.Errorf("invalid IPv6 tree: expected subTree64 value at 0x%016x, %d but got %T (%#v)",
-					MSKey, MSBits, v, v)
-				panic(err)
-			}
+	return t
+}
 
-			r = (*numtree.Node64)(s)
-		}
+func (t *TreeOf[V]) insert32(key uint32, bits int, value V) *TreeOf[V] {
+	var (
+		r32 *numtree.Node32Of[V]
+		r64 *numtree.Node64Of[entry64[V]]
+	)
 
-		r = r.Insert(LSKey, LSBits, value)
-		return &Tree{root32: r32, root64: r64.Insert(MSKey, MSBits, subTree64(r))}
+	if t != nil {
+		r32 = t.root32
+		r64 = t.root64
 	}
 
-	return t
+	return &TreeOf[V]{root32: r32.Insert(key, bits, value), root64: r64}
 }
 
-type UpdateDescendantsCallback func(Pair) (interface{}, bool)
+func (t *TreeOf[V]) insert64(MSKey uint64, MSBits int, LSKey uint64, LSBits int, value V) *TreeOf[V] {
+	var (
+		r32 *numtree.Node32Of[V]
+		r64 *numtree.Node64Of[entry64[V]]
+	)
 
-func updateNode64(MSIP net.IP, n *numtree.Node64, callback UpdateDescendantsCallback) {
-	var key *net.IPNet
-	var ip net.IP
-	var mask net.IPMask
+	if t != nil {
+		r32 = t.root32
+		r64 = t.root64
+	}
 
-	if MSIP == nil {
-		ip = append(unpackUint64ToIP(n.Key), make(net.IP, 8)...)
-		mask = net.CIDRMask(int(n.Bits), iPv6Bits)
-	} else {
-		LSIP := unpackUint64ToIP(n.Key)
-		ip = append(MSIP[0:8], LSIP...)
-		mask = net.CIDRMask(numtree.Key64BitSize+int(n.Bits), iPv6Bits)
+	if MSBits < numtree.Key64BitSize {
+		return &TreeOf[V]{root32: r32, root64: r64.Insert(MSKey, MSBits, entry64[V]{value: value})}
 	}
 
-	key = &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+	r := subTree64(r64, MSKey, MSBits).Insert(LSKey, LSBits, value)
+	return &TreeOf[V]{root32: r32, root64: r64.Insert(MSKey, MSBits, entry64[V]{sub: r})}
+}
 
-	newValue, shouldUpdate := callback(Pair{Key: key, Value: n.Value})
+// UpdateDescendantsCallbackOf is a callback for UpdateDescendants method. It gets a descendant network with its value and returns new value for the network along with flag if the value should be updated.
+type UpdateDescendantsCallbackOf[V any] func(PairOf[V]) (V, bool)
+
+// UpdateDescendantsCallback is a callback for UpdateDescendants method of Tree.
+type UpdateDescendantsCallback = UpdateDescendantsCallbackOf[interface{}]
+
+func updateNode64[V any](MSIP net.IP, n *numtree.Node64Of[V], callback UpdateDescendantsCallbackOf[V]) {
+	mask := net.CIDRMask(numtree.Key64BitSize+int(n.Bits), iPv6Bits)
+	key := &net.IPNet{IP: append(MSIP[0:8], unpackUint64ToIP(n.Key)...).Mask(mask), Mask: mask}
+
+	newValue, shouldUpdate := callback(PairOf[V]{Key: key, Value: n.Value})
 	if shouldUpdate {
 		n.Value = newValue
 	}
 }
 
 // UpdateDescendants accepts a target network (node) and a callback, and updates all the descendants of the target node if the callback returns true as its second return value
-func (t *Tree) UpdateDescendants(n *net.IPNet, callback UpdateDescendantsCallback) {
+func (t *TreeOf[V]) UpdateDescendants(n *net.IPNet, callback UpdateDescendantsCallbackOf[V]) {
+	if t == nil || n == nil {
+		return
+	}
+
 	if key, bits := iPv4NetToUint32(n); bits >= 0 {
 		target := t.root32.FindNode(key, bits)
 		if target == nil {
 			return
 		}
-		nodesCh := target.Enumerate()
-		if target.Leaf {
-			<-nodesCh
-		}
-		for n := range nodesCh {
+		for n := range target.All() {
+			if n == target {
+				continue
+			}
+
 			mask := net.CIDRMask(int(n.Bits), iPv4Bits)
 			key := &net.IPNet{IP: unpackUint32ToIP(n.Key).Mask(mask), Mask: mask}
 
-			newValue, shouldUpdate := callback(Pair{Key: key, Value: n.Value})
+			newValue, shouldUpdate := callback(PairOf[V]{Key: key, Value: n.Value})
 			if shouldUpdate {
 				n.Value = newValue
 			}
@@ -142,48 +164,53 @@ func (t *Tree) UpdateDescendants(n *net.IPNet, callback UpdateDescendantsCallbac
 		if target == nil {
 			return
 		}
-		s, ok := target.Value.(subTree64)
-		if ok {
-			s2 := (*numtree.Node64)(s)
-			if s2.Key == LSKey && int(s2.Bits) == LSBits {
-				target = s2
+		if s := target.Value.sub; s != nil {
+			var target *numtree.Node64Of[V]
+			if s.Key == LSKey && int(s.Bits) == LSBits {
+				target = s
 			} else {
-				target = s2.FindNode(LSKey, LSBits)
+				target = s.FindNode(LSKey, LSBits)
 			}
 			if target == nil {
 				return
 			}
 
-			nodesCh := target.Enumerate()
-			if target.Leaf {
-				<-nodesCh
-			}
-			for n := range nodesCh {
+			for n := range target.All() {
+				if n == target {
+					continue
+				}
+
 				MSIP := append(unpackUint64ToIP(MSKey), make(net.IP, 8)...)
 				updateNode64(MSIP, n, callback)
 			}
 			return
 		}
 
-		nodesCh := target.Enumerate()
-		if target.Leaf {
-			<-nodesCh
-		}
-		for n := range nodesCh {
-			if s, ok := n.Value.(subTree64); ok {
+		for n := range target.All() {
+			if n == target {
+				continue
+			}
+
+			if s := n.Value.sub; s != nil {
 				MSIP := append(unpackUint64ToIP(n.Key), make(net.IP, 8)...)
-				for n := range (*numtree.Node64)(s).Enumerate() {
+				for n := range s.All() {
 					updateNode64(MSIP, n, callback)
 				}
 			} else {
-				updateNode64(nil, n, callback)
+				mask := net.CIDRMask(int(n.Bits), iPv6Bits)
+				key := &net.IPNet{IP: append(unpackUint64ToIP(n.Key), make(net.IP, 8)...).Mask(mask), Mask: mask}
+
+				newValue, shouldUpdate := callback(PairOf[V]{Key: key, Value: n.Value.value})
+				if shouldUpdate {
+					n.Value.value = newValue
+				}
 			}
 		}
 	}
 }
 
 // InplaceInsertNet inserts (or replaces) value using given network as a key in current tree.
-func (t *Tree) InplaceInsertNet(n *net.IPNet, value interface{}) {
+func (t *TreeOf[V]) InplaceInsertNet(n *net.IPNet, value V) {
 	if n == nil {
 		return
 	}
@@ -191,62 +218,91 @@ func (t *Tree) InplaceInsertNet(n *net.IPNet, value interface{}) {
 	if key, bits := iPv4NetToUint32(n); bits >= 0 {
 		t.root32 = t.root32.InplaceInsert(key, bits, value)
 	} else if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
-		if MSBits < numtree.Key64BitSize {
-			t.root64 = t.root64.InplaceInsert(MSKey, MSBits, value)
-		} else {
-			if v, ok := t.root64.ExactMatch(MSKey, MSBits); ok {
-				s, ok := v.(subTree64)
-				if !ok {
-					err := fmt.Errorf("invalid IPv6 tree: expected subTree64 value at 0x%016x, %d but got %T (%#v)",
-						MSKey, MSBits, v, v)
-					panic(err)
-				}
+		t.inplaceInsert64(MSKey, MSBits, LSKey, LSBits, value)
+	}
+}
 
-				r := (*numtree.Node64)(s)
-				newR := r.InplaceInsert(LSKey, LSBits, value)
-				if newR != r {
-					t.root64 = t.root64.InplaceInsert(MSKey, MSBits, subTree64(newR))
-				}
-			} else {
-				var r *numtree.Node64
-				r = r.InplaceInsert(LSKey, LSBits, value)
-				t.root64 = t.root64.InplaceInsert(MSKey, MSBits, subTree64(r))
-			}
-		}
+func (t *TreeOf[V]) inplaceInsert64(MSKey uint64, MSBits int, LSKey uint64, LSBits int, value V) {
+	if MSBits < numtree.Key64BitSize {
+		t.root64 = t.root64.InplaceInsert(MSKey, MSBits, entry64[V]{value: value})
+		return
 	}
+
+	// Put the entry back even if subtree root remains the same to update counts of IPv6 tree.
+	r := subTree64(t.root64, MSKey, MSBits).InplaceInsert(LSKey, LSBits, value)
+	t.root64 = t.root64.InplaceInsert(MSKey, MSBits, entry64[V]{sub: r})
 }
 
 // InsertIP inserts value using given IP address as a key. The method returns new tree (old one remains unaffected).
-func (t *Tree) InsertIP(ip net.IP, value interface{}) *Tree {
+func (t *TreeOf[V]) InsertIP(ip net.IP, value V) *TreeOf[V] {
 	return t.InsertNet(newIPNetFromIP(ip), value)
 }
 
 // InplaceInsertIP inserts (or replaces) value using given IP address as a key in current tree.
-func (t *Tree) InplaceInsertIP(ip net.IP, value interface{}) {
+func (t *TreeOf[V]) InplaceInsertIP(ip net.IP, value V) {
 	t.InplaceInsertNet(newIPNetFromIP(ip), value)
 }
 
 // Enumerate returns channel which is populated by key-value pairs of tree content.
-func (t *Tree) Enumerate() chan Pair {
-	ch := make(chan Pair)
+func (t *TreeOf[V]) Enumerate() chan PairOf[V] {
+	ch := make(chan PairOf[V])
 
 	go func() {
 		defer close(ch)
 
-		if t == nil {
-			return
+		for k, v := range t.All() {
+			ch <- PairOf[V]{Key: k, Value: v}
 		}
+	}()
+
+	return ch
+}
+
+// All returns iterator over key-value pairs of tree content.
+func (t *TreeOf[V]) All() iter.Seq2[*net.IPNet, V] {
+	return func(yield func(*net.IPNet, V) bool) {
+		t.Walk(yield)
+	}
+}
 
-		t.enumerate(ch)
+// Walk calls f for key-value pairs of tree content until f returns false. It reports if all the pairs have been visited.
+func (t *TreeOf[V]) Walk(f func(*net.IPNet, V) bool) bool {
+	if t == nil {
+		return true
+	}
+
+	return t.walk(f)
+}
+
+// EnumerateSubnets returns channel which is populated by key-value pairs for networks equal to or contained by given network.
+func (t *TreeOf[V]) EnumerateSubnets(n *net.IPNet) chan PairOf[V] {
+	ch := make(chan PairOf[V])
+
+	go func() {
+		defer close(ch)
+
+		for k, v := range t.Subnets(n) {
+			ch <- PairOf[V]{Key: k, Value: v}
+		}
 	}()
 
 	return ch
 }
 
+// Subnets returns iterator over key-value pairs for networks equal to or contained by given network. It walks the tree in place without copying it.
+func (t *TreeOf[V]) Subnets(n *net.IPNet) iter.Seq2[*net.IPNet, V] {
+	return func(yield func(*net.IPNet, V) bool) {
+		if t != nil && n != nil {
+			t.walkSubnets(n, yield)
+		}
+	}
+}
+
 // GetByNet gets value for network which is equal to or contains given network.
-func (t *Tree) GetByNet(n *net.IPNet) (interface{}, bool) {
+func (t *TreeOf[V]) GetByNet(n *net.IPNet) (V, bool) {
 	if t == nil || n == nil {
-		return nil, false
+		var v V
+		return v, false
 	}
 
 	if key, bits := iPv4NetToUint32(n); bits >= 0 {
@@ -254,68 +310,188 @@ func (t *Tree) GetByNet(n *net.IPNet) (interface{}, bool) {
 	}
 
 	if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
-		v, ok := t.root64.Match(MSKey, MSBits)
-		if !ok || MSBits < numtree.Key64BitSize {
-			return v, ok
+		return t.get64(MSKey, MSBits, LSKey, LSBits)
+	}
+
+	var v V
+	return v, false
+}
+
+func (t *TreeOf[V]) get64(MSKey uint64, MSBits int, LSKey uint64, LSBits int) (V, bool) {
+	n := t.root64.MatchNode(MSKey, MSBits)
+	if n == nil {
+		var v V
+		return v, false
+	}
+
+	s := n.Value.sub
+	if s == nil {
+		return n.Value.value, true
+	}
+
+	if v, ok := s.Match(LSKey, LSBits); ok {
+		return v, ok
+	}
+
+	n = t.root64.MatchNode(MSKey, numtree.Key64BitSize-1)
+	if n == nil {
+		var v V
+		return v, false
+	}
+
+	return n.Value.value, true
+}
+
+// GetByIP gets value for network which is equal to or contains given IP address.
+func (t *TreeOf[V]) GetByIP(ip net.IP) (V, bool) {
+	if t == nil {
+		var v V
+		return v, false
+	}
+
+	// Avoid newIPNetFromIP here to keep the lookup free of allocations.
+	if ip4 := ip.To4(); ip4 != nil {
+		return t.root32.Match(packIPToUint32(ip4), iPv4Bits)
+	}
+
+	if ip6 := ip.To16(); ip6 != nil {
+		return t.get64(packIPToUint64(ip6), numtree.Key64BitSize, packIPToUint64(ip6[8:]), numtree.Key64BitSize)
+	}
+
+	var v V
+	return v, false
+}
+
+// MatchNet gets network which is equal to or contains given network (longest prefix match) along with its value.
+func (t *TreeOf[V]) MatchNet(n *net.IPNet) (*net.IPNet, V, bool) {
+	var v V
+	if t == nil || n == nil {
+		return nil, v, false
+	}
+
+	if key, bits := iPv4NetToUint32(n); bits >= 0 {
+		r := t.root32.MatchNode(key, bits)
+		if r == nil {
+			return nil, v, false
 		}
 
-		s, ok := v.(subTree64)
-		if !ok {
-			return v, true
+		return newIPNetFromUint32(r.Key, int(r.Bits)), r.Value, true
+	}
+
+	if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
+		r := t.root64.MatchNode(MSKey, MSBits)
+		if r == nil {
+			return nil, v, false
 		}
 
-		v, ok = (*numtree.Node64)(s).Match(LSKey, LSBits)
-		if ok {
-			return v, ok
+		s := r.Value.sub
+		if s == nil {
+			return newIPNetFromUint64Pair(r.Key, int(r.Bits), 0, 0), r.Value.value, true
+		}
+
+		if m := s.MatchNode(LSKey, LSBits); m != nil {
+			return newIPNetFromUint64Pair(r.Key, int(r.Bits), m.Key, int(m.Bits)), m.Value, true
 		}
 
-		return t.root64.Match(MSKey, numtree.Key64BitSize-1)
+		r = t.root64.MatchNode(MSKey, numtree.Key64BitSize-1)
+		if r == nil {
+			return nil, v, false
+		}
+
+		return newIPNetFromUint64Pair(r.Key, int(r.Bits), 0, 0), r.Value.value, true
 	}
 
-	return nil, false
+	return nil, v, false
 }
 
-// GetByIP gets value for network which is equal to or contains given IP address.
-func (t *Tree) GetByIP(ip net.IP) (interface{}, bool) {
-	return t.GetByNet(newIPNetFromIP(ip))
+// GetByIPWithNet gets network which is equal to or contains given IP address along with its value.
+func (t *TreeOf[V]) GetByIPWithNet(ip net.IP) (*net.IPNet, V, bool) {
+	return t.MatchNet(newIPNetFromIP(ip))
 }
 
-// DeleteByNet removes subtree which is contained by given network. The method returns new tree (old one remains unaffected) and flag indicating if deletion happens indeed.
-func (t *Tree) DeleteByNet(n *net.IPNet) (*Tree, bool) {
+// GetAllCoveringNet gets all networks which are equal to or contain given network. The networks are ordered from the least specific to the most specific one.
+func (t *TreeOf[V]) GetAllCoveringNet(n *net.IPNet) []PairOf[V] {
 	if t == nil || n == nil {
-		return t, false
+		return nil
 	}
 
 	if key, bits := iPv4NetToUint32(n); bits >= 0 {
-		r, ok := t.root32.Delete(key, bits)
-		if ok {
-			return &Tree{root32: r, root64: t.root64}, true
+		var r []PairOf[V]
+		for _, n := range t.root32.MatchAll(key, bits) {
+			r = append(r, PairOf[V]{Key: newIPNetFromUint32(n.Key, int(n.Bits)), Value: n.Value})
 		}
-	} else if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
-		r64 := t.root64
-		if MSBits < numtree.Key64BitSize {
-			r64, ok := r64.Delete(MSKey, MSBits)
-			if ok {
-				return &Tree{root32: t.root32, root64: r64}, true
+
+		return r
+	}
+
+	if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
+		var r []PairOf[V]
+		for _, n := range t.root64.MatchAll(MSKey, MSBits) {
+			s := n.Value.sub
+			if s == nil {
+				r = append(r, PairOf[V]{Key: newIPNetFromUint64Pair(n.Key, int(n.Bits), 0, 0), Value: n.Value.value})
+				continue
 			}
-		} else if v, ok := r64.ExactMatch(MSKey, MSBits); ok {
-			s, ok := v.(subTree64)
-			if !ok {
-				err := fmt.Errorf("invalid IPv6 tree: expected subTree64 value at 0x%016x, %d but got %T (%#v)",
-					MSKey, MSBits, v, v)
-				panic(err)
+
+			for _, m := range s.MatchAll(LSKey, LSBits) {
+				r = append(r, PairOf[V]{Key: newIPNetFromUint64Pair(n.Key, int(n.Bits), m.Key, int(m.Bits)), Value: m.Value})
 			}
+		}
 
-			r, ok := (*numtree.Node64)(s).Delete(LSKey, LSBits)
-			if ok {
-				if r == nil {
-					r64, _ = r64.Delete(MSKey, MSBits)
-				} else {
-					r64 = r64.Insert(MSKey, MSBits, subTree64(r))
-				}
+		return r
+	}
+
+	return nil
+}
+
+// GetAllCovering gets all networks which contain given IP address. The networks are ordered from the least specific to the most specific one.
+func (t *TreeOf[V]) GetAllCovering(ip net.IP) []PairOf[V] {
+	return t.GetAllCoveringNet(newIPNetFromIP(ip))
+}
+
+// DeleteByNet removes subtree which is contained by given network. The method returns new tree (old one remains unaffected) and flag indicating if deletion happens indeed.
+func (t *TreeOf[V]) DeleteByNet(n *net.IPNet) (*TreeOf[V], bool) {
+	if t == nil || n == nil {
+		return t, false
+	}
+
+	if key, bits := iPv4NetToUint32(n); bits >= 0 {
+		return t.delete32(key, bits)
+	}
 
-				return &Tree{root32: t.root32, root64: r64}, true
+	if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
+		return t.delete64(MSKey, MSBits, LSKey, LSBits)
+	}
+
+	return t, false
+}
+
+func (t *TreeOf[V]) delete32(key uint32, bits int) (*TreeOf[V], bool) {
+	r, ok := t.root32.Delete(key, bits)
+	if ok {
+		return &TreeOf[V]{root32: r, root64: t.root64}, true
+	}
+
+	return t, false
+}
+
+func (t *TreeOf[V]) delete64(MSKey uint64, MSBits int, LSKey uint64, LSBits int) (*TreeOf[V], bool) {
+	r64 := t.root64
+	if MSBits < numtree.Key64BitSize {
+		r64, ok := r64.Delete(MSKey, MSBits)
+		if ok {
+			return &TreeOf[V]{root32: t.root32, root64: r64}, true
+		}
+	} else if s := subTree64(r64, MSKey, MSBits); s != nil {
+		r, ok := s.Delete(LSKey, LSBits)
+		if ok {
+			if r == nil {
+				r64, _ = r64.Delete(MSKey, MSBits)
+			} else {
+				r64 = r64.Insert(MSKey, MSBits, entry64[V]{sub: r})
 			}
+
+			return &TreeOf[V]{root32: t.root32, root64: r64}, true
 		}
 	}
 
@@ -323,41 +499,139 @@ func (t *Tree) DeleteByNet(n *net.IPNet) (*Tree, bool) {
 }
 
 // DeleteByIP removes node by given IP address. The method returns new tree (old one remains unaffected) and flag indicating if deletion happens indeed.
-func (t *Tree) DeleteByIP(ip net.IP) (*Tree, bool) {
+func (t *TreeOf[V]) DeleteByIP(ip net.IP) (*TreeOf[V], bool) {
 	return t.DeleteByNet(newIPNetFromIP(ip))
 }
 
-func (t *Tree) enumerate(ch chan Pair) {
-	for n := range t.root32.Enumerate() {
-		mask := net.CIDRMask(int(n.Bits), iPv4Bits)
-		ch <- Pair{
-			Key: &net.IPNet{
-				IP:   unpackUint32ToIP(n.Key).Mask(mask),
-				Mask: mask},
-			Value: n.Value}
+// Len returns number of networks in the tree. It takes constant time.
+func (t *TreeOf[V]) Len() int {
+	if t == nil {
+		return 0
+	}
+
+	return t.root32.Len() + t.root64.Len()
+}
+
+// Stats walks the tree and collects its statistics. IPv4 and IPv6 trees are counted together and MaxDepth is the greatest of their depths.
+func (t *TreeOf[V]) Stats() Stats {
+	var s Stats
+	if t == nil {
+		return s
+	}
+
+	s = t.root32.Stats()
+	stats64(t.root64, 1, &s)
+	return s
+}
+
+func (t *TreeOf[V]) walk(f func(*net.IPNet, V) bool) bool {
+	return walk32(t.root32, f) && walk64(t.root64, f)
+}
+
+func (t *TreeOf[V]) walkSubnets(n *net.IPNet, f func(*net.IPNet, V) bool) bool {
+	if key, bits := iPv4NetToUint32(n); bits >= 0 {
+		return walk32(t.root32.FindSubtree(key, bits), f)
+	}
+
+	if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
+		if MSBits < numtree.Key64BitSize {
+			return walk64(t.root64.FindSubtree(MSKey, MSBits), f)
+		}
+
+		s := t.root64.FindNode(MSKey, MSBits)
+		if s == nil || s.Value.sub == nil {
+			return true
+		}
+
+		MSIP := append(unpackUint64ToIP(MSKey), make(net.IP, 8)...)
+		return walkSubTree64(MSIP, s.Value.sub.FindSubtree(LSKey, LSBits), f)
+	}
+
+	return true
+}
+
+// subTree64 returns subtree attached to IPv6 tree node with given key or nil if there is no such node. It panics if the node holds a value instead of a subtree.
+func subTree64[V any](r *numtree.Node64Of[entry64[V]], MSKey uint64, MSBits int) *numtree.Node64Of[V] {
+	n := r.FindNode(MSKey, MSBits)
+	if n == nil {
+		return nil
+	}
+
+	if n.Value.sub == nil {
+		err := fmt.Errorf("invalid IPv6 tree: expected subtree at 0x%016x, %d but got value %#v",
+			MSKey, MSBits, n.Value.value)
+		panic(err)
+	}
+
+	return n.Value.sub
+}
+
+func stats64[V any](n *numtree.Node64Of[entry64[V]], depth int, s *Stats) {
+	if n == nil {
+		return
+	}
+
+	s.Nodes++
+	s.Bytes += int(unsafe.Sizeof(*n))
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	if v := n.Value.sub; v != nil && n.Leaf {
+		ss := v.Stats()
+		s.Nodes += ss.Nodes
+		s.Leaves += ss.Leaves
+		s.Bytes += ss.Bytes
+		if depth+ss.MaxDepth > s.MaxDepth {
+			s.MaxDepth = depth + ss.MaxDepth
+		}
+	} else if n.Leaf {
+		s.Leaves++
+	}
+
+	l, r := n.Children()
+	stats64(l, depth+1, s)
+	stats64(r, depth+1, s)
+}
+
+func walk32[V any](r *numtree.Node32Of[V], f func(*net.IPNet, V) bool) bool {
+	for n := range r.All() {
+		if !f(newIPNetFromUint32(n.Key, int(n.Bits)), n.Value) {
+			return false
+		}
 	}
 
-	for n := range t.root64.Enumerate() {
+	return true
+}
+
+func walk64[V any](r *numtree.Node64Of[entry64[V]], f func(*net.IPNet, V) bool) bool {
+	for n := range r.All() {
 		MSIP := append(unpackUint64ToIP(n.Key), make(net.IP, 8)...)
-		if s, ok := n.Value.(subTree64); ok {
-			for n := range (*numtree.Node64)(s).Enumerate() {
-				LSIP := unpackUint64ToIP(n.Key)
-				mask := net.CIDRMask(numtree.Key64BitSize+int(n.Bits), iPv6Bits)
-				ch <- Pair{
-					Key: &net.IPNet{
-						IP:   append(MSIP[0:8], LSIP...).Mask(mask),
-						Mask: mask},
-					Value: n.Value}
+		if s := n.Value.sub; s != nil {
+			if !walkSubTree64(MSIP, s, f) {
+				return false
 			}
 		} else {
 			mask := net.CIDRMask(int(n.Bits), iPv6Bits)
-			ch <- Pair{
-				Key: &net.IPNet{
-					IP:   MSIP.Mask(mask),
-					Mask: mask},
-				Value: n.Value}
+			if !f(&net.IPNet{IP: MSIP.Mask(mask), Mask: mask}, n.Value.value) {
+				return false
+			}
 		}
 	}
+
+	return true
+}
+
+func walkSubTree64[V any](MSIP net.IP, r *numtree.Node64Of[V], f func(*net.IPNet, V) bool) bool {
+	for n := range r.All() {
+		LSIP := unpackUint64ToIP(n.Key)
+		mask := net.CIDRMask(numtree.Key64BitSize+int(n.Bits), iPv6Bits)
+		if !f(&net.IPNet{IP: append(MSIP[0:8], LSIP...).Mask(mask), Mask: mask}, n.Value) {
+			return false
+		}
+	}
+
+	return true
 }
 
 func iPv4NetToUint32(n *net.IPNet) (uint32, int) {
@@ -381,6 +655,11 @@ func unpackUint32ToIP(x uint32) net.IP {
 	return net.IP{byte(x >> 24 & 0xff), byte(x >> 16 & 0xff), byte(x >> 8 & 0xff), byte(x & 0xff)}
 }
 
+func newIPNetFromUint32(key uint32, bits int) *net.IPNet {
+	mask := net.CIDRMask(bits, iPv4Bits)
+	return &net.IPNet{IP: unpackUint32ToIP(key).Mask(mask), Mask: mask}
+}
+
 func iPv6NetToUint64Pair(n *net.IPNet) (uint64, int, uint64, int) {
 	if len(n.IP) != net.IPv6len {
 		return 0, -1, 0, -1
@@ -402,6 +681,11 @@ func iPv6NetToUint64Pair(n *net.IPNet) (uint64, int, uint64, int) {
 	return packIPToUint64(n.IP), MSBits, packIPToUint64(n.IP[8:]), LSBits
 }
 
+func newIPNetFromUint64Pair(MSKey uint64, MSBits int, LSKey uint64, LSBits int) *net.IPNet {
+	mask := net.CIDRMask(MSBits+LSBits, iPv6Bits)
+	return &net.IPNet{IP: append(unpackUint64ToIP(MSKey), unpackUint64ToIP(LSKey)...).Mask(mask), Mask: mask}
+}
+
 func packIPToUint64(x net.IP) uint64 {
 	return (uint64(x[0]) << 56) | (uint64(x[1]) << 48) | (uint64(x[2]) << 40) | (uint64(x[3]) << 32) |
 		(uint64(x[4]) << 24) | (uint64(x[5]) << 16) | (uint64(x[6]) << 8) | uint64(x[7])
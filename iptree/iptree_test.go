@@ -59,7 +59,7 @@ func TestInsertNet(t *testing.T) {
 	}
 
 	invR := NewTree()
-	invR.root64 = invR.root64.Insert(0x20010db800000000, 64, "test")
+	invR.root64 = invR.root64.Insert(0x20010db800000000, 64, entry64[interface{}]{value: "test"})
 	_, n, _ = net.ParseCIDR("2001:db8:0:0:0:ff::/96")
 	assertPanic(func() { invR.InsertNet(n, "panic") }, "inserting to invalid IPv6 tree", t)
 }
@@ -113,13 +113,13 @@ func TestInplaceInsertNet(t *testing.T) {
 	}
 
 	invR := NewTree()
-	invR.root64 = invR.root64.Insert(0x20010db800000000, 64, "test")
+	invR.root64 = invR.root64.Insert(0x20010db800000000, 64, entry64[interface{}]{value: "test"})
 	_, n, _ = net.ParseCIDR("2001:db8:0:0:0:ff::/96")
 	assertPanic(func() { invR.InplaceInsertNet(n, "panic") }, "inserting to invalid IPv6 tree", t)
 }
 
-func (p Pair) String() string {
-	s, ok := p.Value.(string)
+func (p PairOf[V]) String() string {
+	s, ok := any(p.Value).(string)
 	if ok {
 		return fmt.Sprintf("%s: %q", p.Key, s)
 	}
@@ -164,6 +164,56 @@ func TestEnumerate(t *testing.T) {
 	}
 }
 
+func TestAll(t *testing.T) {
+	var r *Tree
+
+	for k, v := range r.All() {
+		t.Errorf("Expected no nodes in empty tree but got at least one: %s: %#v", k, v)
+	}
+
+	r = NewTree()
+
+	_, n, _ := net.ParseCIDR("192.0.2.0/24")
+	r = r.InsertNet(n, "test 1")
+
+	_, n, _ = net.ParseCIDR("2001:db8::/32")
+	r = r.InsertNet(n, "test 2.1")
+
+	_, n, _ = net.ParseCIDR("2001:db8:1::/48")
+	r = r.InsertNet(n, "test 2.2")
+
+	_, n, _ = net.ParseCIDR("2001:db8:0:0:0:ff::/96")
+	r = r.InsertNet(n, "test 3")
+
+	items := []string{}
+	for k, v := range r.All() {
+		items = append(items, Pair{Key: k, Value: v}.String())
+		if len(items) >= 3 {
+			break
+		}
+	}
+
+	s := strings.Join(items, ", ")
+	e := "192.0.2.0/24: \"test 1\", " +
+		"2001:db8::/32: \"test 2.1\", " +
+		"2001:db8::ff:0:0/96: \"test 3\""
+	if s != e {
+		t.Errorf("Expected following nodes %q but got %q", e, s)
+	}
+
+	count := 0
+	if r.Walk(func(n *net.IPNet, v interface{}) bool {
+		count++
+		return count < 2
+	}) {
+		t.Error("Expected interrupted walk")
+	}
+
+	if count != 2 {
+		t.Errorf("Expected walk to stop after 2 nodes but got %d", count)
+	}
+}
+
 func TestUpdateDescendants(t *testing.T) {
 	type action struct {
 		network string
@@ -203,7 +253,6 @@ func TestUpdateDescendants(t *testing.T) {
 					return v, true
 				}
 				panic("new value not provided")
-				return nil, false
 			},
 			updatedTree: "" +
 				"\n==== 32 bit ====\n" +
@@ -242,7 +291,6 @@ func TestUpdateDescendants(t *testing.T) {
 					return v, true
 				}
 				panic("new value not provided")
-				return nil, false
 			},
 			updatedTree: "" +
 				"\n==== 32 bit ====\n" +
@@ -280,7 +328,6 @@ func TestUpdateDescendants(t *testing.T) {
 					return v, true
 				}
 				panic("new value not provided")
-				return nil, false
 			},
 			updatedTree: "" +
 				"\n==== 32 bit ====\n" +
@@ -319,7 +366,6 @@ func TestUpdateDescendants(t *testing.T) {
 					return v, true
 				}
 				panic("new value not provided")
-				return nil, false
 			},
 			updatedTree: "" +
 				"\n==== 32 bit ====\n" +
@@ -364,7 +410,6 @@ func TestUpdateDescendants(t *testing.T) {
 				}
 				fmt.Printf("DEBUG: KEY (%s) -- VALUE (%#v)\n", p.Key, p.Value)
 				panic("new value not provided")
-				return nil, false
 			},
 			updatedTree: "" +
 				"\n==== 32 bit ====\n" +
@@ -401,7 +446,6 @@ func TestUpdateDescendants(t *testing.T) {
 					return v, true
 				}
 				panic("new value not provided")
-				return nil, false
 			},
 			updatedTree: "" +
 				"\n==== 32 bit ====\n" +
@@ -443,7 +487,6 @@ func TestUpdateDescendants(t *testing.T) {
 					return v, true
 				}
 				panic("new value not provided")
-				return nil, false
 			},
 			updatedTree: "" +
 				"\n==== 32 bit ====\n" +
@@ -480,7 +523,6 @@ func TestUpdateDescendants(t *testing.T) {
 					return v, true
 				}
 				panic("new value not provided")
-				return nil, false
 			},
 			updatedTree: "" +
 				"\n==== 32 bit ====\n" +
@@ -523,7 +565,6 @@ func TestUpdateDescendants(t *testing.T) {
 					return v, true
 				}
 				panic("new value not provided")
-				return nil, false
 			},
 			updatedTree: "" +
 				"\n==== 32 bit ====\n" +
@@ -574,7 +615,6 @@ func TestUpdateDescendants(t *testing.T) {
 					return v, true
 				}
 				panic("new value not provided")
-				return nil, false
 			},
 			updatedTree: "" +
 				"\n==== 32 bit ====\n" +
@@ -629,7 +669,6 @@ func TestUpdateDescendants(t *testing.T) {
 					return v, true
 				}
 				panic("new value not provided")
-				return nil, false
 			},
 			updatedTree: "" +
 				"\n==== 32 bit ====\n" +
@@ -722,6 +761,177 @@ func TestGetByNet(t *testing.T) {
 	assertResult(v, ok, "test 2.1", fmt.Sprintf("%s", n6), t)
 }
 
+func TestMatchNet(t *testing.T) {
+	r := NewTree()
+
+	_, n4, _ := net.ParseCIDR("192.0.2.0/24")
+	r = r.InsertNet(n4, "test 1")
+
+	_, n6Short1, _ := net.ParseCIDR("2001:db8::/32")
+	r = r.InsertNet(n6Short1, "test 2.1")
+
+	_, n6Short2, _ := net.ParseCIDR("2001:db8:1::/48")
+	r = r.InsertNet(n6Short2, "test 2.2")
+
+	_, n6Long, _ := net.ParseCIDR("2001:db8:0:0:0:ff::/96")
+	r = r.InsertNet(n6Long, "test 3")
+
+	_, n6Exact, _ := net.ParseCIDR("2001:db8:2::/64")
+	r = r.InsertNet(n6Exact, "test 4")
+
+	m, v, ok := r.MatchNet(nil)
+	if ok {
+		t.Errorf("Expected no result for nil network but got %s: %T (%#v)", m, v, v)
+	}
+
+	m, v, ok = r.MatchNet(&net.IPNet{IP: nil, Mask: nil})
+	if ok {
+		t.Errorf("Expected no result for invalid network but got %s: %T (%#v)", m, v, v)
+	}
+
+	assertMatchNet(r, "192.0.2.0/28", "192.0.2.0/24", "test 1", t)
+	assertMatchNet(r, "2001:db8::/32", "2001:db8::/32", "test 2.1", t)
+	assertMatchNet(r, "2001:db8:0:0:0:ff::/112", "2001:db8::ff:0:0/96", "test 3", t)
+	assertMatchNet(r, "2001:db8:1::/64", "2001:db8:1::/48", "test 2.2", t)
+	assertMatchNet(r, "2001:db8:0:0:0:fe::/96", "2001:db8::/32", "test 2.1", t)
+	assertMatchNet(r, "2001:db8:2::/64", "2001:db8:2::/64", "test 4", t)
+	assertMatchNet(r, "2001:db8:2::1/128", "2001:db8:2::/64", "test 4", t)
+
+	for _, s := range []string{"198.51.100.0/24", "2001:db9::/32", "2001:db9::1/128"} {
+		_, n, _ := net.ParseCIDR(s)
+		if m, v, ok := r.MatchNet(n); ok {
+			t.Errorf("Expected no result for %s but got %s: %T (%#v)", s, m, v, v)
+		}
+	}
+
+	ip := net.ParseIP("192.0.2.1")
+	m, v, ok = r.GetByIPWithNet(ip)
+	assertResult(v, ok, "test 1", fmt.Sprintf("address %s", ip), t)
+	if m.String() != "192.0.2.0/24" {
+		t.Errorf("Expected 192.0.2.0/24 network for address %s but got %s", ip, m)
+	}
+
+	ip = net.ParseIP("2001:db8::ff:0:1")
+	m, v, ok = r.GetByIPWithNet(ip)
+	assertResult(v, ok, "test 3", fmt.Sprintf("address %s", ip), t)
+	if m.String() != "2001:db8::ff:0:0/96" {
+		t.Errorf("Expected 2001:db8::ff:0:0/96 network for address %s but got %s", ip, m)
+	}
+}
+
+func TestGetAllCovering(t *testing.T) {
+	var r *Tree
+
+	if p := r.GetAllCovering(net.ParseIP("192.0.2.1")); len(p) > 0 {
+		t.Errorf("Expected no networks in empty tree but got %d", len(p))
+	}
+
+	for _, s := range []string{
+		"0.0.0.0/0",
+		"192.0.2.0/24",
+		"192.0.2.0/28",
+		"192.0.2.16/28",
+		"::/0",
+		"2001:db8::/32",
+		"2001:db8::/64",
+		"2001:db8::/96",
+		"2001:db8::ff:0:0/96",
+		"2001:db8::1/128",
+		"2001:db8:1::/48",
+	} {
+		_, n, _ := net.ParseCIDR(s)
+		r = r.InsertNet(n, s)
+	}
+
+	if p := r.GetAllCoveringNet(nil); len(p) > 0 {
+		t.Errorf("Expected no networks for nil network but got %d", len(p))
+	}
+
+	if p := r.GetAllCoveringNet(&net.IPNet{IP: nil, Mask: nil}); len(p) > 0 {
+		t.Errorf("Expected no networks for invalid network but got %d", len(p))
+	}
+
+	assertCovering(r.GetAllCovering(net.ParseIP("192.0.2.1")), "192.0.2.1", t,
+		"0.0.0.0/0", "192.0.2.0/24", "192.0.2.0/28")
+	assertCovering(r.GetAllCovering(net.ParseIP("198.51.100.1")), "198.51.100.1", t,
+		"0.0.0.0/0")
+	assertCovering(r.GetAllCovering(net.ParseIP("2001:db8::1")), "2001:db8::1", t,
+		"::/0", "2001:db8::/32", "2001:db8::/64", "2001:db8::/96", "2001:db8::1/128")
+	assertCovering(r.GetAllCovering(net.ParseIP("2001:db8::ff:0:1")), "2001:db8::ff:0:1", t,
+		"::/0", "2001:db8::/32", "2001:db8::/64", "2001:db8::ff:0:0/96")
+	assertCovering(r.GetAllCovering(net.ParseIP("2001:db8:1::1")), "2001:db8:1::1", t,
+		"::/0", "2001:db8::/32", "2001:db8:1::/48")
+
+	_, n, _ := net.ParseCIDR("2001:db8::/48")
+	assertCovering(r.GetAllCoveringNet(n), n.String(), t, "::/0", "2001:db8::/32")
+}
+
+func TestEnumerateSubnets(t *testing.T) {
+	var r *Tree
+
+	_, n, _ := net.ParseCIDR("10.0.0.0/8")
+	assertTreeEnumerate(r.EnumerateSubnets(n), "", "empty tree", t)
+
+	for _, s := range []string{
+		"10.0.0.0/8",
+		"10.0.0.0/16",
+		"10.1.0.0/16",
+		"10.1.2.0/24",
+		"11.0.0.0/8",
+		"2001:db8::/32",
+		"2001:db8::/64",
+		"2001:db8::ff:0:0/96",
+		"2001:db8:0:1::/64",
+		"2001:db8:1::/48",
+		"2001:db9::/32",
+	} {
+		_, n, _ := net.ParseCIDR(s)
+		r = r.InsertNet(n, s)
+	}
+
+	assertTreeEnumerate(r.EnumerateSubnets(nil), "", "nil network", t)
+
+	assertTreeEnumerate(r.EnumerateSubnets(n),
+		"10.0.0.0/8: \"10.0.0.0/8\", 10.0.0.0/16: \"10.0.0.0/16\", 10.1.0.0/16: \"10.1.0.0/16\", "+
+			"10.1.2.0/24: \"10.1.2.0/24\"", n.String(), t)
+
+	_, n, _ = net.ParseCIDR("10.1.0.0/16")
+	assertTreeEnumerate(r.EnumerateSubnets(n),
+		"10.1.0.0/16: \"10.1.0.0/16\", 10.1.2.0/24: \"10.1.2.0/24\"", n.String(), t)
+
+	_, n, _ = net.ParseCIDR("192.0.2.0/24")
+	assertTreeEnumerate(r.EnumerateSubnets(n), "", n.String(), t)
+
+	_, n, _ = net.ParseCIDR("2001:db8::/33")
+	assertTreeEnumerate(r.EnumerateSubnets(n),
+		"2001:db8::/64: \"2001:db8::/64\", 2001:db8::ff:0:0/96: \"2001:db8::ff:0:0/96\", "+
+			"2001:db8:0:1::/64: \"2001:db8:0:1::/64\", 2001:db8:1::/48: \"2001:db8:1::/48\"", n.String(), t)
+
+	_, n, _ = net.ParseCIDR("2001:db8::/64")
+	assertTreeEnumerate(r.EnumerateSubnets(n),
+		"2001:db8::/64: \"2001:db8::/64\", 2001:db8::ff:0:0/96: \"2001:db8::ff:0:0/96\"", n.String(), t)
+
+	_, n, _ = net.ParseCIDR("2001:db8::ff:0:0/80")
+	assertTreeEnumerate(r.EnumerateSubnets(n),
+		"2001:db8::ff:0:0/96: \"2001:db8::ff:0:0/96\"", n.String(), t)
+
+	_, n, _ = net.ParseCIDR("2001:db8:0:2::/64")
+	assertTreeEnumerate(r.EnumerateSubnets(n), "", n.String(), t)
+
+	_, n, _ = net.ParseCIDR("::/0")
+	count := 0
+	for range r.Subnets(n) {
+		count++
+		if count >= 2 {
+			break
+		}
+	}
+
+	if count != 2 {
+		t.Errorf("Expected iteration to stop after 2 subnets but got %d", count)
+	}
+}
+
 func TestDeleteByNet(t *testing.T) {
 	var r *Tree
 
@@ -790,7 +1000,7 @@ func TestDeleteByNet(t *testing.T) {
 		t.Errorf("Expected expected empty tree at the end but have root32: %#v and root64: %#v", r.root32, r.root64)
 	}
 
-	r.root64 = r.root64.Insert(0x20010db800000000, 64, "panic")
+	r.root64 = r.root64.Insert(0x20010db800000000, 64, entry64[interface{}]{value: "panic"})
 	assertPanic(func() { r.DeleteByNet(n6Long1) }, "deletion from invalid tree", t)
 }
 
@@ -817,6 +1027,110 @@ func TestTreeByIP(t *testing.T) {
 	}
 }
 
+func TestLen(t *testing.T) {
+	var r *Tree
+	assertLen(r, 0, "nil tree", t)
+
+	r = newTestTree(
+		"192.0.2.0/24",
+		"192.0.2.0/28",
+		"2001:db8::/32",
+		"2001:db8::/48",
+		"2001:db8::/64",
+		"2001:db8::1/128",
+		"2001:db8::2/128",
+		"2001:db8:0:1::/96",
+	)
+	assertLen(r, 8, "tree", t)
+	assertLen(r.InsertNet(parseTestNet("2001:db8::1/128"), "replaced"), 8, "tree with replaced IPv6 value", t)
+	assertLen(r.InsertNet(parseTestNet("2001:db8::/32"), "replaced"), 8, "tree with replaced short IPv6 value", t)
+
+	d, ok := r.DeleteByNet(parseTestNet("2001:db8::/48"))
+	if !ok {
+		t.Errorf("Expected 2001:db8::/48 to be deleted")
+	}
+	assertLen(d, 3, "tree after deletion of IPv6 network with subnets", t)
+
+	d, ok = r.DeleteByNet(parseTestNet("2001:db8::1/128"))
+	if !ok {
+		t.Errorf("Expected 2001:db8::1/128 to be deleted")
+	}
+	assertLen(d, 7, "tree after deletion of long IPv6 network", t)
+
+	d, ok = d.DeleteByNet(parseTestNet("192.0.2.0/24"))
+	if !ok {
+		t.Errorf("Expected 192.0.2.0/24 to be deleted")
+	}
+	assertLen(d, 5, "tree after deletion of IPv4 network", t)
+	assertLen(r, 8, "original tree after deletions", t)
+
+	c := NewTree()
+	for _, s := range []string{"192.0.2.0/24", "2001:db8::/32", "2001:db8::1/128", "2001:db8::2/128"} {
+		c.InplaceInsertNet(parseTestNet(s), s)
+	}
+
+	c.InplaceInsertNet(parseTestNet("2001:db8::1/128"), "replaced")
+	c.InplaceInsertNet(parseTestNet("2001:db8::/32"), "replaced")
+	assertLen(c, 4, "tree after inplace insert", t)
+
+	e := NewTree()
+	e.InplaceInsertNet(parseTestNet("2001:db8::1/128"), "first")
+	e.InplaceInsertNet(parseTestNet("2001:db8::/112"), "parent")
+	e.InplaceInsertNet(parseTestNet("2001:db8::2/128"), "second")
+	assertLen(e, 3, "tree after inplace insert to existing subtree", t)
+
+	m := Merge(r, c, func(n *net.IPNet, a, b interface{}) interface{} { return a })
+	assertLen(m, 8, "merged tree", t)
+
+	m = Merge(r, newTestTree("2001:db8::3/128", "2001:db8:1::/48"), func(n *net.IPNet, a, b interface{}) interface{} { return a })
+	assertLen(m, 10, "merged tree with new networks", t)
+
+	b, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	u := new(Tree)
+	if err := u.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+	assertLen(u, 8, "unmarshalled tree", t)
+}
+
+type testHits struct {
+	n int
+}
+
+func (h testHits) Count() int {
+	return h.n
+}
+
+func TestLenWithCountMethod(t *testing.T) {
+	r := NewTreeOf[testHits]().
+		InsertNet(parseTestNet("10.0.0.0/8"), testHits{n: 7}).
+		InsertNet(parseTestNet("2001:db8::/32"), testHits{n: 100}).
+		InsertNet(parseTestNet("2001:db8::1/128"), testHits{n: 100})
+	r.InplaceInsertNet(parseTestNet("2001:db8::2/128"), testHits{n: 100})
+
+	if l := r.Len(); l != 4 {
+		t.Errorf("Expected Len 4 for tree with values having Count method but got %d", l)
+	}
+}
+
+func TestStats(t *testing.T) {
+	var r *Tree
+	if s := r.Stats(); s != (Stats{}) {
+		t.Errorf("Expected empty stats for nil tree but got %#v", s)
+	}
+
+	r = newTestTree("192.0.2.0/24", "192.0.2.0/28", "2001:db8::/32", "2001:db8::1/128", "2001:db8::2/128")
+
+	s := r.Stats()
+	if s.Nodes != 7 || s.Leaves != 5 || s.MaxDepth != 4 || s.Bytes <= 0 {
+		t.Errorf("Expected 7 nodes, 5 leaves and depth 4 but got %#v", s)
+	}
+}
+
 func TestIPv4NetToUint32(t *testing.T) {
 	_, n, _ := net.ParseCIDR("192.0.2.0/24")
 	key, bits := iPv4NetToUint32(n)
@@ -909,18 +1223,17 @@ func assertTree64Node(r *Tree, MSKey uint64, MSBits int, LSKey uint64, LSBits in
 	v, ok := r.root64.ExactMatch(MSKey, MSBits)
 	if ok {
 		if MSBits < 64 {
-			assertResult(v, ok, e, desc, t)
+			assertResult(v.value, ok, e, desc, t)
 		} else {
-			r, ok := v.(subTree64)
-			if ok {
-				v, ok := (*numtree.Node64)(r).ExactMatch(LSKey, LSBits)
+			if v.sub != nil {
+				v, ok := v.sub.ExactMatch(LSKey, LSBits)
 				if ok {
 					assertResult(v, ok, e, desc, t)
 				} else {
 					t.Errorf("Expected string %q at %s but got nothing at second hop", e, desc)
 				}
 			} else {
-				t.Errorf("Expected subTree64 at %s (first hop) but got %T (%#v)", desc, v, v)
+				t.Errorf("Expected subtree at %s (first hop) but got %T (%#v)", desc, v.value, v.value)
 			}
 		}
 	} else {
@@ -932,6 +1245,59 @@ func assertTree64Node(r *Tree, MSKey uint64, MSBits int, LSKey uint64, LSBits in
 	}
 }
 
+func assertMatchNet(r *Tree, s, en, e string, t *testing.T) {
+	_, n, _ := net.ParseCIDR(s)
+	m, v, ok := r.MatchNet(n)
+	assertResult(v, ok, e, s, t)
+	if ok && m.String() != en {
+		t.Errorf("Expected %s network for %s but got %s", en, s, m)
+	}
+}
+
+func assertCovering(p []Pair, desc string, t *testing.T, e ...string) {
+	t.Helper()
+
+	keys := make([]string, len(p))
+	for i, p := range p {
+		keys[i] = p.Key.String()
+		if p.Value != keys[i] {
+			t.Errorf("Expected value %q for %s but got %#v", keys[i], desc, p.Value)
+		}
+	}
+
+	if s, e := strings.Join(keys, ", "), strings.Join(e, ", "); s != e {
+		t.Errorf("Expected following covering networks for %s:\n\t%q\nbut got:\n\t%q", desc, e, s)
+	}
+}
+
+func assertLen(r *Tree, e int, desc string, t *testing.T) {
+	if n := r.Len(); n != e {
+		t.Errorf("Expected %d networks in %s but got %d", e, desc, n)
+	}
+
+	n := 0
+	for range r.All() {
+		n++
+	}
+
+	if n != r.Len() {
+		t.Errorf("Expected length of %s to match %d enumerated networks but got %d", desc, n, r.Len())
+	}
+}
+
+func assertTreeEnumerate(ch chan Pair, e, desc string, t *testing.T) {
+	t.Helper()
+
+	items := []string{}
+	for p := range ch {
+		items = append(items, p.String())
+	}
+
+	if s := strings.Join(items, ", "); s != e {
+		t.Errorf("Expected following nodes for %s:\n\t%q\nbut got:\n\t%q", desc, e, s)
+	}
+}
+
 func assertPanic(f func(), desc string, t *testing.T) {
 	defer func() {
 		if r := recover(); r == nil {
@@ -960,7 +1326,7 @@ func assertResult(v interface{}, ok bool, e, desc string, t *testing.T) {
 	t.Errorf("Expected string %q at %s but got nothing", e, desc)
 }
 
-func (t *Tree) String() string {
+func (t *TreeOf[V]) String() string {
 	var sb strings.Builder
 	sb.WriteString("\n==== 32 bit ====\n")
 	if t.root32 != nil {
@@ -977,11 +1343,11 @@ func (t *Tree) String() string {
 	return sb.String()
 }
 
-func debugNode32(tree *numtree.Node32) string {
+func debugNode32[V any](tree *numtree.Node32Of[V]) string {
 	var sb strings.Builder
 
-	var walk func(n *numtree.Node32, indent int)
-	walk = func(n *numtree.Node32, indent int) {
+	var walk func(n *numtree.Node32Of[V], indent int)
+	walk = func(n *numtree.Node32Of[V], indent int) {
 		sb.WriteString(fmt.Sprintf("%s%s/%d - (%#v)\n", strings.Repeat("\t", indent), unpackUint32ToIP(n.Key), n.Bits, n.Value))
 		c1, c2 := n.Children()
 		if c1 != nil {
@@ -996,13 +1362,13 @@ func debugNode32(tree *numtree.Node32) string {
 	return sb.String()
 }
 
-func debugNode64(tree *numtree.Node64) string {
+func debugNode64[V any](tree *numtree.Node64Of[entry64[V]]) string {
 	var sb strings.Builder
 
-	var walk func(n *numtree.Node64, indent int)
-	var walkSubtree func(MSIP net.IP, bits int, n *numtree.Node64, indent int)
+	var walk func(n *numtree.Node64Of[entry64[V]], indent int)
+	var walkSubtree func(MSIP net.IP, bits int, n *numtree.Node64Of[V], indent int)
 
-	walkSubtree = func(MSIP net.IP, bits int, n *numtree.Node64, indent int) {
+	walkSubtree = func(MSIP net.IP, bits int, n *numtree.Node64Of[V], indent int) {
 		LSIP := unpackUint64ToIP(n.Key)
 		mask := net.CIDRMask(numtree.Key64BitSize+int(n.Bits), iPv6Bits)
 		sb.WriteString(
@@ -1011,28 +1377,24 @@ func debugNode64(tree *numtree.Node64) string {
 			),
 		)
 
-		if s, ok := n.Value.(subTree64); ok {
-			walkSubtree(MSIP, bits, s, indent+1)
-		} else {
-			c1, c2 := n.Children()
-			if c1 != nil {
-				walkSubtree(MSIP, bits, c1, indent+1)
-			}
+		c1, c2 := n.Children()
+		if c1 != nil {
+			walkSubtree(MSIP, bits, c1, indent+1)
+		}
 
-			if c2 != nil {
-				walkSubtree(MSIP, bits, c2, indent+1)
-			}
+		if c2 != nil {
+			walkSubtree(MSIP, bits, c2, indent+1)
 		}
 	}
 
-	walk = func(n *numtree.Node64, indent int) {
+	walk = func(n *numtree.Node64Of[entry64[V]], indent int) {
 		ip := unpackUint64ToIP(n.Key)
 		MSIP := append(ip, make(net.IP, 8)...)
 		bits := n.Bits
 
 		c1, c2 := n.Children()
 
-		if isNil(n.Value) {
+		if n.Value.sub == nil && isNil(n.Value.value) {
 			if c1 != nil {
 				walk(c1, indent)
 			}
@@ -1042,12 +1404,12 @@ func debugNode64(tree *numtree.Node64) string {
 			}
 		} else {
 			indent2 := indent
-			if s, ok := n.Value.(subTree64); ok {
+			if s := n.Value.sub; s != nil {
 				walkSubtree(MSIP, int(bits), s, indent2)
 			} else {
 				sb.WriteString(
 					fmt.Sprintf("%s%s/%d (%#v)\n",
-						strings.Repeat("\t", indent), MSIP, bits, n.Value,
+						strings.Repeat("\t", indent), MSIP, bits, n.Value.value,
 					),
 				)
 			}
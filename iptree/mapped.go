@@ -0,0 +1,598 @@
+package iptree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"iter"
+	"math"
+	"net"
+	"os"
+
+	"github.com/infobloxopen/go-trees/numtree"
+)
+
+// MappedVersion is a version of flat format produced by WriteMapped.
+const MappedVersion = 1
+
+var mappedMagic = []byte("IPM")
+
+// Layout of flat format. Header is followed by array of IPv4 nodes, array of IPv6 nodes and values. Each node is a fixed size record with big-endian key, significant bits, flags, indexes of children (index plus one, zero for no child) and offset of value. For IPv6 node with subtree the value is index (plus one) of subtree root in the same array. Each value is stored as unsigned varint length followed by data produced by ValueCodec.
+const (
+	mappedHeaderSize = 24
+	mappedNode32Size = 24
+	mappedNode64Size = 32
+
+	mappedFlagLeaf    = 1
+	mappedFlagSubTree = 2
+)
+
+// MappedTree is a read-only view of IPv4 and IPv6 radix tree over data in flat format. The data is accessed directly so loading doesn't create any nodes on heap and memory mapped file can be shared between processes.
+type MappedTree struct {
+	n32    []byte
+	n64    []byte
+	values []byte
+	root64 uint32
+	c      ValueCodec
+	unmap  func() error
+}
+
+// WriteMapped writes the tree to w in flat format suitable for OpenMappedTree and NewMappedTree. Values are encoded with given codec.
+func (t *TreeOf[V]) WriteMapped(w io.Writer, c ValueCodec) error {
+	m := &mappedWriter{c: c}
+
+	if t != nil {
+		if _, err := addMapped32(m, t.root32); err != nil {
+			return err
+		}
+
+		value := func(v V) (uint64, byte, error) {
+			off, err := m.addValue(v)
+			return off, 0, err
+		}
+
+		root64, err := addMapped64(m, t.root64, func(v entry64[V]) (uint64, byte, error) {
+			if v.sub == nil {
+				return value(v.value)
+			}
+
+			k, err := addMapped64(m, v.sub, value)
+			return uint64(k), mappedFlagSubTree, err
+		})
+		if err != nil {
+			return err
+		}
+
+		m.root64 = root64
+	}
+
+	if len(m.n32)/mappedNode32Size > math.MaxUint32 || len(m.n64)/mappedNode64Size > math.MaxUint32 {
+		return ErrInvalidBinary
+	}
+
+	h := make([]byte, mappedHeaderSize)
+	copy(h, mappedMagic)
+	h[len(mappedMagic)] = MappedVersion
+	binary.BigEndian.PutUint32(h[4:], uint32(len(m.n32)/mappedNode32Size))
+	binary.BigEndian.PutUint32(h[8:], uint32(len(m.n64)/mappedNode64Size))
+	binary.BigEndian.PutUint32(h[12:], m.root64)
+	binary.BigEndian.PutUint64(h[16:], uint64(len(m.values)))
+
+	for _, b := range [][]byte{h, m.n32, m.n64, m.values} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OpenMappedTree maps file written by WriteMapped to memory and returns read-only tree over it. Values are decoded with given codec on access. The tree should be closed with Close when it isn't needed anymore.
+func OpenMappedTree(path string, c ValueCodec) (*MappedTree, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Size() < mappedHeaderSize || info.Size() > math.MaxInt {
+		return nil, ErrInvalidBinary
+	}
+
+	data, unmap, err := mapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := NewMappedTree(data, c)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+
+	t.unmap = unmap
+	return t, nil
+}
+
+// NewMappedTree returns read-only tree over data written by WriteMapped. The data must not be changed while the tree is in use.
+func NewMappedTree(data []byte, c ValueCodec) (*MappedTree, error) {
+	if len(data) < mappedHeaderSize || !bytes.Equal(data[:len(mappedMagic)], mappedMagic) {
+		return nil, ErrInvalidBinary
+	}
+
+	if data[len(mappedMagic)] != MappedVersion {
+		return nil, ErrUnsupportedVersion
+	}
+
+	n32 := uint64(binary.BigEndian.Uint32(data[4:])) * mappedNode32Size
+	n64 := uint64(binary.BigEndian.Uint32(data[8:])) * mappedNode64Size
+	size := binary.BigEndian.Uint64(data[16:])
+	if uint64(len(data)-mappedHeaderSize) != n32+n64+size {
+		return nil, ErrInvalidBinary
+	}
+
+	root64 := binary.BigEndian.Uint32(data[12:])
+	data = data[mappedHeaderSize:]
+	return &MappedTree{
+		n32:    data[:n32],
+		n64:    data[n32 : n32+n64],
+		values: data[n32+n64:],
+		root64: root64,
+		c:      c,
+	}, nil
+}
+
+// Close releases memory mapped by OpenMappedTree. The tree can't be used after the call.
+func (t *MappedTree) Close() error {
+	unmap := t.unmap
+	*t = MappedTree{}
+	if unmap != nil {
+		return unmap()
+	}
+
+	return nil
+}
+
+// GetByNet gets value for network which is equal to or contains given network.
+func (t *MappedTree) GetByNet(n *net.IPNet) (interface{}, bool) {
+	if t == nil || n == nil {
+		return nil, false
+	}
+
+	if key, bits := iPv4NetToUint32(n); bits >= 0 {
+		return t.get32(key, bits)
+	}
+
+	if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
+		return t.get64(MSKey, MSBits, LSKey, LSBits)
+	}
+
+	return nil, false
+}
+
+// GetByIP gets value for network which is equal to or contains given IP address.
+func (t *MappedTree) GetByIP(ip net.IP) (interface{}, bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return t.get32(packIPToUint32(ip4), iPv4Bits)
+	}
+
+	if ip6 := ip.To16(); ip6 != nil {
+		return t.get64(packIPToUint64(ip6), numtree.Key64BitSize, packIPToUint64(ip6[8:]), numtree.Key64BitSize)
+	}
+
+	return nil, false
+}
+
+// Enumerate returns channel which is populated by key-value pairs of tree content.
+func (t *MappedTree) Enumerate() chan Pair {
+	ch := make(chan Pair)
+
+	go func() {
+		defer close(ch)
+
+		for k, v := range t.All() {
+			ch <- Pair{Key: k, Value: v}
+		}
+	}()
+
+	return ch
+}
+
+// All returns iterator over key-value pairs of tree content.
+func (t *MappedTree) All() iter.Seq2[*net.IPNet, interface{}] {
+	return func(yield func(*net.IPNet, interface{}) bool) {
+		t.Walk(yield)
+	}
+}
+
+// Walk calls f for key-value pairs of tree content until f returns false. It reports if all the pairs have been visited.
+func (t *MappedTree) Walk(f func(*net.IPNet, interface{}) bool) bool {
+	if t == nil {
+		return true
+	}
+
+	if len(t.n32) > 0 && !t.walk32(1, -1, f) {
+		return false
+	}
+
+	return t.walk64(t.root64, -1, 0, 0, f)
+}
+
+// Len returns number of networks in the tree. It scans node records without decoding values so it takes time proportional to number of nodes.
+func (t *MappedTree) Len() int {
+	if t == nil {
+		return 0
+	}
+
+	n := 0
+	for i := 0; i < len(t.n32); i += mappedNode32Size {
+		if t.n32[i+5]&mappedFlagLeaf != 0 {
+			n++
+		}
+	}
+
+	for i := 0; i < len(t.n64); i += mappedNode64Size {
+		if t.n64[i+9]&(mappedFlagLeaf|mappedFlagSubTree) == mappedFlagLeaf {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Stats walks node records of the tree and collects its statistics the same way as Stats method of TreeOf. Bytes is a size of the node records.
+func (t *MappedTree) Stats() Stats {
+	var s Stats
+	if t == nil {
+		return s
+	}
+
+	if len(t.n32) > 0 {
+		t.stats32(1, -1, 1, &s)
+	}
+
+	t.stats64(t.root64, -1, 1, false, &s)
+	return s
+}
+
+func (t *MappedTree) get32(key uint32, bits int) (interface{}, bool) {
+	v, ok := t.match32(key, uint8(bits))
+	if !ok {
+		return nil, false
+	}
+
+	return t.value(v)
+}
+
+func (t *MappedTree) get64(MSKey uint64, MSBits int, LSKey uint64, LSBits int) (interface{}, bool) {
+	v, flags, ok := t.match64(t.root64, MSKey, uint8(MSBits))
+	if !ok {
+		return nil, false
+	}
+
+	if flags&mappedFlagSubTree == 0 {
+		return t.value(v)
+	}
+
+	if v <= math.MaxUint32 {
+		if v, flags, ok := t.match64(uint32(v), LSKey, uint8(LSBits)); ok && flags&mappedFlagSubTree == 0 {
+			return t.value(v)
+		}
+	}
+
+	v, flags, ok = t.match64(t.root64, MSKey, numtree.Key64BitSize-1)
+	if !ok || flags&mappedFlagSubTree != 0 {
+		return nil, false
+	}
+
+	return t.value(v)
+}
+
+func (t *MappedTree) match32(key uint32, bits uint8) (uint64, bool) {
+	var (
+		v  uint64
+		ok bool
+	)
+
+	prev := -1
+	for i := uint32(1); ; {
+		r := t.node32(i)
+		if r == nil {
+			break
+		}
+
+		nBits := r[4]
+		if int(nBits) <= prev || nBits > bits || (binary.BigEndian.Uint32(r)^key)&mask32(nBits) != 0 {
+			break
+		}
+
+		if r[5]&mappedFlagLeaf != 0 {
+			v = binary.BigEndian.Uint64(r[16:])
+			ok = true
+		}
+
+		if nBits >= bits {
+			break
+		}
+
+		prev = int(nBits)
+		i = binary.BigEndian.Uint32(r[8+4*((key>>(numtree.Key32BitSize-1-nBits))&1):])
+	}
+
+	return v, ok
+}
+
+func (t *MappedTree) match64(i uint32, key uint64, bits uint8) (uint64, byte, bool) {
+	var (
+		v     uint64
+		flags byte
+		ok    bool
+	)
+
+	prev := -1
+	for {
+		r := t.node64(i)
+		if r == nil {
+			break
+		}
+
+		nBits := r[8]
+		if int(nBits) <= prev || nBits > bits || (binary.BigEndian.Uint64(r)^key)&mask64(nBits) != 0 {
+			break
+		}
+
+		if r[9]&mappedFlagLeaf != 0 {
+			v = binary.BigEndian.Uint64(r[24:])
+			flags = r[9]
+			ok = true
+		}
+
+		if nBits >= bits {
+			break
+		}
+
+		prev = int(nBits)
+		i = binary.BigEndian.Uint32(r[16+4*((key>>(numtree.Key64BitSize-1-nBits))&1):])
+	}
+
+	return v, flags, ok
+}
+
+func (t *MappedTree) walk32(i uint32, prev int, f func(*net.IPNet, interface{}) bool) bool {
+	r := t.node32(i)
+	if r == nil || int(r[4]) <= prev || r[4] > numtree.Key32BitSize {
+		return true
+	}
+
+	if r[5]&mappedFlagLeaf != 0 {
+		if v, ok := t.value(binary.BigEndian.Uint64(r[16:])); ok {
+			if !f(newIPNetFromUint32(binary.BigEndian.Uint32(r), int(r[4])), v) {
+				return false
+			}
+		}
+	}
+
+	return t.walk32(binary.BigEndian.Uint32(r[8:]), int(r[4]), f) &&
+		t.walk32(binary.BigEndian.Uint32(r[12:]), int(r[4]), f)
+}
+
+func (t *MappedTree) walk64(i uint32, prev int, MSKey uint64, MSBits int, f func(*net.IPNet, interface{}) bool) bool {
+	r := t.node64(i)
+	if r == nil || int(r[8]) <= prev || r[8] > numtree.Key64BitSize {
+		return true
+	}
+
+	key := binary.BigEndian.Uint64(r)
+	bits := int(r[8])
+	if r[9]&mappedFlagLeaf != 0 {
+		v := binary.BigEndian.Uint64(r[24:])
+		if r[9]&mappedFlagSubTree != 0 {
+			if MSBits <= 0 && bits >= numtree.Key64BitSize && v <= math.MaxUint32 &&
+				!t.walk64(uint32(v), -1, key, bits, f) {
+				return false
+			}
+		} else if v, ok := t.value(v); ok {
+			n := newIPNetFromUint64Pair(key, bits, 0, 0)
+			if MSBits > 0 {
+				n = newIPNetFromUint64Pair(MSKey, MSBits, key, bits)
+			}
+
+			if !f(n, v) {
+				return false
+			}
+		}
+	}
+
+	return t.walk64(binary.BigEndian.Uint32(r[16:]), bits, MSKey, MSBits, f) &&
+		t.walk64(binary.BigEndian.Uint32(r[20:]), bits, MSKey, MSBits, f)
+}
+
+func (t *MappedTree) stats32(i uint32, prev, depth int, s *Stats) {
+	r := t.node32(i)
+	if r == nil || int(r[4]) <= prev || r[4] > numtree.Key32BitSize {
+		return
+	}
+
+	s.Nodes++
+	s.Bytes += mappedNode32Size
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	if r[5]&mappedFlagLeaf != 0 {
+		s.Leaves++
+	}
+
+	t.stats32(binary.BigEndian.Uint32(r[8:]), int(r[4]), depth+1, s)
+	t.stats32(binary.BigEndian.Uint32(r[12:]), int(r[4]), depth+1, s)
+}
+
+func (t *MappedTree) stats64(i uint32, prev, depth int, sub bool, s *Stats) {
+	r := t.node64(i)
+	if r == nil || int(r[8]) <= prev || r[8] > numtree.Key64BitSize {
+		return
+	}
+
+	s.Nodes++
+	s.Bytes += mappedNode64Size
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	bits := int(r[8])
+	if r[9]&mappedFlagLeaf != 0 {
+		if r[9]&mappedFlagSubTree == 0 {
+			s.Leaves++
+		} else if v := binary.BigEndian.Uint64(r[24:]); !sub && bits >= numtree.Key64BitSize && v <= math.MaxUint32 {
+			t.stats64(uint32(v), -1, depth+1, true, s)
+		}
+	}
+
+	t.stats64(binary.BigEndian.Uint32(r[16:]), bits, depth+1, sub, s)
+	t.stats64(binary.BigEndian.Uint32(r[20:]), bits, depth+1, sub, s)
+}
+
+func (t *MappedTree) node32(i uint32) []byte {
+	if i == 0 || uint64(i) > uint64(len(t.n32)/mappedNode32Size) {
+		return nil
+	}
+
+	off := int(i-1) * mappedNode32Size
+	return t.n32[off : off+mappedNode32Size]
+}
+
+func (t *MappedTree) node64(i uint32) []byte {
+	if i == 0 || uint64(i) > uint64(len(t.n64)/mappedNode64Size) {
+		return nil
+	}
+
+	off := int(i-1) * mappedNode64Size
+	return t.n64[off : off+mappedNode64Size]
+}
+
+func (t *MappedTree) value(off uint64) (interface{}, bool) {
+	if off >= uint64(len(t.values)) {
+		return nil, false
+	}
+
+	b := t.values[off:]
+	size, n := binary.Uvarint(b)
+	if n <= 0 || uint64(len(b)-n) < size {
+		return nil, false
+	}
+
+	v, err := t.c.UnmarshalValue(b[n : n+int(size)])
+	if err != nil {
+		return nil, false
+	}
+
+	return v, true
+}
+
+func mask32(bits uint8) uint32 {
+	return ^uint32(0) << (numtree.Key32BitSize - bits)
+}
+
+func mask64(bits uint8) uint64 {
+	return ^uint64(0) << (numtree.Key64BitSize - bits)
+}
+
+type mappedWriter struct {
+	n32    []byte
+	n64    []byte
+	values []byte
+	root64 uint32
+	c      ValueCodec
+}
+
+func addMapped32[V any](m *mappedWriter, n *numtree.Node32Of[V]) (uint32, error) {
+	if n == nil {
+		return 0, nil
+	}
+
+	i := len(m.n32) / mappedNode32Size
+	m.n32 = append(m.n32, make([]byte, mappedNode32Size)...)
+
+	r := m.n32[i*mappedNode32Size:]
+	binary.BigEndian.PutUint32(r, n.Key)
+	r[4] = n.Bits
+	if n.Leaf {
+		r[5] = mappedFlagLeaf
+
+		off, err := m.addValue(n.Value)
+		if err != nil {
+			return 0, err
+		}
+
+		binary.BigEndian.PutUint64(r[16:], off)
+	}
+
+	c0, c1 := n.Children()
+	for j, c := range []*numtree.Node32Of[V]{c0, c1} {
+		k, err := addMapped32(m, c)
+		if err != nil {
+			return 0, err
+		}
+
+		binary.BigEndian.PutUint32(m.n32[i*mappedNode32Size+8+4*j:], k)
+	}
+
+	return uint32(i + 1), nil
+}
+
+// addMapped64 adds IPv6 tree or subtree. Value callback adds node value and returns its offset (or index of subtree root) along with additional flags.
+func addMapped64[T any](m *mappedWriter, n *numtree.Node64Of[T], value func(v T) (uint64, byte, error)) (uint32, error) {
+	if n == nil {
+		return 0, nil
+	}
+
+	i := len(m.n64) / mappedNode64Size
+	m.n64 = append(m.n64, make([]byte, mappedNode64Size)...)
+
+	r := m.n64[i*mappedNode64Size:]
+	binary.BigEndian.PutUint64(r, n.Key)
+	r[8] = n.Bits
+
+	c0, c1 := n.Children()
+	for j, c := range []*numtree.Node64Of[T]{c0, c1} {
+		k, err := addMapped64(m, c, value)
+		if err != nil {
+			return 0, err
+		}
+
+		binary.BigEndian.PutUint32(m.n64[i*mappedNode64Size+16+4*j:], k)
+	}
+
+	if n.Leaf {
+		v, flags, err := value(n.Value)
+		if err != nil {
+			return 0, err
+		}
+
+		r = m.n64[i*mappedNode64Size:]
+		r[9] = mappedFlagLeaf | flags
+		binary.BigEndian.PutUint64(r[24:], v)
+	}
+
+	return uint32(i + 1), nil
+}
+
+func (m *mappedWriter) addValue(v interface{}) (uint64, error) {
+	data, err := m.c.MarshalValue(v)
+	if err != nil {
+		return 0, err
+	}
+
+	off := uint64(len(m.values))
+	m.values = append(binary.AppendUvarint(m.values, uint64(len(data))), data...)
+	return off, nil
+}
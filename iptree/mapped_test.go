@@ -0,0 +1,148 @@
+package iptree
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMappedTree(t *testing.T) {
+	var r *Tree
+	for _, s := range []string{
+		"10.0.0.0/8",
+		"10.1.0.0/16",
+		"192.0.2.0/24",
+		"2001:db8::/32",
+		"2001:db8::/63",
+		"2001:db8::/64",
+		"2001:db8::ff:0:0/96",
+		"2001:db8:0:1::1/128",
+	} {
+		_, n, _ := net.ParseCIDR(s)
+		r = r.InsertNet(n, s)
+	}
+
+	var b bytes.Buffer
+	if err := r.WriteMapped(&b, GobCodec{}); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "tree.ipm")
+	if err := os.WriteFile(path, b.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := OpenMappedTree(path, GobCodec{})
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+	defer m.Close()
+
+	e := "10.0.0.0/8: \"10.0.0.0/8\", 10.1.0.0/16: \"10.1.0.0/16\", 192.0.2.0/24: \"192.0.2.0/24\", " +
+		"2001:db8::/32: \"2001:db8::/32\", 2001:db8::/63: \"2001:db8::/63\", 2001:db8::/64: \"2001:db8::/64\", " +
+		"2001:db8::ff:0:0/96: \"2001:db8::ff:0:0/96\", 2001:db8:0:1::1/128: \"2001:db8:0:1::1/128\""
+	assertTreeEnumerate(m.Enumerate(), e, "mapped tree", t)
+
+	if n := m.Len(); n != r.Len() {
+		t.Errorf("Expected %d networks in mapped tree but got %d", r.Len(), n)
+	}
+
+	es := r.Stats()
+	n32 := r.root32.Stats().Nodes
+	es.Bytes = n32*mappedNode32Size + (es.Nodes-n32)*mappedNode64Size
+	if s := m.Stats(); s != es {
+		t.Errorf("Expected %#v for mapped tree but got %#v", es, s)
+	}
+
+	for _, c := range []struct {
+		ip string
+		e  string
+	}{
+		{ip: "10.0.0.1", e: "10.0.0.0/8"},
+		{ip: "10.1.0.1", e: "10.1.0.0/16"},
+		{ip: "192.0.2.1", e: "192.0.2.0/24"},
+		{ip: "2001:db8:1::1", e: "2001:db8::/32"},
+		{ip: "2001:db8::1", e: "2001:db8::/64"},
+		{ip: "2001:db8::ff:0:1", e: "2001:db8::ff:0:0/96"},
+		{ip: "2001:db8:0:1::1", e: "2001:db8:0:1::1/128"},
+		{ip: "2001:db8:0:1::2", e: "2001:db8::/63"},
+	} {
+		v, ok := m.GetByIP(net.ParseIP(c.ip))
+		assertResult(v, ok, c.e, "mapped tree for "+c.ip, t)
+	}
+
+	for _, s := range []string{"192.0.3.1", "2001:db9::1"} {
+		if v, ok := m.GetByIP(net.ParseIP(s)); ok {
+			t.Errorf("Expected no value for %s but got %#v", s, v)
+		}
+	}
+
+	_, n, _ := net.ParseCIDR("10.1.2.0/24")
+	v, ok := m.GetByNet(n)
+	assertResult(v, ok, "10.1.0.0/16", "mapped tree for 10.1.2.0/24", t)
+
+	_, n, _ = net.ParseCIDR("2001:db8::ff:0:0/112")
+	v, ok = m.GetByNet(n)
+	assertResult(v, ok, "2001:db8::ff:0:0/96", "mapped tree for 2001:db8::ff:0:0/112", t)
+
+	var c int
+	m.Walk(func(*net.IPNet, interface{}) bool {
+		c++
+		return c < 3
+	})
+	if c != 3 {
+		t.Errorf("Expected walk to stop after 3 items but got %d", c)
+	}
+}
+
+func TestMappedTreeEmpty(t *testing.T) {
+	var r *Tree
+
+	var b bytes.Buffer
+	if err := r.WriteMapped(&b, testCodec{}); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	m, err := NewMappedTree(b.Bytes(), testCodec{})
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	assertTreeEnumerate(m.Enumerate(), "", "empty mapped tree", t)
+	if n := m.Len(); n != 0 {
+		t.Errorf("Expected no networks in empty mapped tree but got %d", n)
+	}
+
+	if s := m.Stats(); s != (Stats{}) {
+		t.Errorf("Expected empty stats for empty mapped tree but got %#v", s)
+	}
+
+	if v, ok := m.GetByIP(net.ParseIP("192.0.2.1")); ok {
+		t.Errorf("Expected no value but got %#v", v)
+	}
+}
+
+func TestNewMappedTreeErrors(t *testing.T) {
+	var b bytes.Buffer
+	if err := NewTree().WriteMapped(&b, testCodec{}); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	data := b.Bytes()
+	for _, c := range []struct {
+		data []byte
+		err  error
+	}{
+		{data: nil, err: ErrInvalidBinary},
+		{data: append([]byte("XYZ"), data[3:]...), err: ErrInvalidBinary},
+		{data: append([]byte("IPM\x02"), data[4:]...), err: ErrUnsupportedVersion},
+		{data: append(append([]byte{}, data...), 0), err: ErrInvalidBinary},
+	} {
+		if _, err := NewMappedTree(c.data, testCodec{}); !errors.Is(err, c.err) {
+			t.Errorf("Expected %q for %q but got %v", c.err, c.data, err)
+		}
+	}
+}
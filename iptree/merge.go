@@ -0,0 +1,40 @@
+package iptree
+
+import (
+	"net"
+
+	"github.com/infobloxopen/go-trees/numtree"
+)
+
+// MergeResolverOf returns value for network present in both merged trees. Argument a is a value from the first tree and b from the second one.
+type MergeResolverOf[V any] func(n *net.IPNet, a, b V) V
+
+// MergeResolver returns value for network present in both merged trees with interface{} values.
+type MergeResolver = MergeResolverOf[interface{}]
+
+// Merge returns new tree which contains networks of both given trees. For network present in both trees resolve is called to get value for the result. Radix trees are merged directly without enumeration and reinsertion so subtrees which have no counterpart in the other tree are shared with the result. Neither tree is modified.
+func Merge[V any](a, b *TreeOf[V], resolve MergeResolverOf[V]) *TreeOf[V] {
+	if a == nil {
+		return b
+	}
+
+	if b == nil {
+		return a
+	}
+
+	return &TreeOf[V]{
+		root32: a.root32.Merge(b.root32, func(key uint32, bits int, a, b V) V {
+			return resolve(newIPNetFromUint32(key, bits), a, b)
+		}),
+		root64: a.root64.Merge(b.root64, func(key uint64, bits int, a, b entry64[V]) entry64[V] {
+			if a.sub == nil || b.sub == nil {
+				return entry64[V]{value: resolve(newIPNetFromUint64Pair(key, bits, 0, 0), a.value, b.value)}
+			}
+
+			MSKey := key
+			return entry64[V]{sub: a.sub.Merge(b.sub, func(key uint64, bits int, a, b V) V {
+				return resolve(newIPNetFromUint64Pair(MSKey, numtree.Key64BitSize, key, bits), a, b)
+			})}
+		}),
+	}
+}
@@ -0,0 +1,42 @@
+package iptree
+
+import (
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	resolve := func(n *net.IPNet, a, b interface{}) interface{} {
+		return fmt.Sprintf("%s: %s+%s", n, a, b)
+	}
+
+	var a *Tree
+	for _, s := range []string{"10.0.0.0/8", "192.0.2.0/24", "2001:db8::/32", "2001:db8::/64", "2001:db8::ff:0:0/96"} {
+		_, n, _ := net.ParseCIDR(s)
+		a = a.InsertNet(n, "a")
+	}
+
+	var b *Tree
+	for _, s := range []string{"10.1.0.0/16", "192.0.2.0/24", "2001:db8::/32", "2001:db8::ff:0:0/96", "2001:db8:1::/64"} {
+		_, n, _ := net.ParseCIDR(s)
+		b = b.InsertNet(n, "b")
+	}
+
+	assertTreeEnumerate(Merge(nil, nil, resolve).Enumerate(), "", "merge of empty trees", t)
+	assertTreeEnumerate(Merge(a, nil, resolve).Enumerate(), "10.0.0.0/8: \"a\", 192.0.2.0/24: \"a\", "+
+		"2001:db8::/32: \"a\", 2001:db8::/64: \"a\", 2001:db8::ff:0:0/96: \"a\"", "merge with empty tree", t)
+
+	m := Merge(a, b, resolve)
+	assertTreeEnumerate(m.Enumerate(), "10.0.0.0/8: \"a\", 10.1.0.0/16: \"b\", "+
+		"192.0.2.0/24: \"192.0.2.0/24: a+b\", 2001:db8::/32: \"2001:db8::/32: a+b\", 2001:db8::/64: \"a\", "+
+		"2001:db8::ff:0:0/96: \"2001:db8::ff:0:0/96: a+b\", 2001:db8:1::/64: \"b\"", "merged tree", t)
+
+	v, ok := m.GetByIP(net.ParseIP("2001:db8::1"))
+	assertResult(v, ok, "a", "merged tree for 2001:db8::1", t)
+
+	assertTreeEnumerate(a.Enumerate(), "10.0.0.0/8: \"a\", 192.0.2.0/24: \"a\", "+
+		"2001:db8::/32: \"a\", 2001:db8::/64: \"a\", 2001:db8::ff:0:0/96: \"a\"", "first tree after merge", t)
+	assertTreeEnumerate(b.Enumerate(), "10.1.0.0/16: \"b\", 192.0.2.0/24: \"b\", "+
+		"2001:db8::/32: \"b\", 2001:db8::ff:0:0/96: \"b\", 2001:db8:1::/64: \"b\"", "second tree after merge", t)
+}
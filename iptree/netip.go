@@ -0,0 +1,188 @@
+package iptree
+
+import (
+	"encoding/binary"
+	"iter"
+	"net/netip"
+
+	"github.com/infobloxopen/go-trees/numtree"
+)
+
+// PrefixPairOf represents a key-value pair returned by EnumeratePrefixes method of TreeOf.
+type PrefixPairOf[V any] struct {
+	Key   netip.Prefix
+	Value V
+}
+
+// PrefixPair represents a key-value pair returned by EnumeratePrefixes method.
+type PrefixPair = PrefixPairOf[interface{}]
+
+// InsertPrefix inserts value using given prefix as a key. The method returns new tree (old one remains unaffected). IPv4-mapped IPv6 prefixes are treated as IPv6 ones.
+func (t *TreeOf[V]) InsertPrefix(p netip.Prefix, value V) *TreeOf[V] {
+	if key, bits := iPv4PrefixToUint32(p); bits >= 0 {
+		return t.insert32(key, bits, value)
+	}
+
+	if MSKey, MSBits, LSKey, LSBits := iPv6PrefixToUint64Pair(p); MSBits >= 0 {
+		return t.insert64(MSKey, MSBits, LSKey, LSBits, value)
+	}
+
+	return t
+}
+
+// InplaceInsertPrefix inserts (or replaces) value using given prefix as a key in current tree.
+func (t *TreeOf[V]) InplaceInsertPrefix(p netip.Prefix, value V) {
+	if key, bits := iPv4PrefixToUint32(p); bits >= 0 {
+		t.root32 = t.root32.InplaceInsert(key, bits, value)
+	} else if MSKey, MSBits, LSKey, LSBits := iPv6PrefixToUint64Pair(p); MSBits >= 0 {
+		t.inplaceInsert64(MSKey, MSBits, LSKey, LSBits, value)
+	}
+}
+
+// InsertAddr inserts value using given address as a key. The method returns new tree (old one remains unaffected). IPv4-mapped IPv6 address is treated as IPv4 one as InsertIP does.
+func (t *TreeOf[V]) InsertAddr(a netip.Addr, value V) *TreeOf[V] {
+	return t.InsertPrefix(newPrefixFromAddr(a), value)
+}
+
+// InplaceInsertAddr inserts (or replaces) value using given address as a key in current tree. IPv4-mapped IPv6 address is treated as IPv4 one.
+func (t *TreeOf[V]) InplaceInsertAddr(a netip.Addr, value V) {
+	t.InplaceInsertPrefix(newPrefixFromAddr(a), value)
+}
+
+// EnumeratePrefixes returns channel which is populated by prefix-value pairs of tree content.
+func (t *TreeOf[V]) EnumeratePrefixes() chan PrefixPairOf[V] {
+	ch := make(chan PrefixPairOf[V])
+
+	go func() {
+		defer close(ch)
+
+		for k, v := range t.AllPrefixes() {
+			ch <- PrefixPairOf[V]{Key: k, Value: v}
+		}
+	}()
+
+	return ch
+}
+
+// AllPrefixes returns iterator over prefix-value pairs of tree content.
+func (t *TreeOf[V]) AllPrefixes() iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		if t != nil {
+			t.walkPrefixes(yield)
+		}
+	}
+}
+
+// GetByPrefix gets value for network which is equal to or contains given prefix. The method doesn't allocate.
+func (t *TreeOf[V]) GetByPrefix(p netip.Prefix) (V, bool) {
+	if t == nil {
+		var v V
+		return v, false
+	}
+
+	if key, bits := iPv4PrefixToUint32(p); bits >= 0 {
+		return t.root32.Match(key, bits)
+	}
+
+	if MSKey, MSBits, LSKey, LSBits := iPv6PrefixToUint64Pair(p); MSBits >= 0 {
+		return t.get64(MSKey, MSBits, LSKey, LSBits)
+	}
+
+	var v V
+	return v, false
+}
+
+// GetByAddr gets value for network which contains given address. IPv4-mapped IPv6 address is looked up as IPv4 one as GetByIP does. The method doesn't allocate.
+func (t *TreeOf[V]) GetByAddr(a netip.Addr) (V, bool) {
+	return t.GetByPrefix(newPrefixFromAddr(a))
+}
+
+// DeleteByPrefix removes subtree which is contained by given prefix. The method returns new tree (old one remains unaffected) and flag indicating if deletion happens indeed.
+func (t *TreeOf[V]) DeleteByPrefix(p netip.Prefix) (*TreeOf[V], bool) {
+	if t == nil {
+		return t, false
+	}
+
+	if key, bits := iPv4PrefixToUint32(p); bits >= 0 {
+		return t.delete32(key, bits)
+	}
+
+	if MSKey, MSBits, LSKey, LSBits := iPv6PrefixToUint64Pair(p); MSBits >= 0 {
+		return t.delete64(MSKey, MSBits, LSKey, LSBits)
+	}
+
+	return t, false
+}
+
+// DeleteByAddr removes node by given address. The method returns new tree (old one remains unaffected) and flag indicating if deletion happens indeed. IPv4-mapped IPv6 address is treated as IPv4 one.
+func (t *TreeOf[V]) DeleteByAddr(a netip.Addr) (*TreeOf[V], bool) {
+	return t.DeleteByPrefix(newPrefixFromAddr(a))
+}
+
+func (t *TreeOf[V]) walkPrefixes(f func(netip.Prefix, V) bool) bool {
+	for n := range t.root32.All() {
+		if !f(newPrefixFromUint32(n.Key, int(n.Bits)), n.Value) {
+			return false
+		}
+	}
+
+	for n := range t.root64.All() {
+		if s := n.Value.sub; s != nil {
+			for m := range s.All() {
+				if !f(newPrefixFromUint64Pair(n.Key, numtree.Key64BitSize, m.Key, int(m.Bits)), m.Value) {
+					return false
+				}
+			}
+		} else if !f(newPrefixFromUint64Pair(n.Key, int(n.Bits), 0, 0), n.Value.value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func iPv4PrefixToUint32(p netip.Prefix) (uint32, int) {
+	if !p.IsValid() || !p.Addr().Is4() {
+		return 0, -1
+	}
+
+	a := p.Masked().Addr().As4()
+	return binary.BigEndian.Uint32(a[:]), p.Bits()
+}
+
+func iPv6PrefixToUint64Pair(p netip.Prefix) (uint64, int, uint64, int) {
+	if !p.IsValid() || !p.Addr().Is6() {
+		return 0, -1, 0, -1
+	}
+
+	ones := p.Bits()
+	MSBits := numtree.Key64BitSize
+	LSBits := 0
+	if ones > numtree.Key64BitSize {
+		LSBits = ones - numtree.Key64BitSize
+	} else {
+		MSBits = ones
+	}
+
+	a := p.Masked().Addr().As16()
+	return binary.BigEndian.Uint64(a[:8]), MSBits, binary.BigEndian.Uint64(a[8:]), LSBits
+}
+
+// newPrefixFromAddr makes single address prefix. It unmaps IPv4-mapped IPv6 address to match net.IP based methods which use To4.
+func newPrefixFromAddr(a netip.Addr) netip.Prefix {
+	a = a.WithZone("").Unmap()
+	return netip.PrefixFrom(a, a.BitLen())
+}
+
+func newPrefixFromUint32(key uint32, bits int) netip.Prefix {
+	var a [4]byte
+	binary.BigEndian.PutUint32(a[:], key)
+	return netip.PrefixFrom(netip.AddrFrom4(a), bits).Masked()
+}
+
+func newPrefixFromUint64Pair(MSKey uint64, MSBits int, LSKey uint64, LSBits int) netip.Prefix {
+	var a [16]byte
+	binary.BigEndian.PutUint64(a[:8], MSKey)
+	binary.BigEndian.PutUint64(a[8:], LSKey)
+	return netip.PrefixFrom(netip.AddrFrom16(a), MSBits+LSBits).Masked()
+}
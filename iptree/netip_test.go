@@ -0,0 +1,190 @@
+package iptree
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestInsertPrefix(t *testing.T) {
+	var r *Tree
+
+	newR := r.InsertPrefix(netip.Prefix{}, "test")
+	if newR != r {
+		t.Errorf("Expected no changes inserting invalid prefix but got:\n%s\n", newR.root32.Dot())
+	}
+
+	r = r.InsertPrefix(netip.MustParsePrefix("192.0.2.1/24"), "test 1")
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::/32"), "test 2")
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::ff:0:0/96"), "test 3")
+	r = r.InsertAddr(netip.MustParseAddr("::ffff:192.0.2.1"), "test 4")
+	r.InplaceInsertPrefix(netip.MustParsePrefix("198.51.100.0/24"), "test 5")
+	r.InplaceInsertAddr(netip.MustParseAddr("2001:db8::1"), "test 6")
+
+	assertPrefixEnumerate(r, "192.0.2.0/24: \"test 1\", 192.0.2.1/32: \"test 4\", 198.51.100.0/24: \"test 5\", "+
+		"2001:db8::/32: \"test 2\", 2001:db8::1/128: \"test 6\", "+
+		"2001:db8::ff:0:0/96: \"test 3\"", "tree with prefixes", t)
+
+	items := []string{}
+	for p := range r.Enumerate() {
+		items = append(items, p.String())
+	}
+
+	s := strings.Join(items, ", ")
+	e := "192.0.2.0/24: \"test 1\", 192.0.2.1/32: \"test 4\", 198.51.100.0/24: \"test 5\", " +
+		"2001:db8::/32: \"test 2\", 2001:db8::1/128: \"test 6\", " +
+		"2001:db8::ff:0:0/96: \"test 3\""
+	if s != e {
+		t.Errorf("Expected following nodes %q but got %q", e, s)
+	}
+}
+
+func TestGetByPrefix(t *testing.T) {
+	var r *Tree
+
+	if v, ok := r.GetByPrefix(netip.MustParsePrefix("192.0.2.0/24")); ok {
+		t.Errorf("Expected no result in empty tree but got %T (%#v)", v, v)
+	}
+
+	r = r.InsertPrefix(netip.MustParsePrefix("192.0.2.0/24"), "test 1")
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::/32"), "test 2.1")
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8:1::/48"), "test 2.2")
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::ff:0:0/96"), "test 3")
+
+	if v, ok := r.GetByPrefix(netip.Prefix{}); ok {
+		t.Errorf("Expected no result for invalid prefix but got %T (%#v)", v, v)
+	}
+
+	for _, c := range []struct {
+		p string
+		e string
+	}{
+		{p: "192.0.2.0/24", e: "test 1"},
+		{p: "192.0.2.0/28", e: "test 1"},
+		{p: "2001:db8::/32", e: "test 2.1"},
+		{p: "2001:db8::ff:0:0/112", e: "test 3"},
+		{p: "2001:db8:1::/64", e: "test 2.2"},
+		{p: "2001:db8::fe:0:0/96", e: "test 2.1"},
+	} {
+		v, ok := r.GetByPrefix(netip.MustParsePrefix(c.p))
+		assertResult(v, ok, c.e, c.p, t)
+	}
+
+	for _, s := range []string{"198.51.100.0/24", "2001:db9::/32", "::ffff:192.0.2.0/120"} {
+		if v, ok := r.GetByPrefix(netip.MustParsePrefix(s)); ok {
+			t.Errorf("Expected no result for %s but got %T (%#v)", s, v, v)
+		}
+	}
+
+	v, ok := r.GetByAddr(netip.MustParseAddr("192.0.2.1"))
+	assertResult(v, ok, "test 1", "address 192.0.2.1", t)
+
+	v, ok = r.GetByAddr(netip.MustParseAddr("2001:db8::ff:0:1%eth0"))
+	assertResult(v, ok, "test 3", "address 2001:db8::ff:0:1%eth0", t)
+
+	if v, ok := r.GetByAddr(netip.Addr{}); ok {
+		t.Errorf("Expected no result for invalid address but got %T (%#v)", v, v)
+	}
+}
+
+func TestIPv4MappedAddr(t *testing.T) {
+	r := NewTree()
+	r = r.InsertPrefix(netip.MustParsePrefix("192.0.2.0/24"), "IPv4")
+	r = r.InsertPrefix(netip.MustParsePrefix("::ffff:192.0.2.0/120"), "IPv4-mapped IPv6")
+
+	a := netip.MustParseAddr("::ffff:192.0.2.1")
+	v, ok := r.GetByAddr(a)
+	assertResult(v, ok, "IPv4", "address "+a.String(), t)
+
+	v, ok = r.GetByIP(net.ParseIP(a.String()))
+	assertResult(v, ok, "IPv4", "IP "+a.String(), t)
+
+	// Prefixes aren't unmapped as networks with 16 bytes IP aren't by net.IPNet based methods.
+	v, ok = r.GetByPrefix(netip.PrefixFrom(a, 128))
+	assertResult(v, ok, "IPv4-mapped IPv6", "prefix "+a.String()+"/128", t)
+
+	_, n, _ := net.ParseCIDR("::ffff:192.0.2.1/128")
+	v, ok = r.GetByNet(n)
+	assertResult(v, ok, "IPv4-mapped IPv6", "network "+n.String(), t)
+
+	r = r.InsertAddr(a, "address")
+	v, ok = r.GetByIP(net.ParseIP("192.0.2.1"))
+	assertResult(v, ok, "address", "IP 192.0.2.1", t)
+
+	r, ok = r.DeleteByAddr(a)
+	if !ok {
+		t.Errorf("Expected deletion by %s but got nothing", a)
+	}
+
+	assertPrefixEnumerate(r, "192.0.2.0/24: \"IPv4\", ::ffff:192.0.2.0/120: \"IPv4-mapped IPv6\"",
+		"tree after deletion by IPv4-mapped address", t)
+}
+
+func TestGetByAddrAllocs(t *testing.T) {
+	r := NewTree()
+	r = r.InsertPrefix(netip.MustParsePrefix("192.0.2.0/24"), "test 1")
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::/32"), "test 2")
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::ff:0:0/96"), "test 3")
+
+	for _, s := range []string{"192.0.2.1", "2001:db8::1", "2001:db8::ff:0:1"} {
+		a := netip.MustParseAddr(s)
+		p := netip.PrefixFrom(a, a.BitLen())
+		if n := testing.AllocsPerRun(100, func() { r.GetByAddr(a) }); n > 0 {
+			t.Errorf("Expected no allocations for GetByAddr(%s) but got %g", a, n)
+		}
+
+		if n := testing.AllocsPerRun(100, func() { r.GetByPrefix(p) }); n > 0 {
+			t.Errorf("Expected no allocations for GetByPrefix(%s) but got %g", p, n)
+		}
+	}
+}
+
+func TestDeleteByPrefix(t *testing.T) {
+	var r *Tree
+
+	r, ok := r.DeleteByPrefix(netip.MustParsePrefix("192.0.2.0/24"))
+	if ok {
+		t.Error("Expected no deletion in empty tree but got one")
+	}
+
+	r = r.InsertPrefix(netip.MustParsePrefix("192.0.2.0/24"), "test 1")
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::/32"), "test 2")
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::ff:0:0/96"), "test 3")
+	r = r.InsertAddr(netip.MustParseAddr("2001:db8::1"), "test 4")
+
+	if _, ok := r.DeleteByPrefix(netip.Prefix{}); ok {
+		t.Error("Expected no deletion by invalid prefix but got one")
+	}
+
+	r, ok = r.DeleteByPrefix(netip.MustParsePrefix("2001:db8::ff:0:0/96"))
+	if !ok {
+		t.Error("Expected deletion by 2001:db8::ff:0:0/96 but got nothing")
+	}
+
+	r, ok = r.DeleteByAddr(netip.MustParseAddr("2001:db8::1"))
+	if !ok {
+		t.Error("Expected deletion by 2001:db8::1 but got nothing")
+	}
+
+	r, ok = r.DeleteByPrefix(netip.MustParsePrefix("192.0.2.0/24"))
+	if !ok {
+		t.Error("Expected deletion by 192.0.2.0/24 but got nothing")
+	}
+
+	assertPrefixEnumerate(r, "2001:db8::/32: \"test 2\"", "tree after deletions", t)
+}
+
+func assertPrefixEnumerate(r *Tree, e, desc string, t *testing.T) {
+	t.Helper()
+
+	items := []string{}
+	for p := range r.EnumeratePrefixes() {
+		items = append(items, fmt.Sprintf("%s: %q", p.Key, p.Value))
+	}
+
+	if s := strings.Join(items, ", "); s != e {
+		t.Errorf("Expected following nodes for %s:\n\t%q\nbut got:\n\t%q", desc, e, s)
+	}
+}
@@ -0,0 +1,129 @@
+package iptree
+
+import (
+	"math/bits"
+	"net"
+
+	"github.com/infobloxopen/go-trees/numtree"
+)
+
+// InsertRange puts value for all addresses from start to end inclusive. The range is split to the minimal set of networks. Both addresses should be of the same family and start shouldn't be greater than end otherwise the tree remains unchanged. The method returns new tree (old one remains unaffected).
+func (t *TreeOf[V]) InsertRange(start, end net.IP, value V) *TreeOf[V] {
+	splitRange(start, end, func(key uint32, bits int) {
+		t = t.insert32(key, bits, value)
+	}, func(MSKey uint64, MSBits int, LSKey uint64, LSBits int) {
+		t = t.insert64(MSKey, MSBits, LSKey, LSBits, value)
+	})
+
+	return t
+}
+
+// InplaceInsertRange puts value for all addresses from start to end inclusive in the same way as InsertRange. The method inserts data directly to current tree so make sure you have exclusive access to it.
+func (t *TreeOf[V]) InplaceInsertRange(start, end net.IP, value V) {
+	splitRange(start, end, func(key uint32, bits int) {
+		t.root32 = t.root32.InplaceInsert(key, bits, value)
+	}, func(MSKey uint64, MSBits int, LSKey uint64, LSBits int) {
+		t.inplaceInsert64(MSKey, MSBits, LSKey, LSBits, value)
+	})
+}
+
+// DeleteRange removes all networks contained by range from start to end inclusive. Networks which only overlap with the range remain in the tree. It returns new tree and flag if deletion indeed occurs.
+func (t *TreeOf[V]) DeleteRange(start, end net.IP) (*TreeOf[V], bool) {
+	if t == nil {
+		return t, false
+	}
+
+	deleted := false
+	splitRange(start, end, func(key uint32, bits int) {
+		var ok bool
+		if t, ok = t.delete32(key, bits); ok {
+			deleted = true
+		}
+	}, func(MSKey uint64, MSBits int, LSKey uint64, LSBits int) {
+		var ok bool
+		if t, ok = t.delete64(MSKey, MSBits, LSKey, LSBits); ok {
+			deleted = true
+		}
+	})
+
+	return t, deleted
+}
+
+// splitRange calls f32 or f64 for each network of the minimal set covering given range.
+func splitRange(start, end net.IP, f32 func(key uint32, bits int), f64 func(MSKey uint64, MSBits int, LSKey uint64, LSBits int)) {
+	if s, e := start.To4(), end.To4(); s != nil && e != nil {
+		splitRange32(packIPToUint32(s), packIPToUint32(e), f32)
+		return
+	}
+
+	s, e := start.To16(), end.To16()
+	if s == nil || e == nil || start.To4() != nil || end.To4() != nil {
+		return
+	}
+
+	splitRange128(packIPToUint64(s), packIPToUint64(s[8:]), packIPToUint64(e), packIPToUint64(e[8:]),
+		func(hi, lo uint64, n int) {
+			if n > numtree.Key64BitSize {
+				f64(hi, numtree.Key64BitSize, lo, n-numtree.Key64BitSize)
+			} else {
+				f64(hi, n, 0, 0)
+			}
+		})
+}
+
+func splitRange32(start, end uint32, f func(key uint32, bits int)) {
+	for start <= end {
+		// Find the largest network aligned to start which doesn't go beyond end.
+		size := bits.TrailingZeros32(start)
+		for size > 0 && end-start < uint32(1)<<size-1 {
+			size--
+		}
+
+		f(start, iPv4Bits-size)
+
+		next := start + uint32(1)<<size - 1
+		if next >= end {
+			return
+		}
+
+		start = next + 1
+	}
+}
+
+func splitRange128(sHi, sLo, eHi, eLo uint64, f func(hi, lo uint64, bits int)) {
+	for sHi < eHi || sHi == eHi && sLo <= eLo {
+		// Find the largest network aligned to start which doesn't go beyond end.
+		size := bits.TrailingZeros64(sLo)
+		if sLo == 0 {
+			size += bits.TrailingZeros64(sHi)
+		}
+
+		var nHi, nLo uint64
+		for {
+			var c uint64
+			if size < numtree.Key64BitSize {
+				nLo, c = bits.Add64(sLo, uint64(1)<<size-1, 0)
+				nHi = sHi + c
+			} else {
+				nLo = ^uint64(0)
+				nHi = sHi + (uint64(1)<<(size-numtree.Key64BitSize) - 1)
+			}
+
+			if nHi < eHi || nHi == eHi && nLo <= eLo {
+				break
+			}
+
+			size--
+		}
+
+		f(sHi, sLo, iPv6Bits-size)
+
+		if nHi == eHi && nLo == eLo {
+			return
+		}
+
+		var c uint64
+		sLo, c = bits.Add64(nLo, 1, 0)
+		sHi = nHi + c
+	}
+}
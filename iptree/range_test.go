@@ -0,0 +1,80 @@
+package iptree
+
+import (
+	"net"
+	"testing"
+)
+
+func TestInsertRange(t *testing.T) {
+	var r *Tree
+
+	r = r.InsertRange(net.ParseIP("192.168.1.10"), net.ParseIP("192.168.3.77"), "a")
+	assertTreeEnumerate(r.Enumerate(), "192.168.1.10/31: \"a\", 192.168.1.12/30: \"a\", 192.168.1.16/28: \"a\", "+
+		"192.168.1.32/27: \"a\", 192.168.1.64/26: \"a\", 192.168.1.128/25: \"a\", 192.168.2.0/24: \"a\", "+
+		"192.168.3.0/26: \"a\", 192.168.3.64/29: \"a\", 192.168.3.72/30: \"a\", 192.168.3.76/31: \"a\"",
+		"tree with IPv4 range", t)
+
+	u := r.InsertRange(net.ParseIP("10.0.0.0"), net.ParseIP("192.168.3.77"), "b")
+	u = u.InsertRange(net.ParseIP("2001:db8::ffff"), net.ParseIP("2001:db8::1:0:0:0"), "c")
+	u = u.InsertRange(net.ParseIP("2001:db9::"), net.ParseIP("2001:db9:ffff:ffff:ffff:ffff:ffff:ffff"), "d")
+	u = u.InsertRange(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.0"), "e")
+	u = u.InsertRange(net.ParseIP("10.0.0.1"), net.ParseIP("2001:db8::"), "e")
+	u = u.InsertRange(nil, net.ParseIP("2001:db8::"), "e")
+
+	v, ok := u.GetByIP(net.ParseIP("192.168.1.11"))
+	assertResult(v, ok, "a", "range for 192.168.1.11", t)
+
+	v, ok = u.GetByIP(net.ParseIP("172.16.0.1"))
+	assertResult(v, ok, "b", "range for 172.16.0.1", t)
+
+	v, ok = u.GetByIP(net.ParseIP("10.0.0.1"))
+	assertResult(v, ok, "b", "invalid ranges for 10.0.0.1", t)
+
+	assertTreeEnumerate(u.EnumerateSubnets(parseTestNet("2001:db8::/32")), "2001:db8::ffff/128: \"c\", "+
+		"2001:db8::1:0/112: \"c\", 2001:db8::2:0/111: \"c\", 2001:db8::4:0/110: \"c\", 2001:db8::8:0/109: \"c\", "+
+		"2001:db8::10:0/108: \"c\", 2001:db8::20:0/107: \"c\", 2001:db8::40:0/106: \"c\", 2001:db8::80:0/105: \"c\", "+
+		"2001:db8::100:0/104: \"c\", 2001:db8::200:0/103: \"c\", 2001:db8::400:0/102: \"c\", 2001:db8::800:0/101: \"c\", "+
+		"2001:db8::1000:0/100: \"c\", 2001:db8::2000:0/99: \"c\", 2001:db8::4000:0/98: \"c\", "+
+		"2001:db8::8000:0/97: \"c\", 2001:db8::1:0:0/96: \"c\", 2001:db8::2:0:0/95: \"c\", 2001:db8::4:0:0/94: \"c\", "+
+		"2001:db8::8:0:0/93: \"c\", 2001:db8::10:0:0/92: \"c\", 2001:db8::20:0:0/91: \"c\", 2001:db8::40:0:0/90: \"c\", "+
+		"2001:db8::80:0:0/89: \"c\", 2001:db8::100:0:0/88: \"c\", 2001:db8::200:0:0/87: \"c\", "+
+		"2001:db8::400:0:0/86: \"c\", 2001:db8::800:0:0/85: \"c\", 2001:db8::1000:0:0/84: \"c\", "+
+		"2001:db8::2000:0:0/83: \"c\", 2001:db8::4000:0:0/82: \"c\", 2001:db8::8000:0:0/81: \"c\", "+
+		"2001:db8:0:0:1::/128: \"c\"", "IPv6 range", t)
+
+	assertTreeEnumerate(u.EnumerateSubnets(parseTestNet("2001:db9::/32")), "2001:db9::/32: \"d\"", "IPv6 network range", t)
+
+	r = NewTree()
+	r.InplaceInsertRange(net.ParseIP("0.0.0.0"), net.ParseIP("255.255.255.255"), "f")
+	r.InplaceInsertRange(net.ParseIP("::"), net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"), "g")
+	r.InplaceInsertRange(net.ParseIP("2001:db8::"), net.ParseIP("2001:db8::ffff:ffff:ffff:ffff"), "h")
+	assertTreeEnumerate(r.Enumerate(), "0.0.0.0/0: \"f\", ::/0: \"g\", 2001:db8::/64: \"h\"", "inplace ranges", t)
+}
+
+func TestDeleteRange(t *testing.T) {
+	var r *Tree
+	if _, ok := r.DeleteRange(net.ParseIP("10.0.0.0"), net.ParseIP("10.0.0.255")); ok {
+		t.Error("Expected no deletion from empty tree but got one")
+	}
+
+	r = r.InsertRange(net.ParseIP("192.168.1.10"), net.ParseIP("192.168.3.77"), "a")
+	r = r.InsertNet(parseTestNet("192.168.0.0/16"), "b")
+	r = r.InsertRange(net.ParseIP("2001:db8::"), net.ParseIP("2001:db8:0:1::ffff"), "c")
+
+	u, ok := r.DeleteRange(net.ParseIP("192.168.1.0"), net.ParseIP("192.168.2.255"))
+	if !ok {
+		t.Error("Expected deletion of IPv4 range but got nothing")
+	}
+
+	u, ok = u.DeleteRange(net.ParseIP("2001:db8::"), net.ParseIP("2001:db8::ffff:ffff:ffff:ffff"))
+	if !ok {
+		t.Error("Expected deletion of IPv6 range but got nothing")
+	}
+
+	if _, ok := u.DeleteRange(net.ParseIP("10.0.0.0"), net.ParseIP("10.0.0.255")); ok {
+		t.Error("Expected no deletion of missing range but got one")
+	}
+
+	assertTreeEnumerate(u.Enumerate(), "192.168.0.0/16: \"b\", 192.168.3.0/26: \"a\", 192.168.3.64/29: \"a\", "+
+		"192.168.3.72/30: \"a\", 192.168.3.76/31: \"a\", 2001:db8:0:1::/112: \"c\"", "tree after range deletion", t)
+}
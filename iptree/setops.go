@@ -0,0 +1,77 @@
+package iptree
+
+import "net"
+
+// Intersect returns new tree which covers addresses covered by both given trees. Networks of the first tree which are partially covered by the second one are split to the minimal set of CIDRs. Values are taken from the first tree.
+func Intersect[V any](a, b *TreeOf[V]) *TreeOf[V] {
+	r := NewTreeOf[V]()
+	if a == nil || b == nil {
+		return r
+	}
+
+	a.walk(func(n *net.IPNet, v V) bool {
+		b.splitNet(n, true, func(n *net.IPNet) {
+			r.InplaceInsertNet(n, v)
+		})
+
+		return true
+	})
+
+	return r
+}
+
+// Subtract returns new tree which covers addresses covered by the first tree but not by the second one. Networks of the first tree which are partially covered by the second one are split to the minimal set of CIDRs covering the rest. For example 10.0.0.0/16 minus 10.0.1.0/24 gives 10.0.0.0/24, 10.0.2.0/23, 10.0.4.0/22 and so on up to 10.0.128.0/17. Values are taken from the first tree. The result never shares nodes with the first tree even if the second one is empty.
+func Subtract[V any](a, b *TreeOf[V]) *TreeOf[V] {
+	r := NewTreeOf[V]()
+	if a == nil {
+		return r
+	}
+
+	if b == nil {
+		b = NewTreeOf[V]()
+	}
+
+	a.walk(func(n *net.IPNet, v V) bool {
+		b.splitNet(n, false, func(n *net.IPNet) {
+			r.InplaceInsertNet(n, v)
+		})
+
+		return true
+	})
+
+	return r
+}
+
+// splitNet calls f for minimal set of CIDRs which cover part of given network covered (or not covered if covered argument is false) by the tree.
+func (t *TreeOf[V]) splitNet(n *net.IPNet, covered bool, f func(n *net.IPNet)) {
+	if _, ok := t.GetByNet(n); ok {
+		if covered {
+			f(n)
+		}
+
+		return
+	}
+
+	inside := false
+	t.walkSubnets(n, func(*net.IPNet, V) bool {
+		inside = true
+		return false
+	})
+
+	if !inside {
+		if !covered {
+			f(n)
+		}
+
+		return
+	}
+
+	ones, bits := n.Mask.Size()
+	mask := net.CIDRMask(ones+1, bits)
+
+	hi := &net.IPNet{IP: append(net.IP{}, n.IP...), Mask: mask}
+	hi.IP[ones/8] |= 0x80 >> (ones % 8)
+
+	t.splitNet(&net.IPNet{IP: n.IP, Mask: mask}, covered, f)
+	t.splitNet(hi, covered, f)
+}
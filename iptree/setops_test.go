@@ -0,0 +1,58 @@
+package iptree
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIntersect(t *testing.T) {
+	a := newTestTree("10.0.0.0/16", "10.0.1.0/24", "192.0.2.0/24", "2001:db8::/32", "2001:db8::/64")
+	b := newTestTree("10.0.1.128/25", "10.0.2.0/23", "10.0.0.0/8", "192.0.2.0/25", "2001:db8::ff:0:0/96")
+
+	assertTreeEnumerate(Intersect(a, nil).Enumerate(), "", "intersection with empty tree", t)
+	assertTreeEnumerate(Intersect(a, b).Enumerate(), "10.0.0.0/16: \"10.0.0.0/16\", 10.0.1.0/24: \"10.0.1.0/24\", "+
+		"192.0.2.0/25: \"192.0.2.0/24\", 2001:db8::ff:0:0/96: \"2001:db8::/64\"", "intersection", t)
+
+	b = newTestTree("10.0.1.128/25", "10.0.2.0/23", "192.0.2.0/25", "2001:db8::ff:0:0/96")
+	assertTreeEnumerate(Intersect(a, b).Enumerate(), "10.0.1.128/25: \"10.0.1.0/24\", 10.0.2.0/23: \"10.0.0.0/16\", "+
+		"192.0.2.0/25: \"192.0.2.0/24\", 2001:db8::ff:0:0/96: \"2001:db8::/64\"", "intersection with smaller networks", t)
+}
+
+func TestSubtract(t *testing.T) {
+	a := newTestTree("10.0.0.0/16")
+	b := newTestTree("10.0.1.0/24")
+
+	assertTreeEnumerate(Subtract(nil, b).Enumerate(), "", "subtraction from empty tree", t)
+
+	r := Subtract(a, nil)
+	assertTreeEnumerate(r.Enumerate(), "10.0.0.0/16: \"10.0.0.0/16\"", "subtraction of empty tree", t)
+
+	_, n, _ := net.ParseCIDR("10.0.0.0/24")
+	r.InplaceInsertNet(n, "test")
+	assertTreeEnumerate(a.Enumerate(), "10.0.0.0/16: \"10.0.0.0/16\"", "first tree after insertion to subtraction result", t)
+
+	r = Subtract(a, NewTree())
+	r.InplaceInsertNet(n, "test")
+	assertTreeEnumerate(a.Enumerate(), "10.0.0.0/16: \"10.0.0.0/16\"", "first tree after insertion to subtraction of empty tree", t)
+	assertTreeEnumerate(Subtract(a, b).Enumerate(), "10.0.0.0/24: \"10.0.0.0/16\", 10.0.2.0/23: \"10.0.0.0/16\", "+
+		"10.0.4.0/22: \"10.0.0.0/16\", 10.0.8.0/21: \"10.0.0.0/16\", 10.0.16.0/20: \"10.0.0.0/16\", "+
+		"10.0.32.0/19: \"10.0.0.0/16\", 10.0.64.0/18: \"10.0.0.0/16\", 10.0.128.0/17: \"10.0.0.0/16\"", "subtraction", t)
+
+	a = newTestTree("10.0.0.0/23", "10.0.1.0/24", "192.0.2.0/24", "2001:db8::/120", "2001:db8::/126")
+	b = newTestTree("10.0.0.128/25", "10.0.1.128/25", "2001:db8::80/121", "2001:db8::/127")
+
+	assertTreeEnumerate(Subtract(a, b).Enumerate(), "10.0.0.0/25: \"10.0.0.0/23\", 10.0.1.0/25: \"10.0.1.0/24\", "+
+		"192.0.2.0/24: \"192.0.2.0/24\", 2001:db8::2/127: \"2001:db8::/126\", 2001:db8::4/126: \"2001:db8::/120\", "+
+		"2001:db8::8/125: \"2001:db8::/120\", 2001:db8::10/124: \"2001:db8::/120\", 2001:db8::20/123: \"2001:db8::/120\", "+
+		"2001:db8::40/122: \"2001:db8::/120\"", "subtraction with nested networks", t)
+}
+
+func newTestTree(s ...string) *Tree {
+	var r *Tree
+	for _, s := range s {
+		_, n, _ := net.ParseCIDR(s)
+		r = r.InsertNet(n, s)
+	}
+
+	return r
+}
@@ -0,0 +1,234 @@
+package numtree
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	// ErrInvalidNode is returned when binary data can't be decoded to a tree node.
+	ErrInvalidNode = errors.New("invalid node")
+	// ErrUnexpectedEnd is returned when binary data ends before a tree node is completely decoded.
+	ErrUnexpectedEnd = errors.New("unexpected end of data")
+)
+
+// ValueEncoderOf appends binary representation of node value of type V to given buffer.
+type ValueEncoderOf[V any] func(b []byte, v V) ([]byte, error)
+
+// ValueDecoderOf decodes node value of type V from the beginning of given buffer. It returns the value and number of bytes consumed.
+type ValueDecoderOf[V any] func(b []byte) (V, int, error)
+
+// ValueEncoder appends binary representation of interface{} node value to given buffer.
+type ValueEncoder = ValueEncoderOf[interface{}]
+
+// ValueDecoder decodes interface{} node value from the beginning of given buffer. It returns the value and number of bytes consumed.
+type ValueDecoder = ValueDecoderOf[interface{}]
+
+const (
+	nodeFlagPresent = 1 << iota
+	nodeFlagLeaf
+)
+
+// AppendBinary appends binary representation of the tree to given buffer. Nodes are written in depth-first order as flags byte, big-endian key, significant bits, value (for leaf nodes only) followed by both children. Absent node is a single zero byte.
+func (n *Node32Of[V]) AppendBinary(b []byte, enc ValueEncoderOf[V]) ([]byte, error) {
+	if n == nil {
+		return append(b, 0), nil
+	}
+
+	flags := byte(nodeFlagPresent)
+	if n.Leaf {
+		flags |= nodeFlagLeaf
+	}
+
+	b = binary.BigEndian.AppendUint32(append(b, flags), n.Key)
+	b = append(b, n.Bits)
+
+	if n.Leaf {
+		var err error
+		b, err = enc(b, n.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range n.chld {
+		var err error
+		b, err = c.AppendBinary(b, enc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// DecodeNode32 decodes tree written by AppendBinary from the beginning of given buffer. It returns root node and number of bytes consumed.
+func DecodeNode32(b []byte, dec ValueDecoder) (*Node32, int, error) {
+	return DecodeNode32Of(b, dec)
+}
+
+// DecodeNode32Of decodes tree with values of type V written by AppendBinary from the beginning of given buffer. It returns root node and number of bytes consumed.
+func DecodeNode32Of[V any](b []byte, dec ValueDecoderOf[V]) (*Node32Of[V], int, error) {
+	return decodeNode32(b, dec, nil, 0)
+}
+
+func decodeNode32[V any](b []byte, dec ValueDecoderOf[V], p *Node32Of[V], branch uint32) (*Node32Of[V], int, error) {
+	if len(b) < 1 {
+		return nil, 0, ErrUnexpectedEnd
+	}
+
+	flags := b[0]
+	if flags == 0 {
+		return nil, 1, nil
+	}
+
+	if flags&^(nodeFlagPresent|nodeFlagLeaf) != 0 || flags&nodeFlagPresent == 0 {
+		return nil, 0, ErrInvalidNode
+	}
+
+	if len(b) < 6 {
+		return nil, 0, ErrUnexpectedEnd
+	}
+
+	n := &Node32Of[V]{
+		Key:  binary.BigEndian.Uint32(b[1:]),
+		Bits: b[5],
+		Leaf: flags&nodeFlagLeaf != 0,
+	}
+
+	if n.Bits > Key32BitSize {
+		return nil, 0, ErrInvalidNode
+	}
+
+	if p != nil {
+		if n.Bits <= p.Bits || (n.Key^p.Key)&masks32[p.Bits] != 0 || (n.Key>>(Key32BitSize-1-p.Bits))&1 != branch {
+			return nil, 0, ErrInvalidNode
+		}
+	}
+
+	off := 6
+	if n.Leaf {
+		v, m, err := dec(b[off:])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		n.Value = v
+		off += m
+	}
+
+	for i := range n.chld {
+		c, m, err := decodeNode32(b[off:], dec, n, uint32(i))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		n.chld[i] = c
+		off += m
+	}
+
+	n.updateCount()
+	return n, off, nil
+}
+
+// AppendBinary appends binary representation of the tree to given buffer. The format is the same as for Node32 but with 8 bytes keys.
+func (n *Node64Of[V]) AppendBinary(b []byte, enc ValueEncoderOf[V]) ([]byte, error) {
+	if n == nil {
+		return append(b, 0), nil
+	}
+
+	flags := byte(nodeFlagPresent)
+	if n.Leaf {
+		flags |= nodeFlagLeaf
+	}
+
+	b = binary.BigEndian.AppendUint64(append(b, flags), n.Key)
+	b = append(b, n.Bits)
+
+	if n.Leaf {
+		var err error
+		b, err = enc(b, n.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range n.chld {
+		var err error
+		b, err = c.AppendBinary(b, enc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// DecodeNode64 decodes tree written by AppendBinary from the beginning of given buffer. It returns root node and number of bytes consumed.
+func DecodeNode64(b []byte, dec ValueDecoder) (*Node64, int, error) {
+	return DecodeNode64Of(b, dec)
+}
+
+// DecodeNode64Of decodes tree with values of type V written by AppendBinary from the beginning of given buffer. It returns root node and number of bytes consumed.
+func DecodeNode64Of[V any](b []byte, dec ValueDecoderOf[V]) (*Node64Of[V], int, error) {
+	return decodeNode64(b, dec, nil, 0)
+}
+
+func decodeNode64[V any](b []byte, dec ValueDecoderOf[V], p *Node64Of[V], branch uint64) (*Node64Of[V], int, error) {
+	if len(b) < 1 {
+		return nil, 0, ErrUnexpectedEnd
+	}
+
+	flags := b[0]
+	if flags == 0 {
+		return nil, 1, nil
+	}
+
+	if flags&^(nodeFlagPresent|nodeFlagLeaf) != 0 || flags&nodeFlagPresent == 0 {
+		return nil, 0, ErrInvalidNode
+	}
+
+	if len(b) < 10 {
+		return nil, 0, ErrUnexpectedEnd
+	}
+
+	n := &Node64Of[V]{
+		Key:  binary.BigEndian.Uint64(b[1:]),
+		Bits: b[9],
+		Leaf: flags&nodeFlagLeaf != 0,
+	}
+
+	if n.Bits > Key64BitSize {
+		return nil, 0, ErrInvalidNode
+	}
+
+	if p != nil {
+		if n.Bits <= p.Bits || (n.Key^p.Key)&masks64[p.Bits] != 0 || (n.Key>>(Key64BitSize-1-p.Bits))&1 != branch {
+			return nil, 0, ErrInvalidNode
+		}
+	}
+
+	off := 10
+	if n.Leaf {
+		v, m, err := dec(b[off:])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		n.Value = v
+		off += m
+	}
+
+	for i := range n.chld {
+		c, m, err := decodeNode64(b[off:], dec, n, uint64(i))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		n.chld[i] = c
+		off += m
+	}
+
+	n.updateCount()
+	return n, off, nil
+}
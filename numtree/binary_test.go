@@ -0,0 +1,115 @@
+package numtree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func appendTestValue(b []byte, v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string but got %T", v)
+	}
+
+	return append(append(b, byte(len(s))), s...), nil
+}
+
+func decodeTestValue(b []byte) (interface{}, int, error) {
+	if len(b) < 1 || len(b) < int(b[0])+1 {
+		return nil, 0, ErrUnexpectedEnd
+	}
+
+	return string(b[1 : b[0]+1]), int(b[0]) + 1, nil
+}
+
+func TestBinary32(t *testing.T) {
+	var r *Node32
+
+	b, err := r.AppendBinary(nil, appendTestValue)
+	if err != nil || len(b) != 1 || b[0] != 0 {
+		t.Errorf("Expected single zero byte for empty tree but got %x (%v)", b, err)
+	}
+
+	r = r.Insert(0xAAAAAAAA, 7, "L1")
+	r = r.Insert(0xA8AAAAAA, 9, "L2.1")
+	r = r.Insert(0xABAAAAAA, 9, "L2.2")
+	r = r.Insert(0xAAAAAAAA, 18, "L3")
+	r = r.Insert(0xAABAAAAA, 19, "L4")
+
+	b, err = r.AppendBinary([]byte{0xff}, appendTestValue)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	u, n, err := DecodeNode32(b[1:], decodeTestValue)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if n != len(b)-1 {
+		t.Errorf("Expected %d bytes consumed but got %d", len(b)-1, n)
+	}
+
+	if e, s := r.Dot(), u.Dot(); s != e {
+		t.Errorf("Expected decoded tree:\n%s\nbut got:\n%s", e, s)
+	}
+
+	if _, _, err := DecodeNode32(b[1:len(b)-1], decodeTestValue); !errors.Is(err, ErrUnexpectedEnd) {
+		t.Errorf("Expected %q error for truncated data but got %v", ErrUnexpectedEnd, err)
+	}
+
+	b = []byte{nodeFlagPresent, 0xaa, 0xaa, 0xaa, 0xaa, 8, nodeFlagPresent | nodeFlagLeaf, 0xaa, 0xaa, 0xaa, 0xaa, 4}
+	if _, _, err := DecodeNode32(b, decodeTestValue); !errors.Is(err, ErrInvalidNode) {
+		t.Errorf("Expected %q error for child with less bits than parent but got %v", ErrInvalidNode, err)
+	}
+
+	if _, err := r.AppendBinary(nil, func(b []byte, v interface{}) ([]byte, error) {
+		return nil, errors.New("test")
+	}); err == nil {
+		t.Error("Expected value encoder error but got nothing")
+	}
+}
+
+func TestBinary64(t *testing.T) {
+	var r *Node64
+
+	r = r.Insert(0xAAAAAAAA00000000, 7, "L1")
+	r = r.Insert(0xA8AAAAAA00000000, 9, "L2.1")
+	r = r.Insert(0xABAAAAAA00000000, 9, "L2.2")
+	r = r.Insert(0xAAAAAAAA00000000, 18, "L3")
+	r = r.Insert(0xAABAAAAA00000000, 19, "L4")
+
+	b, err := r.AppendBinary(nil, appendTestValue)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	u, n, err := DecodeNode64(b, decodeTestValue)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if n != len(b) {
+		t.Errorf("Expected %d bytes consumed but got %d", len(b), n)
+	}
+
+	if e, s := r.Dot(), u.Dot(); s != e {
+		t.Errorf("Expected decoded tree:\n%s\nbut got:\n%s", e, s)
+	}
+
+	b = binary.BigEndian.AppendUint64([]byte{nodeFlagPresent | nodeFlagLeaf}, 0)
+	b = append(b, 65)
+	if _, _, err := DecodeNode64(b, decodeTestValue); !errors.Is(err, ErrInvalidNode) {
+		t.Errorf("Expected %q error for too many significant bits but got %v", ErrInvalidNode, err)
+	}
+
+	b = binary.BigEndian.AppendUint64([]byte{nodeFlagPresent}, 0)
+	b = append(b, 0)
+	b = binary.BigEndian.AppendUint64(append(b, nodeFlagPresent|nodeFlagLeaf), 0x8000000000000000)
+	b = append(b, 1, 1, 'x', 0, 0, 0)
+	if _, _, err := DecodeNode64(b, decodeTestValue); !errors.Is(err, ErrInvalidNode) {
+		t.Errorf("Expected %q error for child on wrong branch but got %v", ErrInvalidNode, err)
+	}
+}
@@ -3,6 +3,7 @@ package numtree
 
 import (
 	"fmt"
+	"iter"
 	"math/bits"
 )
 
@@ -22,8 +23,8 @@ var (
 		0xffffffff}
 )
 
-// Node32 is an element of radix tree with 32-bit unsigned integer as a key.
-type Node32 struct {
+// Node32Of is an element of radix tree with 32-bit unsigned integer as a key and values of type V.
+type Node32Of[V any] struct {
 	// Key stores key for current node.
 	Key uint32
 	// Bits is a number of significant bits in Key.
@@ -31,18 +32,22 @@ type Node32 struct {
 	// Leaf indicates if the node is leaf node and contains any data in Value.
 	Leaf bool
 	// Value contains data associated with key.
-	Value interface{}
+	Value V
 
-	chld [2]*Node32
+	chld  [2]*Node32Of[V]
+	count int
 }
 
+// Node32 is an element of radix tree with 32-bit unsigned integer as a key and interface{} values.
+type Node32 = Node32Of[interface{}]
+
 // Dot dumps tree to Graphviz .dot format
-func (n *Node32) Dot() string {
+func (n *Node32Of[V]) Dot() string {
 	body := ""
 
 	// Iterate all nodes using breadth-first search algorithm.
 	i := 0
-	queue := []*Node32{n}
+	queue := []*Node32Of[V]{n}
 	for len(queue) > 0 {
 		c := queue[0]
 		body += fmt.Sprintf("N%d %s\n", i, c.dotString())
@@ -61,7 +66,7 @@ func (n *Node32) Dot() string {
 }
 
 // Insert puts new leaf to radix tree and returns pointer to new root. The method uses copy on write strategy so old root doesn't see the change.
-func (n *Node32) Insert(key uint32, bits int, value interface{}) *Node32 {
+func (n *Node32Of[V]) Insert(key uint32, bits int, value V) *Node32Of[V] {
 	// Adjust bits.
 	if bits < 0 {
 		bits = 0
@@ -73,7 +78,7 @@ func (n *Node32) Insert(key uint32, bits int, value interface{}) *Node32 {
 }
 
 // InplaceInsert puts new leaf to radix tree (or replaces value in existing one). The method inserts data directly to current tree so make sure you have exclusive access to it.
-func (n *Node32) InplaceInsert(key uint32, bits int, value interface{}) *Node32 {
+func (n *Node32Of[V]) InplaceInsert(key uint32, bits int, value V) *Node32Of[V] {
 	// Adjust bits.
 	if bits < 0 {
 		bits = 0
@@ -85,30 +90,64 @@ func (n *Node32) InplaceInsert(key uint32, bits int, value interface{}) *Node32
 }
 
 // Enumerate returns channel which is populated by nodes with data in order of their keys.
-func (n *Node32) Enumerate() chan *Node32 {
-	ch := make(chan *Node32)
+func (n *Node32Of[V]) Enumerate() chan *Node32Of[V] {
+	ch := make(chan *Node32Of[V])
 
 	go func() {
 		defer close(ch)
 
-		// If tree is empty -
-		if n == nil {
-			// return nothing.
-			return
+		for c := range n.All() {
+			ch <- c
 		}
-
-		n.enumerate(ch)
 	}()
 
 	return ch
 }
 
+// All returns iterator over nodes with data in order of their keys.
+func (n *Node32Of[V]) All() iter.Seq[*Node32Of[V]] {
+	return func(yield func(*Node32Of[V]) bool) {
+		n.Walk(yield)
+	}
+}
+
+// Walk calls f for nodes with data in order of their keys until f returns false. It reports if all the nodes have been visited.
+func (n *Node32Of[V]) Walk(f func(*Node32Of[V]) bool) bool {
+	// If tree is empty -
+	if n == nil {
+		// there is nothing to visit.
+		return true
+	}
+
+	return n.walk(f)
+}
+
+// Len returns number of leaves in the tree. It takes constant time as the number is kept in every node.
+func (n *Node32Of[V]) Len() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.count
+}
+
 // Match locates node which key is equal to or "contains" the key passed as argument.
-func (n *Node32) Match(key uint32, bits int) (interface{}, bool) {
+func (n *Node32Of[V]) Match(key uint32, bits int) (V, bool) {
+	r := n.MatchNode(key, bits)
+	if r == nil {
+		var v V
+		return v, false
+	}
+
+	return r.Value, true
+}
+
+// MatchNode locates node which key is equal to or "contains" the key passed as argument. Unlike Match it returns the node itself so its Key and Bits show which network has matched.
+func (n *Node32Of[V]) MatchNode(key uint32, bits int) *Node32Of[V] {
 	// If tree is empty -
 	if n == nil {
 		// report nothing.
-		return n, false
+		return nil
 	}
 
 	// Adjust bits.
@@ -118,30 +157,41 @@ func (n *Node32) Match(key uint32, bits int) (interface{}, bool) {
 		bits = Key32BitSize
 	}
 
-	r := n.match(key, uint8(bits))
-	if r == nil {
-		return nil, false
-	}
-
-	return r.Value, true
+	return n.match(key, uint8(bits))
 }
 
-func (n *Node32) Children() (*Node32, *Node32) {
+func (n *Node32Of[V]) Children() (*Node32Of[V], *Node32Of[V]) {
 	return n.chld[0], n.chld[1]
 }
 
+// MatchAll locates all nodes which keys are equal to or "contain" the key passed as argument. Nodes are ordered from the least specific to the most specific one.
+func (n *Node32Of[V]) MatchAll(key uint32, bits int) []*Node32Of[V] {
+	if n == nil {
+		return nil
+	}
+
+	if bits < 0 {
+		bits = 0
+	} else if bits > Key32BitSize {
+		bits = Key32BitSize
+	}
+
+	return n.matchAll(key, uint8(bits), nil)
+}
+
 // ExactMatch locates node which exactly matches given key.
-func (n *Node32) ExactMatch(key uint32, bits int) (interface{}, bool) {
+func (n *Node32Of[V]) ExactMatch(key uint32, bits int) (V, bool) {
 	r := n.FindNode(key, bits)
 
 	if r == nil {
-		return nil, false
+		var v V
+		return v, false
 	}
 
 	return r.Value, true
 }
 
-func (n *Node32) FindNode(key uint32, bits int) *Node32 {
+func (n *Node32Of[V]) FindNode(key uint32, bits int) *Node32Of[V] {
 	// If tree is empty -
 	if n == nil {
 		// report nothing.
@@ -163,8 +213,19 @@ func (n *Node32) FindNode(key uint32, bits int) *Node32 {
 	return r
 }
 
+// FindSubtree locates the topmost node which key is equal to or contained by the key passed as argument. The node may be an intermediate one; its Walk or All visits all leaves contained by the key.
+func (n *Node32Of[V]) FindSubtree(key uint32, bits int) *Node32Of[V] {
+	if bits < 0 {
+		bits = 0
+	} else if bits > Key32BitSize {
+		bits = Key32BitSize
+	}
+
+	return n.findSubtree(key, uint8(bits))
+}
+
 // Delete removes subtree which is contained by given key. The method uses copy on write strategy.
-func (n *Node32) Delete(key uint32, bits int) (*Node32, bool) {
+func (n *Node32Of[V]) Delete(key uint32, bits int) (*Node32Of[V], bool) {
 	// If tree is empty -
 	if n == nil {
 		// report nothing.
@@ -181,7 +242,17 @@ func (n *Node32) Delete(key uint32, bits int) (*Node32, bool) {
 	return n.del(key, uint8(bits))
 }
 
-func (n *Node32) dotString() string {
+// Diff calls f for leaves which differ between the tree (old one) and given tree (new one) in order of their keys until f returns false. For a key present in one tree only the other argument is nil. Leaves with the same key are passed together unless they are the same node, so f should compare values to find changed ones. Subtrees shared by both trees are skipped. It reports if all the differences have been visited.
+func (n *Node32Of[V]) Diff(m *Node32Of[V], f func(old, new *Node32Of[V]) bool) bool {
+	return n.diff(m, f)
+}
+
+// Merge returns new tree which contains leaves of both the tree and given tree. For a key present in both trees resolve is called with key and both values (value from the tree goes first) to get value for the result. Neither tree is modified and subtrees which have no counterpart in the other tree are shared with the result.
+func (n *Node32Of[V]) Merge(m *Node32Of[V], resolve func(key uint32, bits int, a, b V) V) *Node32Of[V] {
+	return n.merge(m, resolve)
+}
+
+func (n *Node32Of[V]) dotString() string {
 	if n == nil {
 		return "[label=\"nil\"]"
 	}
@@ -194,7 +265,7 @@ func (n *Node32) dotString() string {
 	return fmt.Sprintf("[label=\"k: %08x, b: %d\"]", n.Key, n.Bits)
 }
 
-func (n *Node32) insert(c *Node32) *Node32 {
+func (n *Node32Of[V]) insert(c *Node32Of[V]) *Node32Of[V] {
 	if n == nil {
 		return c
 	}
@@ -215,16 +286,18 @@ func (n *Node32) insert(c *Node32) *Node32 {
 		if bits == c.Bits {
 			// make new root from the candidate and put current node to one of its branch;
 			c.chld[branch] = n
+			c.updateCount()
 			return c
 		}
 
 		// - NCSB less than NSB of candidate node (it can't be greater because bits after NSB don't count):
 		// make new root (non-leaf node)
-		m := newNode32(c.Key&masks32[bits], bits, false, nil)
+		m := newBranch32[V](c.Key&masks32[bits], bits)
 		// with current tree node at one of branches
 		m.chld[branch] = n
 		// and the candidate at the other.
 		m.chld[1-branch] = c
+		m.updateCount()
 
 		return m
 	}
@@ -233,6 +306,7 @@ func (n *Node32) insert(c *Node32) *Node32 {
 	if c.Bits == n.Bits {
 		// replace current node with the candidate.
 		c.chld = n.chld
+		c.updateCount()
 		return c
 	}
 
@@ -245,14 +319,19 @@ func (n *Node32) insert(c *Node32) *Node32 {
 	branch := (c.Key >> (Key32BitSize - 1 - bits)) & 1
 	// insert it to correct branch.
 	m.chld[branch] = m.chld[branch].insert(c)
+	m.updateCount()
 
 	return m
 }
 
-func (n *Node32) inplaceInsert(key uint32, sbits uint8, value interface{}) *Node32 {
+func (n *Node32Of[V]) inplaceInsert(key uint32, sbits uint8, value V) *Node32Of[V] {
 	var (
-		p      *Node32
+		p      *Node32Of[V]
 		branch uint32
+
+		// Nodes down the path which count should be updated if new leaf is added or value of existing one is replaced.
+		path  [Key32BitSize + 1]*Node32Of[V]
+		depth int
 	)
 
 	r := n
@@ -263,33 +342,43 @@ func (n *Node32) inplaceInsert(key uint32, sbits uint8, value interface{}) *Node
 			pBranch := branch
 			branch = (n.Key >> (Key32BitSize - 1 - cbits)) & 1
 
-			var m *Node32
+			var m *Node32Of[V]
 
 			if cbits == sbits {
 				m = newNode32(key, sbits, true, value)
 				m.chld[branch] = n
 			} else {
-				m = newNode32(key&masks32[cbits], cbits, false, nil)
+				m = newBranch32[V](key&masks32[cbits], cbits)
 				m.chld[1-branch] = newNode32(key, sbits, true, value)
 			}
 
 			m.chld[branch] = n
+			m.updateCount()
 			if p == nil {
 				r = m
 			} else {
 				p.chld[pBranch] = m
 			}
 
+			incrementCount32(path[:depth], m.count-n.count)
 			return r
 		}
 
 		if sbits == n.Bits {
+			count := n.count
+
 			n.Key = key
 			n.Leaf = true
 			n.Value = value
+			n.updateCount()
+
+			incrementCount32(path[:depth], n.count-count)
 			return r
 		}
 
+		path[depth] = n
+		depth++
+
 		p = n
 		branch = (key >> (Key32BitSize - 1 - cbits)) & 1
 		n = n.chld[branch]
@@ -301,25 +390,24 @@ func (n *Node32) inplaceInsert(key uint32, sbits uint8, value interface{}) *Node
 	}
 
 	p.chld[branch] = n
+	incrementCount32(path[:depth], n.count)
 	return r
 }
 
-func (n *Node32) enumerate(ch chan *Node32) {
+func (n *Node32Of[V]) walk(f func(*Node32Of[V]) bool) bool {
 	// Implemented by depth-first search.
-	if n.Leaf {
-		ch <- n
+	if n.Leaf && !f(n) {
+		return false
 	}
 
-	if n.chld[0] != nil {
-		n.chld[0].enumerate(ch)
+	if n.chld[0] != nil && !n.chld[0].walk(f) {
+		return false
 	}
 
-	if n.chld[1] != nil {
-		n.chld[1].enumerate(ch)
-	}
+	return n.chld[1] == nil || n.chld[1].walk(f)
 }
 
-func (n *Node32) match(key uint32, bits uint8) *Node32 {
+func (n *Node32Of[V]) match(key uint32, bits uint8) *Node32Of[V] {
 	// If can't be contained in current root node -
 	if n.Bits > bits {
 		// report nothing.
@@ -360,7 +448,23 @@ func (n *Node32) match(key uint32, bits uint8) *Node32 {
 	return nil
 }
 
-func (n *Node32) exactMatch(key uint32, bits uint8) *Node32 {
+func (n *Node32Of[V]) matchAll(key uint32, bits uint8, r []*Node32Of[V]) []*Node32Of[V] {
+	for n != nil && n.Bits <= bits && (n.Key^key)&masks32[n.Bits] == 0 {
+		if n.Leaf {
+			r = append(r, n)
+		}
+
+		if n.Bits == bits {
+			break
+		}
+
+		n = n.chld[(key>>(Key32BitSize-1-n.Bits))&1]
+	}
+
+	return r
+}
+
+func (n *Node32Of[V]) exactMatch(key uint32, bits uint8) *Node32Of[V] {
 	// If can't be contained in current root node -
 	if n.Bits > bits {
 		// report nothing.
@@ -396,7 +500,154 @@ func (n *Node32) exactMatch(key uint32, bits uint8) *Node32 {
 	return nil
 }
 
-func (n *Node32) del(key uint32, bits uint8) (*Node32, bool) {
+func (n *Node32Of[V]) findSubtree(key uint32, bits uint8) *Node32Of[V] {
+	for n != nil {
+		if n.Bits >= bits {
+			if (n.Key^key)&masks32[bits] == 0 {
+				return n
+			}
+
+			return nil
+		}
+
+		if (n.Key^key)&masks32[n.Bits] != 0 {
+			return nil
+		}
+
+		n = n.chld[(key>>(Key32BitSize-1-n.Bits))&1]
+	}
+
+	return nil
+}
+
+func (n *Node32Of[V]) diff(m *Node32Of[V], f func(old, new *Node32Of[V]) bool) bool {
+	if n == m {
+		return true
+	}
+
+	if n == nil {
+		return m.walk(func(c *Node32Of[V]) bool { return f(nil, c) })
+	}
+
+	if m == nil {
+		return n.walk(func(c *Node32Of[V]) bool { return f(c, nil) })
+	}
+
+	if n.Bits == m.Bits && (n.Key^m.Key)&masks32[n.Bits] == 0 {
+		if n.Leaf || m.Leaf {
+			var a, b *Node32Of[V]
+			if n.Leaf {
+				a = n
+			}
+
+			if m.Leaf {
+				b = m
+			}
+
+			if !f(a, b) {
+				return false
+			}
+		}
+
+		return n.chld[0].diff(m.chld[0], f) && n.chld[1].diff(m.chld[1], f)
+	}
+
+	if n.Bits < m.Bits && (n.Key^m.Key)&masks32[n.Bits] == 0 {
+		if n.Leaf && !f(n, nil) {
+			return false
+		}
+
+		if (m.Key>>(Key32BitSize-1-n.Bits))&1 == 0 {
+			return n.chld[0].diff(m, f) && n.chld[1].diff(nil, f)
+		}
+
+		return n.chld[0].diff(nil, f) && n.chld[1].diff(m, f)
+	}
+
+	if m.Bits < n.Bits && (n.Key^m.Key)&masks32[m.Bits] == 0 {
+		if m.Leaf && !f(nil, m) {
+			return false
+		}
+
+		if (n.Key>>(Key32BitSize-1-m.Bits))&1 == 0 {
+			return n.diff(m.chld[0], f) && (*Node32Of[V])(nil).diff(m.chld[1], f)
+		}
+
+		return (*Node32Of[V])(nil).diff(m.chld[0], f) && n.diff(m.chld[1], f)
+	}
+
+	if n.Key < m.Key {
+		return n.diff(nil, f) && (*Node32Of[V])(nil).diff(m, f)
+	}
+
+	return (*Node32Of[V])(nil).diff(m, f) && n.diff(nil, f)
+}
+
+func (n *Node32Of[V]) merge(m *Node32Of[V], resolve func(key uint32, bits int, a, b V) V) *Node32Of[V] {
+	if n == nil {
+		return m
+	}
+
+	if m == nil {
+		return n
+	}
+
+	// Number of common most significant bits (see insert for details).
+	bits := uint8(bits.LeadingZeros32((n.Key ^ m.Key) | ^masks32[n.Bits] | ^masks32[m.Bits]))
+
+	// Nodes don't contain each other so make new non-leaf root for them.
+	if bits < n.Bits && bits < m.Bits {
+		r := newBranch32[V](n.Key&masks32[bits], bits)
+		branch := (n.Key >> (Key32BitSize - 1 - bits)) & 1
+		r.chld[branch] = n
+		r.chld[1-branch] = m
+		r.updateCount()
+
+		return r
+	}
+
+	// Both nodes have the same key so merge their values and branches.
+	if n.Bits == m.Bits {
+		r := newNode32(n.Key, n.Bits, n.Leaf || m.Leaf, n.Value)
+		if m.Leaf {
+			if n.Leaf {
+				r.Value = resolve(n.Key, int(n.Bits), n.Value, m.Value)
+			} else {
+				r.Value = m.Value
+			}
+		}
+
+		r.chld[0] = n.chld[0].merge(m.chld[0], resolve)
+		r.chld[1] = n.chld[1].merge(m.chld[1], resolve)
+		r.updateCount()
+
+		return r
+	}
+
+	// Current node contains the other one so merge it to correct branch.
+	if n.Bits < m.Bits {
+		r := newNode32(n.Key, n.Bits, n.Leaf, n.Value)
+		r.chld = n.chld
+
+		branch := (m.Key >> (Key32BitSize - 1 - n.Bits)) & 1
+		r.chld[branch] = r.chld[branch].merge(m, resolve)
+		r.updateCount()
+
+		return r
+	}
+
+	// The other node contains current one.
+	r := newNode32(m.Key, m.Bits, m.Leaf, m.Value)
+	r.chld = m.chld
+
+	branch := (n.Key >> (Key32BitSize - 1 - m.Bits)) & 1
+	r.chld[branch] = n.merge(r.chld[branch], resolve)
+	r.updateCount()
+
+	return r
+}
+
+func (n *Node32Of[V]) del(key uint32, bits uint8) (*Node32Of[V], bool) {
 	// If key can contain current tree node -
 	if bits <= n.Bits {
 		// report empty new tree and put deletion mark if it contains indeed.
@@ -440,13 +691,38 @@ func (n *Node32) del(key uint32, bits uint8) (*Node32, bool) {
 
 	// Replace changed child with new one and return new root with deletion mark set.
 	m.chld[branch] = c
+	m.updateCount()
 	return m, true
 }
 
-func newNode32(key uint32, bits uint8, leaf bool, value interface{}) *Node32 {
-	return &Node32{
+func newNode32[V any](key uint32, bits uint8, leaf bool, value V) *Node32Of[V] {
+	n := &Node32Of[V]{
 		Key:   key,
 		Bits:  bits,
 		Leaf:  leaf,
 		Value: value}
+
+	if leaf {
+		n.count = leafCount(&n.Value)
+	}
+
+	return n
+}
+
+// newBranch32 creates intermediate node without data.
+func newBranch32[V any](key uint32, bits uint8) *Node32Of[V] {
+	return &Node32Of[V]{Key: key, Bits: bits}
+}
+
+func (n *Node32Of[V]) updateCount() {
+	n.count = n.chld[0].Len() + n.chld[1].Len()
+	if n.Leaf {
+		n.count += leafCount(&n.Value)
+	}
+}
+
+func incrementCount32[V any](path []*Node32Of[V], d int) {
+	for _, n := range path {
+		n.count += d
+	}
 }
@@ -2,6 +2,7 @@ package numtree
 
 import (
 	"fmt"
+	"iter"
 	"math/bits"
 )
 
@@ -29,8 +30,8 @@ var (
 		0xffffffffffffffff}
 )
 
-// Node64 is an element of radix tree with 64-bit unsigned integer as a key.
-type Node64 struct {
+// Node64Of is an element of radix tree with 64-bit unsigned integer as a key and values of type V.
+type Node64Of[V any] struct {
 	// Key stores key for current node.
 	Key uint64
 	// Bits is a number of significant bits in Key.
@@ -38,17 +39,21 @@ type Node64 struct {
 	// Leaf indicates if the node is leaf node and contains any data in Value.
 	Leaf bool
 	// Value contains data associated with key.
-	Value interface{}
+	Value V
 
-	chld [2]*Node64
+	chld  [2]*Node64Of[V]
+	count int
 }
 
+// Node64 is an element of radix tree with 64-bit unsigned integer as a key and interface{} values.
+type Node64 = Node64Of[interface{}]
+
 // Dot dumps tree to Graphviz .dot format
-func (n *Node64) Dot() string {
+func (n *Node64Of[V]) Dot() string {
 	body := ""
 
 	i := 0
-	queue := []*Node64{n}
+	queue := []*Node64Of[V]{n}
 	for len(queue) > 0 {
 		c := queue[0]
 		body += fmt.Sprintf("N%d %s\n", i, c.dotString())
@@ -65,12 +70,12 @@ func (n *Node64) Dot() string {
 	return "digraph d {\n" + body + "}\n"
 }
 
-func (n *Node64) Children() (*Node64, *Node64) {
+func (n *Node64Of[V]) Children() (*Node64Of[V], *Node64Of[V]) {
 	return n.chld[0], n.chld[1]
 }
 
 // Insert puts new leaf to radix tree and returns pointer to new root. The method uses copy on write strategy so old root doesn't see the change.
-func (n *Node64) Insert(key uint64, bits int, value interface{}) *Node64 {
+func (n *Node64Of[V]) Insert(key uint64, bits int, value V) *Node64Of[V] {
 	if bits < 0 {
 		bits = 0
 	} else if bits > Key64BitSize {
@@ -81,7 +86,7 @@ func (n *Node64) Insert(key uint64, bits int, value interface{}) *Node64 {
 }
 
 // InplaceInsert puts new leaf to radix tree (or replaces value in existing one). The method inserts data directly to current tree so make sure you have exclusive access to it.
-func (n *Node64) InplaceInsert(key uint64, bits int, value interface{}) *Node64 {
+func (n *Node64Of[V]) InplaceInsert(key uint64, bits int, value V) *Node64Of[V] {
 	// Adjust bits.
 	if bits < 0 {
 		bits = 0
@@ -92,27 +97,63 @@ func (n *Node64) InplaceInsert(key uint64, bits int, value interface{}) *Node64
 	return n.inplaceInsert(key, uint8(bits), value)
 }
 
-// Enumerate returns channel which is populated by nodes in order of their keys.
-func (n *Node64) Enumerate() chan *Node64 {
-	ch := make(chan *Node64)
+// Enumerate returns channel which is populated by nodes with data in order of their keys.
+func (n *Node64Of[V]) Enumerate() chan *Node64Of[V] {
+	ch := make(chan *Node64Of[V])
 
 	go func() {
 		defer close(ch)
 
-		if n == nil {
-			return
+		for c := range n.All() {
+			ch <- c
 		}
-
-		n.enumerate(ch)
 	}()
 
 	return ch
 }
 
+// All returns iterator over nodes with data in order of their keys.
+func (n *Node64Of[V]) All() iter.Seq[*Node64Of[V]] {
+	return func(yield func(*Node64Of[V]) bool) {
+		n.Walk(yield)
+	}
+}
+
+// Walk calls f for nodes with data in order of their keys until f returns false. It reports if all the nodes have been visited.
+func (n *Node64Of[V]) Walk(f func(*Node64Of[V]) bool) bool {
+	// If tree is empty -
+	if n == nil {
+		// there is nothing to visit.
+		return true
+	}
+
+	return n.walk(f)
+}
+
+// Len returns number of leaves in the tree. It takes constant time as the number is kept in every node.
+func (n *Node64Of[V]) Len() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.count
+}
+
 // Match locates node which key is equal to or "contains" the key passed as argument.
-func (n *Node64) Match(key uint64, bits int) (interface{}, bool) {
+func (n *Node64Of[V]) Match(key uint64, bits int) (V, bool) {
+	r := n.MatchNode(key, bits)
+	if r == nil {
+		var v V
+		return v, false
+	}
+
+	return r.Value, true
+}
+
+// MatchNode locates node which key is equal to or "contains" the key passed as argument. Unlike Match it returns the node itself so its Key and Bits show which network has matched.
+func (n *Node64Of[V]) MatchNode(key uint64, bits int) *Node64Of[V] {
 	if n == nil {
-		return n, false
+		return nil
 	}
 
 	if bits < 0 {
@@ -121,24 +162,35 @@ func (n *Node64) Match(key uint64, bits int) (interface{}, bool) {
 		bits = Key64BitSize
 	}
 
-	r := n.match(key, uint8(bits))
-	if r == nil {
-		return nil, false
+	return n.match(key, uint8(bits))
+}
+
+// MatchAll locates all nodes which keys are equal to or "contain" the key passed as argument. Nodes are ordered from the least specific to the most specific one.
+func (n *Node64Of[V]) MatchAll(key uint64, bits int) []*Node64Of[V] {
+	if n == nil {
+		return nil
 	}
 
-	return r.Value, true
+	if bits < 0 {
+		bits = 0
+	} else if bits > Key64BitSize {
+		bits = Key64BitSize
+	}
+
+	return n.matchAll(key, uint8(bits), nil)
 }
 
 // ExactMatch locates node which exactly matches given key.
-func (n *Node64) ExactMatch(key uint64, bits int) (interface{}, bool) {
+func (n *Node64Of[V]) ExactMatch(key uint64, bits int) (V, bool) {
 	r := n.FindNode(key, bits)
 	if r == nil {
-		return nil, false
+		var v V
+		return v, false
 	}
 	return r.Value, true
 }
 
-func (n *Node64) FindNode(key uint64, bits int) *Node64 {
+func (n *Node64Of[V]) FindNode(key uint64, bits int) *Node64Of[V] {
 	if n == nil {
 		return nil
 	}
@@ -157,8 +209,19 @@ func (n *Node64) FindNode(key uint64, bits int) *Node64 {
 	return r
 }
 
+// FindSubtree locates the topmost node which key is equal to or contained by the key passed as argument. The node may be an intermediate one; its Walk or All visits all leaves contained by the key.
+func (n *Node64Of[V]) FindSubtree(key uint64, bits int) *Node64Of[V] {
+	if bits < 0 {
+		bits = 0
+	} else if bits > Key64BitSize {
+		bits = Key64BitSize
+	}
+
+	return n.findSubtree(key, uint8(bits))
+}
+
 // Delete removes subtree which is contained by given key. The method uses copy on write strategy.
-func (n *Node64) Delete(key uint64, bits int) (*Node64, bool) {
+func (n *Node64Of[V]) Delete(key uint64, bits int) (*Node64Of[V], bool) {
 	if n == nil {
 		return n, false
 	}
@@ -172,7 +235,17 @@ func (n *Node64) Delete(key uint64, bits int) (*Node64, bool) {
 	return n.del(key, uint8(bits))
 }
 
-func (n *Node64) dotString() string {
+// Diff calls f for leaves which differ between the tree (old one) and given tree (new one) in order of their keys until f returns false. For a key present in one tree only the other argument is nil. Leaves with the same key are passed together unless they are the same node, so f should compare values to find changed ones. Subtrees shared by both trees are skipped. It reports if all the differences have been visited.
+func (n *Node64Of[V]) Diff(m *Node64Of[V], f func(old, new *Node64Of[V]) bool) bool {
+	return n.diff(m, f)
+}
+
+// Merge returns new tree which contains leaves of both the tree and given tree. For a key present in both trees resolve is called with key and both values (value from the tree goes first) to get value for the result. Neither tree is modified and subtrees which have no counterpart in the other tree are shared with the result.
+func (n *Node64Of[V]) Merge(m *Node64Of[V], resolve func(key uint64, bits int, a, b V) V) *Node64Of[V] {
+	return n.merge(m, resolve)
+}
+
+func (n *Node64Of[V]) dotString() string {
 	if n == nil {
 		return "[label=\"nil\"]"
 	}
@@ -185,7 +258,7 @@ func (n *Node64) dotString() string {
 	return fmt.Sprintf("[label=\"k: %016x, b: %d\"]", n.Key, n.Bits)
 }
 
-func (n *Node64) insert(c *Node64) *Node64 {
+func (n *Node64Of[V]) insert(c *Node64Of[V]) *Node64Of[V] {
 	if n == nil {
 		return c
 	}
@@ -195,18 +268,21 @@ func (n *Node64) insert(c *Node64) *Node64 {
 		branch := (n.Key >> (Key64BitSize - 1 - bits)) & 1
 		if bits == c.Bits {
 			c.chld[branch] = n
+			c.updateCount()
 			return c
 		}
 
-		m := newNode64(c.Key&masks64[bits], bits, false, nil)
+		m := newBranch64[V](c.Key&masks64[bits], bits)
 		m.chld[branch] = n
 		m.chld[1-branch] = c
+		m.updateCount()
 
 		return m
 	}
 
 	if c.Bits == n.Bits {
 		c.chld = n.chld
+		c.updateCount()
 		return c
 	}
 
@@ -215,14 +291,18 @@ func (n *Node64) insert(c *Node64) *Node64 {
 
 	branch := (c.Key >> (Key64BitSize - 1 - bits)) & 1
 	m.chld[branch] = m.chld[branch].insert(c)
+	m.updateCount()
 
 	return m
 }
 
-func (n *Node64) inplaceInsert(key uint64, sbits uint8, value interface{}) *Node64 {
+func (n *Node64Of[V]) inplaceInsert(key uint64, sbits uint8, value V) *Node64Of[V] {
 	var (
-		p      *Node64
+		p      *Node64Of[V]
 		branch uint64
+
+		path  [Key64BitSize + 1]*Node64Of[V]
+		depth int
 	)
 
 	r := n
@@ -233,33 +313,43 @@ func (n *Node64) inplaceInsert(key uint64, sbits uint8, value interface{}) *Node
 			pBranch := branch
 			branch = (n.Key >> (Key64BitSize - 1 - cbits)) & 1
 
-			var m *Node64
+			var m *Node64Of[V]
 
 			if cbits == sbits {
 				m = newNode64(key, sbits, true, value)
 				m.chld[branch] = n
 			} else {
-				m = newNode64(key&masks64[cbits], cbits, false, nil)
+				m = newBranch64[V](key&masks64[cbits], cbits)
 				m.chld[1-branch] = newNode64(key, sbits, true, value)
 			}
 
 			m.chld[branch] = n
+			m.updateCount()
 			if p == nil {
 				r = m
 			} else {
 				p.chld[pBranch] = m
 			}
 
+			incrementCount64(path[:depth], m.count-n.count)
 			return r
 		}
 
 		if sbits == n.Bits {
+			count := n.count
+
 			n.Key = key
 			n.Leaf = true
 			n.Value = value
+			n.updateCount()
+
+			incrementCount64(path[:depth], n.count-count)
 			return r
 		}
 
+		path[depth] = n
+		depth++
+
 		p = n
 		branch = (key >> (Key64BitSize - 1 - cbits)) & 1
 		n = n.chld[branch]
@@ -271,24 +361,24 @@ func (n *Node64) inplaceInsert(key uint64, sbits uint8, value interface{}) *Node
 	}
 
 	p.chld[branch] = n
+	incrementCount64(path[:depth], n.count)
 	return r
 }
 
-func (n *Node64) enumerate(ch chan *Node64) {
-	if n.Leaf {
-		ch <- n
+func (n *Node64Of[V]) walk(f func(*Node64Of[V]) bool) bool {
+	// Implemented by depth-first search.
+	if n.Leaf && !f(n) {
+		return false
 	}
 
-	if n.chld[0] != nil {
-		n.chld[0].enumerate(ch)
+	if n.chld[0] != nil && !n.chld[0].walk(f) {
+		return false
 	}
 
-	if n.chld[1] != nil {
-		n.chld[1].enumerate(ch)
-	}
+	return n.chld[1] == nil || n.chld[1].walk(f)
 }
 
-func (n *Node64) match(key uint64, bits uint8) *Node64 {
+func (n *Node64Of[V]) match(key uint64, bits uint8) *Node64Of[V] {
 	if n.Bits > bits {
 		return nil
 	}
@@ -320,7 +410,23 @@ func (n *Node64) match(key uint64, bits uint8) *Node64 {
 	return nil
 }
 
-func (n *Node64) exactMatch(key uint64, bits uint8) *Node64 {
+func (n *Node64Of[V]) matchAll(key uint64, bits uint8, r []*Node64Of[V]) []*Node64Of[V] {
+	for n != nil && n.Bits <= bits && (n.Key^key)&masks64[n.Bits] == 0 {
+		if n.Leaf {
+			r = append(r, n)
+		}
+
+		if n.Bits == bits {
+			break
+		}
+
+		n = n.chld[(key>>(Key64BitSize-1-n.Bits))&1]
+	}
+
+	return r
+}
+
+func (n *Node64Of[V]) exactMatch(key uint64, bits uint8) *Node64Of[V] {
 	if n.Bits > bits {
 		return nil
 	}
@@ -348,7 +454,154 @@ func (n *Node64) exactMatch(key uint64, bits uint8) *Node64 {
 	return nil
 }
 
-func (n *Node64) del(key uint64, bits uint8) (*Node64, bool) {
+func (n *Node64Of[V]) findSubtree(key uint64, bits uint8) *Node64Of[V] {
+	for n != nil {
+		if n.Bits >= bits {
+			if (n.Key^key)&masks64[bits] == 0 {
+				return n
+			}
+
+			return nil
+		}
+
+		if (n.Key^key)&masks64[n.Bits] != 0 {
+			return nil
+		}
+
+		n = n.chld[(key>>(Key64BitSize-1-n.Bits))&1]
+	}
+
+	return nil
+}
+
+func (n *Node64Of[V]) diff(m *Node64Of[V], f func(old, new *Node64Of[V]) bool) bool {
+	if n == m {
+		return true
+	}
+
+	if n == nil {
+		return m.walk(func(c *Node64Of[V]) bool { return f(nil, c) })
+	}
+
+	if m == nil {
+		return n.walk(func(c *Node64Of[V]) bool { return f(c, nil) })
+	}
+
+	if n.Bits == m.Bits && (n.Key^m.Key)&masks64[n.Bits] == 0 {
+		if n.Leaf || m.Leaf {
+			var a, b *Node64Of[V]
+			if n.Leaf {
+				a = n
+			}
+
+			if m.Leaf {
+				b = m
+			}
+
+			if !f(a, b) {
+				return false
+			}
+		}
+
+		return n.chld[0].diff(m.chld[0], f) && n.chld[1].diff(m.chld[1], f)
+	}
+
+	if n.Bits < m.Bits && (n.Key^m.Key)&masks64[n.Bits] == 0 {
+		if n.Leaf && !f(n, nil) {
+			return false
+		}
+
+		if (m.Key>>(Key64BitSize-1-n.Bits))&1 == 0 {
+			return n.chld[0].diff(m, f) && n.chld[1].diff(nil, f)
+		}
+
+		return n.chld[0].diff(nil, f) && n.chld[1].diff(m, f)
+	}
+
+	if m.Bits < n.Bits && (n.Key^m.Key)&masks64[m.Bits] == 0 {
+		if m.Leaf && !f(nil, m) {
+			return false
+		}
+
+		if (n.Key>>(Key64BitSize-1-m.Bits))&1 == 0 {
+			return n.diff(m.chld[0], f) && (*Node64Of[V])(nil).diff(m.chld[1], f)
+		}
+
+		return (*Node64Of[V])(nil).diff(m.chld[0], f) && n.diff(m.chld[1], f)
+	}
+
+	if n.Key < m.Key {
+		return n.diff(nil, f) && (*Node64Of[V])(nil).diff(m, f)
+	}
+
+	return (*Node64Of[V])(nil).diff(m, f) && n.diff(nil, f)
+}
+
+func (n *Node64Of[V]) merge(m *Node64Of[V], resolve func(key uint64, bits int, a, b V) V) *Node64Of[V] {
+	if n == nil {
+		return m
+	}
+
+	if m == nil {
+		return n
+	}
+
+	// Number of common most significant bits (see insert for details).
+	bits := uint8(bits.LeadingZeros64((n.Key ^ m.Key) | ^masks64[n.Bits] | ^masks64[m.Bits]))
+
+	// Nodes don't contain each other so make new non-leaf root for them.
+	if bits < n.Bits && bits < m.Bits {
+		r := newBranch64[V](n.Key&masks64[bits], bits)
+		branch := (n.Key >> (Key64BitSize - 1 - bits)) & 1
+		r.chld[branch] = n
+		r.chld[1-branch] = m
+		r.updateCount()
+
+		return r
+	}
+
+	// Both nodes have the same key so merge their values and branches.
+	if n.Bits == m.Bits {
+		r := newNode64(n.Key, n.Bits, n.Leaf || m.Leaf, n.Value)
+		if m.Leaf {
+			if n.Leaf {
+				r.Value = resolve(n.Key, int(n.Bits), n.Value, m.Value)
+			} else {
+				r.Value = m.Value
+			}
+		}
+
+		r.chld[0] = n.chld[0].merge(m.chld[0], resolve)
+		r.chld[1] = n.chld[1].merge(m.chld[1], resolve)
+		r.updateCount()
+
+		return r
+	}
+
+	// Current node contains the other one so merge it to correct branch.
+	if n.Bits < m.Bits {
+		r := newNode64(n.Key, n.Bits, n.Leaf, n.Value)
+		r.chld = n.chld
+
+		branch := (m.Key >> (Key64BitSize - 1 - n.Bits)) & 1
+		r.chld[branch] = r.chld[branch].merge(m, resolve)
+		r.updateCount()
+
+		return r
+	}
+
+	// The other node contains current one.
+	r := newNode64(m.Key, m.Bits, m.Leaf, m.Value)
+	r.chld = m.chld
+
+	branch := (n.Key >> (Key64BitSize - 1 - m.Bits)) & 1
+	r.chld[branch] = n.merge(r.chld[branch], resolve)
+	r.updateCount()
+
+	return r
+}
+
+func (n *Node64Of[V]) del(key uint64, bits uint8) (*Node64Of[V], bool) {
 	if bits <= n.Bits {
 		if (n.Key^key)&masks64[bits] == 0 {
 			return nil, true
@@ -380,13 +633,38 @@ func (n *Node64) del(key uint64, bits uint8) (*Node64, bool) {
 	m.chld = n.chld
 
 	m.chld[branch] = c
+	m.updateCount()
 	return m, true
 }
 
-func newNode64(key uint64, bits uint8, leaf bool, value interface{}) *Node64 {
-	return &Node64{
+func newNode64[V any](key uint64, bits uint8, leaf bool, value V) *Node64Of[V] {
+	n := &Node64Of[V]{
 		Key:   key,
 		Bits:  bits,
 		Leaf:  leaf,
 		Value: value}
+
+	if leaf {
+		n.count = leafCount(&n.Value)
+	}
+
+	return n
+}
+
+// newBranch64 creates intermediate node without data.
+func newBranch64[V any](key uint64, bits uint8) *Node64Of[V] {
+	return &Node64Of[V]{Key: key, Bits: bits}
+}
+
+func (n *Node64Of[V]) updateCount() {
+	n.count = n.chld[0].Len() + n.chld[1].Len()
+	if n.Leaf {
+		n.count += leafCount(&n.Value)
+	}
+}
+
+func incrementCount64[V any](path []*Node64Of[V], d int) {
+	for _, n := range path {
+		n.count += d
+	}
 }
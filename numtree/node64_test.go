@@ -187,6 +187,72 @@ func TestEnumerate64(t *testing.T) {
 		"0xabaaaaaa00000000/9: \"L2.2\"")
 }
 
+func TestAll64(t *testing.T) {
+	var r *Node64
+
+	for n := range r.All() {
+		t.Errorf("Expected no nodes in empty tree but got %#v", n)
+	}
+
+	r = r.Insert(0xAAAAAAAA00000000, 7, "L1")
+	r = r.Insert(0xA8AAAAAA00000000, 9, "L2.1")
+	r = r.Insert(0xABAAAAAA00000000, 9, "L2.2")
+	r = r.Insert(0xAAAAAAAA00000000, 18, "L3")
+
+	items := []string{}
+	for n := range r.All() {
+		items = append(items, fmt.Sprintf("0x%016x/%d\n", n.Key, n.Bits))
+		if len(items) >= 3 {
+			break
+		}
+	}
+
+	assertStringLists(items, []string{
+		"0xa8aaaaaa00000000/9\n",
+		"0xaaaaaaaa00000000/7\n",
+		"0xaaaaaaaa00000000/18\n",
+	}, "64-tree iteration with break", t)
+}
+
+func TestWalk64(t *testing.T) {
+	var r *Node64
+
+	if !r.Walk(func(n *Node64) bool {
+		t.Errorf("Expected no nodes in empty tree but got %#v", n)
+		return true
+	}) {
+		t.Error("Expected complete walk over empty tree")
+	}
+
+	r = r.Insert(0xAAAAAAAA00000000, 7, "L1")
+	r = r.Insert(0xA8AAAAAA00000000, 9, "L2.1")
+	r = r.Insert(0xABAAAAAA00000000, 9, "L2.2")
+
+	count := 0
+	if r.Walk(func(n *Node64) bool {
+		count++
+		return count < 2
+	}) {
+		t.Error("Expected interrupted walk")
+	}
+
+	if count != 2 {
+		t.Errorf("Expected walk to stop after 2 nodes but got %d", count)
+	}
+
+	count = 0
+	if !r.Walk(func(n *Node64) bool {
+		count++
+		return true
+	}) {
+		t.Error("Expected complete walk")
+	}
+
+	if count != 3 {
+		t.Errorf("Expected walk over 3 nodes but got %d", count)
+	}
+}
+
 func TestMatch64(t *testing.T) {
 	var r *Node64
 
@@ -233,6 +299,184 @@ func TestMatch64(t *testing.T) {
 		"64-tree match with contains match to non-leaf node", t)
 }
 
+func TestMatchNode64(t *testing.T) {
+	var r *Node64
+
+	if n := r.MatchNode(0, 0); n != nil {
+		t.Errorf("Expected no node in empty tree but got %#v", n)
+	}
+
+	r = r.Insert(0xAAAAAAAA00000000, 7, "L1")
+	r = r.Insert(0xAAAAAAAA00000000, 18, "L3")
+
+	n := r.MatchNode(0xAAAAAAAB00000000, 20)
+	if n == nil {
+		t.Error("Expected node for 0xAAAAAAAB00000000/20 but got nothing")
+	} else if n.Key != 0xAAAAAAAA00000000 || n.Bits != 18 || n.Value != "L3" {
+		t.Errorf("Expected L3 node at 0xAAAAAAAA00000000/18 but got 0x%x/%d %#v", n.Key, n.Bits, n.Value)
+	}
+
+	if n := r.MatchNode(0xAAAAAAAA00000000, 5); n != nil {
+		t.Errorf("Expected no node for 0xAAAAAAAA00000000/5 but got 0x%x/%d %#v", n.Key, n.Bits, n.Value)
+	}
+}
+
+func TestMatchAll64(t *testing.T) {
+	var r *Node64
+
+	if nodes := r.MatchAll(0, 0); len(nodes) > 0 {
+		t.Errorf("Expected no nodes in empty tree but got %d", len(nodes))
+	}
+
+	r = r.Insert(0xAAAAAAAA00000000, 7, "L1")
+	r = r.Insert(0xA8AAAAAA00000000, 9, "L2.1")
+	r = r.Insert(0xABAAAAAA00000000, 9, "L2.2")
+	r = r.Insert(0xAAAAAAAA00000000, 18, "L3")
+	r = r.Insert(0xAABAAAAA00000000, 19, "L4")
+
+	assertMatchAll64(r.MatchAll(0xAAAAAAAA00000000, -1), "negative significant bits", t)
+	assertMatchAll64(r.MatchAll(0xAAAAAAAA00000000, 5), "small significant bits number", t)
+	assertMatchAll64(r.MatchAll(0xAAAAAAAA00000000, 100), "overflow significant bits number", t, "L1", "L3")
+	assertMatchAll64(r.MatchAll(0xAAAAAAAA00000000, 18), "exact match to deepest node", t, "L1", "L3")
+	assertMatchAll64(r.MatchAll(0xABAAAAAA00000000, 12), "match on other branch", t, "L1", "L2.2")
+	assertMatchAll64(r.MatchAll(0x5555555500000000, 12), "no match", t)
+}
+
+func assertMatchAll64(nodes []*Node64, desc string, t *testing.T, e ...string) {
+	t.Helper()
+
+	v := make([]string, len(nodes))
+	for i, n := range nodes {
+		v[i] = n.Value.(string)
+	}
+
+	if fmt.Sprintf("%q", v) != fmt.Sprintf("%q", e) {
+		t.Errorf("Expected %q for %s but got %q", e, desc, v)
+	}
+}
+
+func TestFindSubtree64(t *testing.T) {
+	var r *Node64
+
+	if n := r.FindSubtree(0, 0); n != nil {
+		t.Errorf("Expected no node in empty tree but got %#v", n)
+	}
+
+	r = r.Insert(0xAAAAAAAA00000000, 7, "L1")
+	r = r.Insert(0xA8AAAAAA00000000, 9, "L2.1")
+	r = r.Insert(0xABAAAAAA00000000, 9, "L2.2")
+	r = r.Insert(0xAAAAAAAA00000000, 18, "L3")
+
+	assertFindSubtree64(r.FindSubtree(0xAAAAAAAA00000000, -1), "negative significant bits", t, "L2.1", "L1", "L3", "L2.2")
+	assertFindSubtree64(r.FindSubtree(0xA8AAAAAA00000000, 7), "leaf with longer key", t, "L2.1")
+	assertFindSubtree64(r.FindSubtree(0xAAAAAAAA00000000, 8), "contained leaf", t, "L3")
+	assertFindSubtree64(r.FindSubtree(0xABAAAAAA00000000, 9), "exact match to a node", t, "L2.2")
+	assertFindSubtree64(r.FindSubtree(0xAAAAAAAA00000000, 100), "overflow significant bits number", t)
+	assertFindSubtree64(r.FindSubtree(0x5555555500000000, 4), "no match", t)
+}
+
+func TestDiff64(t *testing.T) {
+	var r *Node64
+	assertDiff64(r, r, "empty trees", t)
+
+	r = r.Insert(0xAAAAAAAAAAAAAAAA, 7, "L1")
+	r = r.Insert(0xA8AAAAAAAAAAAAAA, 9, "L2.1")
+	r = r.Insert(0xABAAAAAAAAAAAAAA, 9, "L2.2")
+	r = r.Insert(0xAAAAAAAAAAAAAAAA, 18, "L3")
+
+	assertDiff64(nil, r, "added tree", t, "+L2.1", "+L1", "+L3", "+L2.2")
+	assertDiff64(r, nil, "removed tree", t, "-L2.1", "-L1", "-L3", "-L2.2")
+	assertDiff64(r, r, "same tree", t)
+
+	u := r.Insert(0xAAAAAAAAAAAAAAAA, 18, "L3.1")
+	u = u.Insert(0x5555555555555555, 16, "L4")
+	u, _ = u.Delete(0xA8AAAAAAAAAAAAAA, 9)
+	assertDiff64(r, u, "changed tree", t, "+L4", "-L2.1", "L1=L1", "L3>L3.1")
+
+	u = r.Insert(0xA000000000000000, 4, "L0")
+	assertDiff64(r, u, "tree with new root", t, "+L0")
+	assertDiff64(u, r, "tree with removed root", t, "-L0")
+
+	u = r.Insert(0xAAAAAAAAAAAAAAAA, 8, "L1.1")
+	assertDiff64(r, u, "tree with inserted node", t, "L1=L1", "+L1.1")
+
+	if r.Diff(u, func(a, b *Node64) bool { return false }) {
+		t.Errorf("Expected interrupted diff but got completed one")
+	}
+}
+
+func TestMerge64(t *testing.T) {
+	var r *Node64
+
+	resolve := func(key uint64, bits int, a, b interface{}) interface{} {
+		return fmt.Sprintf("%s+%s", a, b)
+	}
+
+	assertFindSubtree64(r.Merge(nil, resolve), "empty trees", t)
+
+	r = r.Insert(0xAAAAAAAAAAAAAAAA, 7, "L1")
+	r = r.Insert(0xA8AAAAAAAAAAAAAA, 9, "L2.1")
+	r = r.Insert(0xAAAAAAAAAAAAAAAA, 18, "L3")
+
+	var u *Node64
+	u = u.Insert(0xABAAAAAAAAAAAAAA, 9, "L2.2")
+	u = u.Insert(0xAAAAAAAAAAAAAAAA, 18, "U3")
+	u = u.Insert(0xAAAAAAAAAAAAAAAA, 8, "U1.1")
+	u = u.Insert(0x5555555555555555, 16, "U4")
+
+	assertFindSubtree64(r.Merge(nil, resolve), "merge with empty tree", t, "L2.1", "L1", "L3")
+	assertFindSubtree64((*Node64)(nil).Merge(u, resolve), "merge to empty tree", t, "U4", "U1.1", "U3", "L2.2")
+
+	m := r.Merge(u, resolve)
+	assertFindSubtree64(m, "merged tree", t, "U4", "L2.1", "L1", "U1.1", "L3+U3", "L2.2")
+	assertFindSubtree64(u.Merge(r, resolve), "reversed merged tree", t, "U4", "L2.1", "L1", "U1.1", "U3+L3", "L2.2")
+	assertFindSubtree64(r, "first tree after merge", t, "L2.1", "L1", "L3")
+	assertFindSubtree64(u, "second tree after merge", t, "U4", "U1.1", "U3", "L2.2")
+
+	v, ok := m.Match(0xAAAAAAAAAAAAAAAA, 64)
+	assertTreeMatch(v, ok, wrapStr("L3+U3"), "merged tree", t)
+}
+
+func assertFindSubtree64(r *Node64, desc string, t *testing.T, e ...string) {
+	t.Helper()
+
+	v := []string{}
+	for n := range r.All() {
+		v = append(v, n.Value.(string))
+	}
+
+	if fmt.Sprintf("%q", v) != fmt.Sprintf("%q", e) {
+		t.Errorf("Expected %q for %s but got %q", e, desc, v)
+	}
+}
+
+func assertDiff64(r, u *Node64, desc string, t *testing.T, e ...string) {
+	t.Helper()
+
+	v := []string{}
+	r.Diff(u, func(a, b *Node64) bool {
+		switch {
+		case a == nil:
+			v = append(v, "+"+b.Value.(string))
+
+		case b == nil:
+			v = append(v, "-"+a.Value.(string))
+
+		case a.Value == b.Value:
+			v = append(v, a.Value.(string)+"="+b.Value.(string))
+
+		default:
+			v = append(v, a.Value.(string)+">"+b.Value.(string))
+		}
+
+		return true
+	})
+
+	if fmt.Sprintf("%q", v) != fmt.Sprintf("%q", e) {
+		t.Errorf("Expected %q for %s but got %q", e, desc, v)
+	}
+}
+
 func TestExactMatch64(t *testing.T) {
 	var r *Node64
 
@@ -0,0 +1,80 @@
+package numtree
+
+import (
+	"unsafe"
+
+	"github.com/infobloxopen/go-trees/internal/weight"
+)
+
+// Stats holds tree statistics returned by Stats method.
+type Stats struct {
+	// Nodes is a number of all nodes in the tree including intermediate ones.
+	Nodes int
+	// Leaves is a number of nodes with data.
+	Leaves int
+	// MaxDepth is a number of nodes on the longest path from the root.
+	MaxDepth int
+	// Bytes is an estimated memory size of the nodes. It doesn't include memory referenced by values.
+	Bytes int
+}
+
+// Stats walks the tree and collects its statistics.
+func (n *Node32Of[V]) Stats() Stats {
+	var s Stats
+	n.stats(1, &s)
+	s.Bytes = s.Nodes * int(unsafe.Sizeof(Node32Of[V]{}))
+	return s
+}
+
+// Stats walks the tree and collects its statistics.
+func (n *Node64Of[V]) Stats() Stats {
+	var s Stats
+	n.stats(1, &s)
+	s.Bytes = s.Nodes * int(unsafe.Sizeof(Node64Of[V]{}))
+	return s
+}
+
+func (n *Node32Of[V]) stats(depth int, s *Stats) {
+	if n == nil {
+		return
+	}
+
+	s.Nodes++
+	if n.Leaf {
+		s.Leaves++
+	}
+
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	n.chld[0].stats(depth+1, s)
+	n.chld[1].stats(depth+1, s)
+}
+
+func (n *Node64Of[V]) stats(depth int, s *Stats) {
+	if n == nil {
+		return
+	}
+
+	s.Nodes++
+	if n.Leaf {
+		s.Leaves++
+	}
+
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	n.chld[0].stats(depth+1, s)
+	n.chld[1].stats(depth+1, s)
+}
+
+// leafCount returns number of items which leaf with given value adds to Len. It's one for any value except ones of the module's own trees which implement weight.Weigher.
+func leafCount[V any](v *V) int {
+	if w, ok := any(v).(weight.Weigher); ok {
+		return int(w.Weight())
+	}
+
+	return 1
+}
@@ -0,0 +1,229 @@
+package numtree
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/infobloxopen/go-trees/internal/weight"
+)
+
+func TestLen32(t *testing.T) {
+	var r *Node32
+	assertLen32(r, 0, "empty tree", t)
+
+	r = r.Insert(0xAAAAAAAA, 7, "L1")
+	r = r.Insert(0xA8AAAAAA, 9, "L2.1")
+	r = r.Insert(0xABAAAAAA, 9, "L2.2")
+	r = r.Insert(0xAAAAAAAA, 18, "L3")
+	r = r.Insert(0xAAAAAAAA, 7, "L1.1")
+	assertLen32(r, 4, "tree", t)
+
+	u, _ := r.Delete(0xABAAAAAA, 8)
+	assertLen32(u, 3, "tree after deletion", t)
+	assertLen32(r, 4, "tree before deletion", t)
+
+	u, _ = r.Delete(0xAAAAAAAA, 18)
+	assertLen32(u, 3, "tree after leaf deletion", t)
+
+	u, _ = r.Delete(0x55555555, 1)
+	assertLen32(u, 4, "tree after no deletion", t)
+
+	var i *Node32
+	i = i.InplaceInsert(0xAAAAAAAA, 7, "L1")
+	i = i.InplaceInsert(0xA8AAAAAA, 9, "L2.1")
+	i = i.InplaceInsert(0xABAAAAAA, 9, "L2.2")
+	i = i.InplaceInsert(0xAAAAAAAA, 18, "L3")
+	i = i.InplaceInsert(0xAAAAAAAA, 7, "L1.1")
+	i = i.InplaceInsert(0xAAAAAAAA, 6, "L0")
+	i = i.InplaceInsert(0x54000000, 6, "L0.1")
+	assertLen32(i, 6, "inplace tree", t)
+
+	m := r.Merge(i, func(key uint32, bits int, a, b interface{}) interface{} { return a })
+	assertLen32(m, 6, "merged tree", t)
+
+	b, err := m.AppendBinary(nil, func(b []byte, v interface{}) ([]byte, error) { return b, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, _, err := DecodeNode32(b, func(b []byte) (interface{}, int, error) { return nil, 0, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertLen32(d, 6, "decoded tree", t)
+}
+
+func TestStats32(t *testing.T) {
+	var r *Node32
+	if s := r.Stats(); s != (Stats{}) {
+		t.Errorf("Expected empty stats for empty tree but got %#v", s)
+	}
+
+	r = r.Insert(0xAAAAAAAA, 7, "L1")
+	r = r.Insert(0xA8AAAAAA, 9, "L2.1")
+	r = r.Insert(0xABAAAAAA, 9, "L2.2")
+	r = r.Insert(0xAAAAAAAA, 18, "L3")
+
+	e := Stats{Nodes: 5, Leaves: 4, MaxDepth: 3, Bytes: 5 * int(unsafe.Sizeof(Node32{}))}
+	if s := r.Stats(); s != e {
+		t.Errorf("Expected %#v but got %#v", e, s)
+	}
+}
+
+func TestLen64(t *testing.T) {
+	var r *Node64
+	assertLen64(r, 0, "empty tree", t)
+
+	r = r.Insert(0xAAAAAAAAAAAAAAAA, 7, "L1")
+	r = r.Insert(0xA8AAAAAAAAAAAAAA, 9, "L2.1")
+	r = r.Insert(0xABAAAAAAAAAAAAAA, 9, "L2.2")
+	r = r.Insert(0xAAAAAAAAAAAAAAAA, 18, "L3")
+	r = r.Insert(0xAAAAAAAAAAAAAAAA, 7, "L1.1")
+	assertLen64(r, 4, "tree", t)
+
+	u, _ := r.Delete(0xABAAAAAAAAAAAAAA, 8)
+	assertLen64(u, 3, "tree after deletion", t)
+	assertLen64(r, 4, "tree before deletion", t)
+
+	u, _ = r.Delete(0xAAAAAAAAAAAAAAAA, 18)
+	assertLen64(u, 3, "tree after leaf deletion", t)
+
+	u, _ = r.Delete(0x5555555555555555, 1)
+	assertLen64(u, 4, "tree after no deletion", t)
+
+	var i *Node64
+	i = i.InplaceInsert(0xAAAAAAAAAAAAAAAA, 7, "L1")
+	i = i.InplaceInsert(0xA8AAAAAAAAAAAAAA, 9, "L2.1")
+	i = i.InplaceInsert(0xABAAAAAAAAAAAAAA, 9, "L2.2")
+	i = i.InplaceInsert(0xAAAAAAAAAAAAAAAA, 18, "L3")
+	i = i.InplaceInsert(0xAAAAAAAAAAAAAAAA, 7, "L1.1")
+	i = i.InplaceInsert(0xAAAAAAAAAAAAAAAA, 6, "L0")
+	i = i.InplaceInsert(0x5400000000000000, 6, "L0.1")
+	assertLen64(i, 6, "inplace tree", t)
+
+	m := r.Merge(i, func(key uint64, bits int, a, b interface{}) interface{} { return a })
+	assertLen64(m, 6, "merged tree", t)
+
+	b, err := m.AppendBinary(nil, func(b []byte, v interface{}) ([]byte, error) { return b, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, _, err := DecodeNode64(b, func(b []byte) (interface{}, int, error) { return nil, 0, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertLen64(d, 6, "decoded tree", t)
+}
+
+func TestStats64(t *testing.T) {
+	var r *Node64
+	if s := r.Stats(); s != (Stats{}) {
+		t.Errorf("Expected empty stats for empty tree but got %#v", s)
+	}
+
+	r = r.Insert(0xAAAAAAAAAAAAAAAA, 7, "L1")
+	r = r.Insert(0xA8AAAAAAAAAAAAAA, 9, "L2.1")
+	r = r.Insert(0xABAAAAAAAAAAAAAA, 9, "L2.2")
+	r = r.Insert(0xAAAAAAAAAAAAAAAA, 18, "L3")
+
+	e := Stats{Nodes: 5, Leaves: 4, MaxDepth: 3, Bytes: 5 * int(unsafe.Sizeof(Node64{}))}
+	if s := r.Stats(); s != e {
+		t.Errorf("Expected %#v but got %#v", e, s)
+	}
+}
+
+type weighted int
+
+func (w *weighted) Weight() weight.Int {
+	return weight.Int(*w)
+}
+
+type hits int
+
+func (h *hits) Count() int {
+	return int(*h)
+}
+
+func TestLenWithWeigher(t *testing.T) {
+	var r32 *Node32Of[weighted]
+	r32 = r32.Insert(0xAA000000, 8, 3)
+	r32 = r32.Insert(0xAAAA0000, 16, 5)
+	r32 = r32.InplaceInsert(0xAB000000, 8, 2)
+	r32 = r32.InplaceInsert(0xAAAA0000, 16, 1)
+	if l := r32.Len(); l != 6 {
+		t.Errorf("Expected Len 6 for weighted 32-bit tree but got %d", l)
+	}
+
+	r32, _ = r32.Delete(0xAA000000, 8)
+	if l := r32.Len(); l != 2 {
+		t.Errorf("Expected Len 2 for weighted 32-bit tree after deletion but got %d", l)
+	}
+
+	var r64 *Node64Of[weighted]
+	r64 = r64.Insert(0xAA00000000000000, 8, 3)
+	r64 = r64.InplaceInsert(0xAAAA000000000000, 16, 5)
+	r64 = r64.InplaceInsert(0xAB00000000000000, 8, 2)
+	r64 = r64.InplaceInsert(0xAAAA000000000000, 16, 1)
+	if l := r64.Len(); l != 6 {
+		t.Errorf("Expected Len 6 for weighted 64-bit tree but got %d", l)
+	}
+
+	m := r64.Merge((*Node64Of[weighted])(nil).Insert(0xAAAA000000000000, 16, 4), func(key uint64, bits int, a, b weighted) weighted {
+		return a + b
+	})
+	if l := m.Len(); l != 10 {
+		t.Errorf("Expected Len 10 for merged weighted 64-bit tree but got %d", l)
+	}
+}
+
+func TestLenWithCountMethod(t *testing.T) {
+	var r32 *Node32Of[hits]
+	r32 = r32.Insert(0xAA000000, 8, 100)
+	r32 = r32.InplaceInsert(0xAAAA0000, 16, 5)
+	if l := r32.Len(); l != 2 {
+		t.Errorf("Expected Len 2 for 32-bit tree with values having Count method but got %d", l)
+	}
+
+	var r64 *Node64Of[hits]
+	r64 = r64.Insert(0xAA00000000000000, 8, 100)
+	if l := r64.Len(); l != 1 {
+		t.Errorf("Expected Len 1 for 64-bit tree with value having Count method but got %d", l)
+	}
+}
+
+func assertLen32(r *Node32, e int, desc string, t *testing.T) {
+	t.Helper()
+
+	n := 0
+	for range r.All() {
+		n++
+	}
+
+	if n != e {
+		t.Errorf("Expected %d leaves in %s but got %d", e, desc, n)
+	}
+
+	if l := r.Len(); l != e {
+		t.Errorf("Expected Len %d for %s but got %d", e, desc, l)
+	}
+}
+
+func assertLen64(r *Node64, e int, desc string, t *testing.T) {
+	t.Helper()
+
+	n := 0
+	for range r.All() {
+		n++
+	}
+
+	if n != e {
+		t.Errorf("Expected %d leaves in %s but got %d", e, desc, n)
+	}
+
+	if l := r.Len(); l != e {
+		t.Errorf("Expected Len %d for %s but got %d", e, desc, l)
+	}
+}
@@ -1,26 +1,32 @@
 package strtree
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+)
 
 const (
 	dirLeft = iota
 	dirRight
 )
 
-type node struct {
-	key   string
-	value interface{}
+type node[K, V any] struct {
+	key   K
+	value V
 
-	chld [2]*node
+	chld [2]*node[K, V]
 	red  bool
+
+	// dirty marks node which has been created or changed by current operation so its aggregate needs update.
+	dirty bool
 }
 
-func (n *node) dot() string {
+func (n *node[K, V]) dot() string {
 	body := ""
 
 	// Iterate all nodes using breadth-first search algorithm.
 	i := 0
-	queue := []*node{n}
+	queue := []*node[K, V]{n}
 	for len(queue) > 0 {
 		n := queue[0]
 		body += fmt.Sprintf("N%d %s\n", i, n.dotString())
@@ -38,14 +44,14 @@ func (n *node) dot() string {
 	return body
 }
 
-func (n *node) dotString() string {
+func (n *node[K, V]) dotString() string {
 	if n == nil {
 		return "[label=\"nil\" style=filled fontcolor=white fillcolor=black]"
 	}
 
-	k := fmt.Sprintf("%q", n.key)
-	if n.value != nil {
-		v := fmt.Sprintf("%q", fmt.Sprintf("%#v", n.value))
+	k := fmt.Sprintf("%q", fmt.Sprint(n.key))
+	if any(n.value) != nil {
+		v := fmt.Sprintf("%q", formatValue(n.value))
 		k = fmt.Sprintf("\"k: \\\"%s\\\" v: \\\"%s\\\"\"", k[1:len(k)-1], v[1:len(v)-1])
 	}
 
@@ -57,28 +63,37 @@ func (n *node) dotString() string {
 	return fmt.Sprintf("[label=%s style=filled %s]", k, color)
 }
 
-func (n *node) insert(key string, value interface{}, compare Compare) *node {
+func formatValue[V any](v V) string {
+	if reflect.TypeOf((*V)(nil)).Elem().Kind() == reflect.Interface {
+		return fmt.Sprintf("%#v", v)
+	}
+
+	return fmt.Sprint(v)
+}
+
+func (n *node[K, V]) insert(key K, value V, compare CompareOf[K]) (*node[K, V], bool) {
 	if n == nil {
-		return &node{key: key, value: value}
+		return &node[K, V]{key: key, value: value, dirty: true}, true
 	}
 
 	// Using fake root to get rid of corner cases with rotation right under the root.
-	root := &node{chld: [2]*node{nil, n}}
+	root := &node[K, V]{chld: [2]*node[K, V]{nil, n}}
 	dir := dirLeft
+	added := false
 
 	// Nodes down the path to current node. All these nodes are copies of nodes from tree.
 	var (
 		// Grandparent's parent.
-		gp *node
+		gp *node[K, V]
 
 		// Grandparent.
-		g *node
+		g *node[K, V]
 
 		// Parent.
-		p *node
+		p *node[K, V]
 
 		// Childern.
-		c [2]*node
+		c [2]*node[K, V]
 	)
 
 	// Start with fake root.
@@ -104,11 +119,13 @@ func (n *node) insert(key string, value interface{}, compare Compare) *node {
 
 		if n == nil {
 			// If no child in the direction we go insert new red node.
-			n = &node{
-				key: key,
-				red: true}
+			added = true
+			n = &node[K, V]{
+				key:   key,
+				red:   true,
+				dirty: true}
 
-			c = [2]*node{nil, nil}
+			c = [2]*node[K, V]{nil, nil}
 		} else {
 			// Make copy of current node or just use copy of child node if it has been made during color flip.
 			if n != c[dir] {
@@ -118,12 +135,12 @@ func (n *node) insert(key string, value interface{}, compare Compare) *node {
 			// Color flip case to maintain invariant that the current node is black and has at least one black child.
 			if n.chld[dirLeft] != nil && n.chld[dirRight] != nil && n.chld[dirLeft].red && n.chld[dirRight].red {
 				n.red = true
-				c = [2]*node{
+				c = [2]*node[K, V]{
 					n.chld[dirLeft].colorCopy(false),
 					n.chld[dirRight].colorCopy(false)}
 				n.chld = c
 			} else {
-				c = [2]*node{nil, nil}
+				c = [2]*node[K, V]{nil, nil}
 			}
 		}
 		p.chld[dir] = n
@@ -169,21 +186,22 @@ func (n *node) insert(key string, value interface{}, compare Compare) *node {
 
 	n = root.chld[dirRight]
 	n.red = false
-	return n
+	return n, added
 }
 
-func (n *node) inplaceInsert(key string, value interface{}, compare Compare) *node {
+func (n *node[K, V]) inplaceInsert(key K, value V, compare CompareOf[K]) (*node[K, V], bool) {
 	if n == nil {
-		return &node{key: key, value: value}
+		return &node[K, V]{key: key, value: value, dirty: true}, true
 	}
 
-	root := &node{chld: [2]*node{nil, n}}
+	root := &node[K, V]{chld: [2]*node[K, V]{nil, n}}
 	dir := dirLeft
+	added := false
 
 	var (
-		gp *node
-		g  *node
-		p  *node
+		gp *node[K, V]
+		g  *node[K, V]
+		p  *node[K, V]
 	)
 
 	n = root
@@ -202,12 +220,15 @@ func (n *node) inplaceInsert(key string, value interface{}, compare Compare) *no
 		n = n.chld[dir]
 
 		if n == nil {
-			n = &node{
-				key: key,
-				red: true}
+			added = true
+			n = &node[K, V]{
+				key:   key,
+				red:   true,
+				dirty: true}
 
 			p.chld[dir] = n
 		} else {
+			n.dirty = true
 			if n.chld[dirLeft] != nil && n.chld[dirRight] != nil && n.chld[dirLeft].red && n.chld[dirRight].red {
 				n.red = true
 				n.chld[dirLeft].red = false
@@ -237,26 +258,46 @@ func (n *node) inplaceInsert(key string, value interface{}, compare Compare) *no
 
 	n = root.chld[dirRight]
 	n.red = false
-	return n
+	return n, added
 }
 
-func (n *node) fullCopy() *node {
-	return &node{
+func (n *node[K, V]) fullCopy() *node[K, V] {
+	return &node[K, V]{
 		key:   n.key,
 		value: n.value,
 		chld:  n.chld,
-		red:   n.red}
+		red:   n.red,
+		dirty: true}
 }
 
-func (n *node) colorCopy(color bool) *node {
-	return &node{
+func (n *node[K, V]) colorCopy(color bool) *node[K, V] {
+	return &node[K, V]{
 		key:   n.key,
 		value: n.value,
 		chld:  n.chld,
-		red:   color}
+		red:   color,
+		dirty: true}
+}
+
+// fix updates aggregates of nodes marked as dirty. Path copying guarantees that all changed nodes are reachable from the root through other changed nodes so clean subtrees are skipped.
+func (n *node[K, V]) fix(augment AugmentOf[K, V]) {
+	if n == nil || !n.dirty {
+		return
+	}
+
+	var c [2]*V
+	for i, m := range n.chld {
+		if m != nil {
+			m.fix(augment)
+			c[i] = &m.value
+		}
+	}
+
+	n.value = augment(n.key, n.value, c[dirLeft], c[dirRight])
+	n.dirty = false
 }
 
-func (n *node) single(dir int) *node {
+func (n *node[K, V]) single(dir int) *node[K, V] {
 	nDir := 1 - dir
 	s := n.chld[dir]
 	n.chld[dir] = s.chld[nDir]
@@ -268,12 +309,12 @@ func (n *node) single(dir int) *node {
 	return s
 }
 
-func (n *node) double(dir int) *node {
+func (n *node[K, V]) double(dir int) *node[K, V] {
 	n.chld[dir] = n.chld[dir].single(1 - dir)
 	return n.single(dir)
 }
 
-func (n *node) get(key string, compare Compare) (interface{}, bool) {
+func (n *node[K, V]) get(key K, compare CompareOf[K]) (V, bool) {
 	for n != nil {
 		r := compare(n.key, key)
 
@@ -289,35 +330,44 @@ func (n *node) get(key string, compare Compare) (interface{}, bool) {
 		n = n.chld[dir]
 	}
 
-	return nil, false
+	var v V
+	return v, false
 }
 
-func (n *node) enumerate(ch chan Pair) {
+func (n *node[K, V]) walk(f func(K, V) bool) bool {
 	if n == nil {
-		return
+		return true
 	}
 
-	n.chld[dirLeft].enumerate(ch)
+	return n.chld[dirLeft].walk(f) &&
+		f(n.key, n.value) &&
+		n.chld[dirRight].walk(f)
+}
 
-	ch <- Pair{Key: n.key, Value: n.value}
+func (n *node[K, V]) walkPruned(skip func(V) bool, f func(K, V) bool) bool {
+	if n == nil || skip(n.value) {
+		return true
+	}
 
-	n.chld[dirRight].enumerate(ch)
+	return n.chld[dirLeft].walkPruned(skip, f) &&
+		f(n.key, n.value) &&
+		n.chld[dirRight].walkPruned(skip, f)
 }
 
-func (n *node) del(key string, compare Compare) (*node, bool) {
+func (n *node[K, V]) del(key K, compare CompareOf[K]) (*node[K, V], bool) {
 	// Fake root.
-	root := &node{chld: [2]*node{nil, n}}
+	root := &node[K, V]{chld: [2]*node[K, V]{nil, n}}
 
 	// Nodes down the path to current node.
 	var (
 		// Grandparent.
-		g *node
+		g *node[K, V]
 
 		// Parent.
-		p *node
+		p *node[K, V]
 
 		// Target node.
-		t *node
+		t *node[K, V]
 	)
 
 	n = root
@@ -408,3 +458,21 @@ func (n *node) del(key string, compare Compare) (*node, bool) {
 	}
 	return n, t != nil
 }
+
+func (n *node[K, V]) stats(depth int, s *Stats) {
+	if n == nil {
+		return
+	}
+
+	s.Nodes++
+	if n.chld[dirLeft] == nil && n.chld[dirRight] == nil {
+		s.Childless++
+	}
+
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	n.chld[dirLeft].stats(depth+1, s)
+	n.chld[dirRight].stats(depth+1, s)
+}
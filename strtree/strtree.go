@@ -1,21 +1,53 @@
-// Package strtree implements red-black tree for key value pairs with string keys and custom comparison.
+// Package strtree implements red-black tree for key value pairs with ordered keys and custom comparison.
 package strtree
 
-import "strings"
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"strings"
+	"unsafe"
+)
+
+// CompareOf defines function interface for custom comparison of keys of type K. Function implementing the interface should return value less than zero if its first argument precedes second one, zero if both are equal and positive if the second precedes.
+type CompareOf[K any] func(a, b K) int
+
+// AugmentOf defines function interface for recomputation of per-node aggregate kept in value. Function implementing the interface gets key and value of a node and pointers to values of its children (nil if there is no such child) which already have their aggregates updated. It should return the value with aggregate for the subtree rooted at the node and must not change values of the children.
+type AugmentOf[K, V any] func(key K, value V, left, right *V) V
 
 // Compare defines function interface for custom comparison. Function implementing the interface should return value less than zero if its first argument precedes second one, zero if both are equal and positive if the second precedes.
 type Compare func(a, b string) int
 
+// TreeOf is a red-black tree for key-value pairs where key has type K and value has type V.
+type TreeOf[K, V any] struct {
+	root    *node[K, V]
+	compare CompareOf[K]
+	augment AugmentOf[K, V]
+	count   int
+}
+
 // Tree is a red-black tree for key-value pairs where key is string.
-type Tree struct {
-	root    *node
-	compare Compare
+type Tree = TreeOf[string, interface{}]
+
+// PairOf is a key-value pair representing tree node content.
+type PairOf[K, V any] struct {
+	Key   K
+	Value V
 }
 
 // Pair is a key-value pair representing tree node content.
-type Pair struct {
-	Key   string
-	Value interface{}
+type Pair = PairOf[string, interface{}]
+
+// Stats holds tree statistics returned by Stats method.
+type Stats struct {
+	// Nodes is a number of all nodes in the tree.
+	Nodes int
+	// Childless is a number of nodes without children. Every node of red-black tree holds a key-value pair so the number of pairs is equal to Nodes unlike Leaves of radix trees.
+	Childless int
+	// MaxDepth is a number of nodes on the longest path from the root.
+	MaxDepth int
+	// Bytes is an estimated memory size of the nodes. It doesn't include memory referenced by keys and values.
+	Bytes int
 }
 
 // NewTree creates empty tree with default comparison operation (strings.Compare).
@@ -25,75 +57,167 @@ func NewTree() *Tree {
 
 // NewTreeWithCustomComparison creates empty tree with given comparison operation.
 func NewTreeWithCustomComparison(compare Compare) *Tree {
-	return &Tree{compare: compare}
+	return &Tree{compare: CompareOf[string](compare)}
+}
+
+// NewTreeOf creates empty tree with default comparison operation (cmp.Compare) for ordered keys.
+func NewTreeOf[K cmp.Ordered, V any]() *TreeOf[K, V] {
+	return &TreeOf[K, V]{compare: cmp.Compare[K]}
 }
 
-// Insert puts given key-value pair to the tree and returns pointer to new root.
-func (t *Tree) Insert(key string, value interface{}) *Tree {
+// NewTreeOfWithCustomComparison creates empty tree with given comparison operation.
+func NewTreeOfWithCustomComparison[K, V any](compare CompareOf[K]) *TreeOf[K, V] {
+	return &TreeOf[K, V]{compare: compare}
+}
+
+// NewTreeOfWithAugmentation creates empty tree with given comparison operation and augmentation. The tree calls augment for every node created or changed by insertion or deletion including nodes moved by rotations so each value can keep aggregate of its subtree (for example the greatest end of intervals).
+func NewTreeOfWithAugmentation[K, V any](compare CompareOf[K], augment AugmentOf[K, V]) *TreeOf[K, V] {
+	return &TreeOf[K, V]{compare: compare, augment: augment}
+}
+
+// Insert puts given key-value pair to the tree and returns pointer to new root. Nil tree with string keys (like Tree) gets default comparison (strings.Compare) while for other keys the method panics so trees with such keys should be created by NewTreeOf or NewTreeOfWithCustomComparison.
+func (t *TreeOf[K, V]) Insert(key K, value V) *TreeOf[K, V] {
 	var (
-		n *node
-		c Compare
+		n     *node[K, V]
+		c     CompareOf[K]
+		a     AugmentOf[K, V]
+		count int
 	)
 
 	if t == nil {
-		c = strings.Compare
+		var ok bool
+		if c, ok = defaultCompare[K](); !ok {
+			panic(fmt.Errorf("no default comparison for keys of %T, create the tree with NewTreeOf or NewTreeOfWithCustomComparison", t))
+		}
 	} else {
 		n = t.root
 		c = t.compare
+		a = t.augment
+		count = t.count
+	}
+
+	root, ok := n.insert(key, value, c)
+	if ok {
+		count++
+	}
+
+	if a != nil {
+		root.fix(a)
 	}
 
-	return &Tree{root: n.insert(key, value, c), compare: c}
+	return &TreeOf[K, V]{root: root, compare: c, augment: a, count: count}
 }
 
 // InplaceInsert inserts or replaces given key-value pair in the tree. The method inserts data directly to current tree so make sure you have exclusive access to it.
-func (t *Tree) InplaceInsert(key string, value interface{}) {
-	t.root = t.root.inplaceInsert(key, value, t.compare)
+func (t *TreeOf[K, V]) InplaceInsert(key K, value V) {
+	var ok bool
+	if t.root, ok = t.root.inplaceInsert(key, value, t.compare); ok {
+		t.count++
+	}
+
+	if t.augment != nil {
+		t.root.fix(t.augment)
+	}
 }
 
 // Get returns value by given key.
-func (t *Tree) Get(key string) (interface{}, bool) {
+func (t *TreeOf[K, V]) Get(key K) (V, bool) {
 	if t == nil {
-		return nil, false
+		var v V
+		return v, false
 	}
 
 	return t.root.get(key, t.compare)
 }
 
 // Enumerate returns channel which is populated by key pair values in order of keys.
-func (t *Tree) Enumerate() chan Pair {
-	ch := make(chan Pair)
+func (t *TreeOf[K, V]) Enumerate() chan PairOf[K, V] {
+	ch := make(chan PairOf[K, V])
 
 	go func() {
 		defer close(ch)
 
-		if t == nil {
-			return
+		for k, v := range t.All() {
+			ch <- PairOf[K, V]{Key: k, Value: v}
 		}
-
-		t.root.enumerate(ch)
 	}()
 
 	return ch
 }
 
+// All returns iterator over key-value pairs in order of keys.
+func (t *TreeOf[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		t.Walk(yield)
+	}
+}
+
+// Walk calls f for key-value pairs in order of keys until f returns false. It reports if all the pairs have been visited.
+func (t *TreeOf[K, V]) Walk(f func(K, V) bool) bool {
+	if t == nil {
+		return true
+	}
+
+	return t.root.walk(f)
+}
+
+// WalkPruned calls f for key-value pairs in order of keys until f returns false. It skips whole subtree if skip returns true for value of its root so with augmentation the walk visits only subtrees which aggregates may match. The method reports if f hasn't stopped the walk.
+func (t *TreeOf[K, V]) WalkPruned(skip func(V) bool, f func(K, V) bool) bool {
+	if t == nil {
+		return true
+	}
+
+	return t.root.walkPruned(skip, f)
+}
+
 // Delete removes node by given key. It returns copy of tree and true if node has been indeed deleted otherwise original tree and false.
-func (t *Tree) Delete(key string) (*Tree, bool) {
+func (t *TreeOf[K, V]) Delete(key K) (*TreeOf[K, V], bool) {
 	if t == nil {
 		return nil, false
 	}
 
 	c := t.compare
 	root, ok := t.root.del(key, c)
-	return &Tree{root: root, compare: c}, ok
+
+	count := t.count
+	if ok {
+		count--
+	}
+
+	if t.augment != nil {
+		root.fix(t.augment)
+	}
+
+	return &TreeOf[K, V]{root: root, compare: c, augment: t.augment, count: count}, ok
+}
+
+// Len returns number of key-value pairs in the tree. It takes constant time.
+func (t *TreeOf[K, V]) Len() int {
+	if t == nil {
+		return 0
+	}
+
+	return t.count
+}
+
+// Stats walks the tree and collects its statistics.
+func (t *TreeOf[K, V]) Stats() Stats {
+	var s Stats
+	if t != nil {
+		t.root.stats(1, &s)
+		s.Bytes = s.Nodes * int(unsafe.Sizeof(node[K, V]{}))
+	}
+
+	return s
 }
 
 // IsEmpty returns true if given tree has no nodes.
-func (t *Tree) IsEmpty() bool {
+func (t *TreeOf[K, V]) IsEmpty() bool {
 	return t == nil || t.root == nil
 }
 
 // Dot dumps tree to Graphviz .dot format.
-func (t *Tree) Dot() string {
+func (t *TreeOf[K, V]) Dot() string {
 	body := ""
 
 	if t != nil {
@@ -102,3 +226,9 @@ func (t *Tree) Dot() string {
 
 	return "digraph d {\n" + body + "}\n"
 }
+
+// defaultCompare returns comparison used for insertion to nil tree. Only string keys (as keys of Tree) have such comparison (strings.Compare) and the function reports false for other key types.
+func defaultCompare[K any]() (CompareOf[K], bool) {
+	c, ok := any(strings.Compare).(func(a, b K) int)
+	return c, ok
+}
@@ -1,7 +1,10 @@
 package strtree
 
 import (
+	"cmp"
 	"fmt"
+	"math/rand"
+	"sort"
 	"strings"
 	"testing"
 
@@ -451,6 +454,45 @@ func TestEnumerate(t *testing.T) {
 		"\"4\": \"test-4\"\n")
 }
 
+func TestAll(t *testing.T) {
+	var r *Tree
+
+	for k, v := range r.All() {
+		t.Errorf("Expected no pairs in empty tree but got %q: %#v", k, v)
+	}
+
+	r = NewTree()
+	r = r.Insert("1", "test-1")
+	r = r.Insert("0", "test-0")
+	r = r.Insert("4", "test-4")
+	r = r.Insert("2", "test-2")
+	r = r.Insert("3", "test-3")
+
+	keys := ""
+	for k := range r.All() {
+		keys += k
+		if len(keys) >= 3 {
+			break
+		}
+	}
+
+	if keys != "012" {
+		t.Errorf("Expected \"012\" keys but got %q", keys)
+	}
+
+	keys = ""
+	if r.Walk(func(k string, v interface{}) bool {
+		keys += k
+		return k != "3"
+	}) {
+		t.Error("Expected interrupted walk")
+	}
+
+	if keys != "0123" {
+		t.Errorf("Expected \"0123\" keys but got %q", keys)
+	}
+}
+
 func TestDelete(t *testing.T) {
 	var r *Tree
 
@@ -585,6 +627,60 @@ func TestIsEmpty(t *testing.T) {
 		t.Errorf("Expected empty non-nil tree to be empty")
 	}
 }
+func TestLen(t *testing.T) {
+	var r *Tree
+	assertLen(r, 0, "nil tree", t)
+
+	r = NewTree()
+	assertLen(r, 0, "empty tree", t)
+
+	for _, k := range []string{"0", "1", "2", "3", "4"} {
+		r = r.Insert(k, nil)
+	}
+	assertLen(r, 5, "five nodes tree", t)
+
+	r = r.Insert("2", "replaced")
+	assertLen(r, 5, "five nodes tree after replace", t)
+
+	r.InplaceInsert("5", nil)
+	r.InplaceInsert("0", "replaced")
+	assertLen(r, 6, "six nodes tree after inplace insert", t)
+
+	d, ok := r.Delete("3")
+	if !ok {
+		t.Errorf("Expected element \"3\" to be deleted")
+	}
+	assertLen(d, 5, "tree after delete", t)
+	assertLen(r, 6, "original tree after delete", t)
+
+	d, ok = d.Delete("3")
+	if ok {
+		t.Errorf("Expected element \"3\" to be absent")
+	}
+	assertLen(d, 5, "tree after delete of missing element", t)
+}
+
+func TestStats(t *testing.T) {
+	var r *Tree
+	if s := r.Stats(); s != (Stats{}) {
+		t.Errorf("Expected empty stats for nil tree but got %#v", s)
+	}
+
+	for _, k := range []string{"0", "1", "2", "3", "4", "5", "6"} {
+		r = r.Insert(k, nil)
+	}
+
+	s := r.Stats()
+	if s.Nodes != 7 || s.Childless != 4 || s.MaxDepth != 4 || s.Bytes <= 0 {
+		t.Errorf("Expected 7 nodes, 4 childless nodes and depth 4 but got %#v", s)
+	}
+}
+
+func assertLen(r *Tree, e int, desc string, t *testing.T) {
+	if n := r.Len(); n != e {
+		t.Errorf("Expected %d elements in %s but got %d", e, desc, n)
+	}
+}
 
 const (
 	TestEmptyTree = `digraph d {
@@ -906,6 +1002,190 @@ N0 [label="k: \"00\" v: \"\"test-2\"\"" style=filled fontcolor=white fillcolor=b
 `
 )
 
+func TestTreeOf(t *testing.T) {
+	r := NewTreeOf[int, string]()
+	for _, k := range []int{10, -3, 7, 0, 42} {
+		r = r.Insert(k, fmt.Sprintf("v%d", k))
+	}
+
+	r.InplaceInsert(7, "seven")
+
+	if v, ok := r.Get(7); !ok || v != "seven" {
+		t.Errorf("Expected \"seven\" for 7 but got %q (%v)", v, ok)
+	}
+
+	r, ok := r.Delete(10)
+	if !ok {
+		t.Errorf("Expected 10 to be deleted")
+	}
+
+	assertTreeOfEnumerate(r, "-3: v-3, 0: v0, 7: seven, 42: v42", "int tree", t)
+
+	type port uint16
+
+	p := NewTreeOf[port, bool]().Insert(443, true).Insert(80, true).Insert(8080, false)
+	assertTreeOfEnumerate(p, "80: true, 443: true, 8080: false", "tree with derived key type", t)
+
+	rev := NewTreeOfWithCustomComparison[float64, int](func(a, b float64) int {
+		return cmp.Compare(b, a)
+	})
+	rev = rev.Insert(1.5, 1).Insert(-2, 2).Insert(3.25, 3)
+	assertTreeOfEnumerate(rev, "3.25: 3, 1.5: 1, -2: 2", "tree with reversed comparison", t)
+}
+
+func TestTreeOfDefaultComparison(t *testing.T) {
+	var s *TreeOf[string, int]
+	s = s.Insert("b", 1).Insert("c", 2).Insert("a", 3)
+	assertTreeOfEnumerate(s, "a: 3, b: 1, c: 2", "nil tree with string keys", t)
+
+	type name string
+
+	var n *TreeOf[name, int]
+	assertPanic(func() { n.Insert("a", 1) }, "first insertion of derived string key to nil tree", t)
+
+	n = NewTreeOf[name, int]().Insert("b", 1).Insert("a", 2)
+	assertTreeOfEnumerate(n, "a: 2, b: 1", "tree with derived string keys", t)
+
+	var i *TreeOf[int, string]
+	assertPanic(func() { i.Insert(1, "one") }, "first insertion of integer key to nil tree", t)
+}
+
+type maxValue struct {
+	v   int
+	max int
+}
+
+func augmentMax(key int, value maxValue, left, right *maxValue) maxValue {
+	value.max = value.v
+	for _, c := range []*maxValue{left, right} {
+		if c != nil && c.max > value.max {
+			value.max = c.max
+		}
+	}
+
+	return value
+}
+
+func TestTreeOfWithAugmentation(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	var (
+		r  = NewTreeOfWithAugmentation[int, maxValue](cmp.Compare[int], augmentMax)
+		in = NewTreeOfWithAugmentation[int, maxValue](cmp.Compare[int], augmentMax)
+		m  = map[int]int{}
+	)
+
+	for i := 0; i < 2000; i++ {
+		k := rnd.Intn(500)
+		if rnd.Intn(3) > 0 {
+			v := rnd.Intn(1000)
+			r = r.Insert(k, maxValue{v: v})
+			in.InplaceInsert(k, maxValue{v: v})
+			m[k] = v
+		} else {
+			old := r
+			r, _ = r.Delete(k)
+			in, _ = in.Delete(k)
+			delete(m, k)
+
+			if i%100 == 0 {
+				assertAugmentedInvariants(old.root, "tree before deletion", t)
+			}
+		}
+
+		if i%100 == 0 {
+			assertAugmentedInvariants(r.root, "persistent tree", t)
+			assertAugmentedInvariants(in.root, "inplace tree", t)
+		}
+	}
+
+	keys := []int{}
+	for k, v := range m {
+		if v >= 900 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Ints(keys)
+
+	e := make([]string, len(keys))
+	for i, k := range keys {
+		e[i] = fmt.Sprintf("%d: %d", k, m[k])
+	}
+
+	for _, tr := range []*TreeOf[int, maxValue]{r, in} {
+		visited := 0
+		items := []string{}
+		tr.WalkPruned(func(v maxValue) bool {
+			visited++
+			return v.max < 900
+		}, func(k int, v maxValue) bool {
+			if v.v >= 900 {
+				items = append(items, fmt.Sprintf("%d: %d", k, v.v))
+			}
+
+			return true
+		})
+
+		assertStringLists(items, e, "pruned walk", t)
+		if visited >= 2*tr.Len() {
+			t.Errorf("Expected pruned walk to skip subtrees but it visited %d nodes of %d", visited, tr.Len())
+		}
+	}
+
+	if r.WalkPruned(func(maxValue) bool { return false }, func(int, maxValue) bool { return false }) {
+		t.Error("Expected stopped pruned walk to report it")
+	}
+}
+
+func assertAugmentedInvariants(n *node[int, maxValue], desc string, t *testing.T) int {
+	t.Helper()
+
+	if n == nil {
+		return 1
+	}
+
+	if n.dirty {
+		t.Fatalf("Expected no dirty nodes in %s", desc)
+	}
+
+	m := n.value.v
+	for _, c := range n.chld {
+		if c != nil && c.value.max > m {
+			m = c.value.max
+		}
+
+		if n.red && c != nil && c.red {
+			t.Fatalf("Expected no red child for red node in %s", desc)
+		}
+	}
+
+	if n.value.max != m {
+		t.Fatalf("Expected max %d for %d in %s but got %d", m, n.key, desc, n.value.max)
+	}
+
+	l := assertAugmentedInvariants(n.chld[dirLeft], desc, t)
+	if r := assertAugmentedInvariants(n.chld[dirRight], desc, t); l != r {
+		t.Fatalf("Expected the same black height for %d in %s but got %d and %d", n.key, desc, l, r)
+	}
+
+	if !n.red {
+		l++
+	}
+
+	return l
+}
+
+func assertTreeOfEnumerate[K, V any](r *TreeOf[K, V], e, desc string, t *testing.T) {
+	items := []string{}
+	for p := range r.Enumerate() {
+		items = append(items, fmt.Sprintf("%v: %v", p.Key, p.Value))
+	}
+
+	if s := strings.Join(items, ", "); s != e {
+		t.Errorf("Expected %q for %s but got %q", e, desc, s)
+	}
+}
+
 func assertTree(r *Tree, e, desc string, t *testing.T) {
 	assertStringLists(difflib.SplitLines(r.Dot()), difflib.SplitLines(e), desc, t)
 }
@@ -2,7 +2,12 @@ package domaintree16
 
 // !!!DON'T EDIT!!! Generated by infobloxopen/go-trees/etc from <name>tree{{.bits}} with etc -s uint16 -d uintX.yaml -t ./<name>tree\{\{.bits\}\}
 
-import "github.com/infobloxopen/go-trees/domain"
+import (
+	"iter"
+	"unsafe"
+
+	"github.com/infobloxopen/go-trees/domain"
+)
 
 type labelTree struct {
 	root *node
@@ -69,35 +74,27 @@ func (t *labelTree) rawGet(key string) (*Node, bool) {
 }
 
 func (t *labelTree) enumerate() chan labelPair {
-	ch := make(chan labelPair)
-
-	go func() {
-		defer close(ch)
+	return enumerateLabels(t.all())
+}
 
-		if t == nil {
-			return
+func (t *labelTree) all() iter.Seq2[string, *Node] {
+	return func(yield func(string, *Node) bool) {
+		if t != nil {
+			t.root.walk(yield)
 		}
-
-		t.root.enumerate(ch)
-	}()
-
-	return ch
+	}
 }
 
 func (t *labelTree) rawEnumerate() chan labelPair {
-	ch := make(chan labelPair)
-
-	go func() {
-		defer close(ch)
+	return enumerateLabels(t.rawAll())
+}
 
-		if t == nil {
-			return
+func (t *labelTree) rawAll() iter.Seq2[string, *Node] {
+	return func(yield func(string, *Node) bool) {
+		if t != nil {
+			t.root.rawWalk(yield)
 		}
-
-		t.root.rawEnumerate(ch)
-	}()
-
-	return ch
+	}
 }
 
 func (t *labelTree) del(key string) (*labelTree, bool) {
@@ -123,6 +120,14 @@ func (t *labelTree) isEmpty() bool {
 	return t == nil || t.root == nil
 }
 
+func (t *labelTree) bytes() int {
+	if t == nil {
+		return 0
+	}
+
+	return int(unsafe.Sizeof(*t)) + t.root.bytes()
+}
+
 func (t *labelTree) dot() string {
 	body := ""
 
@@ -132,3 +137,17 @@ func (t *labelTree) dot() string {
 
 	return "digraph d {\n" + body + "}\n"
 }
+
+func enumerateLabels(seq iter.Seq2[string, *Node]) chan labelPair {
+	ch := make(chan labelPair)
+
+	go func() {
+		defer close(ch)
+
+		for k, v := range seq {
+			ch <- labelPair{Key: k, Value: v}
+		}
+	}()
+
+	return ch
+}
@@ -5,6 +5,8 @@ package domaintree16
 
 import (
 	"errors"
+	"iter"
+	"unsafe"
 
 	"github.com/infobloxopen/go-trees/domain"
 )
@@ -15,6 +17,8 @@ type Node struct {
 
 	hasValue bool
 	value    uint16
+
+	count int
 }
 
 // Pair represents a key-value pair returned by Enumerate method.
@@ -23,14 +27,32 @@ type Pair struct {
 	Value uint16
 }
 
+// Stats holds tree statistics returned by Stats method.
+type Stats struct {
+	// Nodes is a number of all nodes in the tree (one node per domain label).
+	Nodes int
+	// Leaves is a number of nodes which hold values.
+	Leaves int
+	// MaxDepth is a number of nodes on the longest path from the root (root itself has depth 1).
+	MaxDepth int
+	// Bytes is an estimated memory size of the nodes and their label trees.
+	Bytes int
+}
+
 var errStopIterations = errors.New("stop iterations")
 
 // Insert puts value using given domain as a key. The method returns new tree (old one remains unaffected).
 func (n *Node) Insert(d domain.Name, v uint16) *Node {
+	var path [domain.MaxLabels + 1]*Node
+
 	n = n.copy()
 	r := n
 
+	i := 0
 	d.GetLabels(func(label string) error {
+		path[i] = n
+		i++
+
 		next, ok := n.branches.rawGet(label)
 		if ok {
 			next = next.copy()
@@ -44,9 +66,7 @@ func (n *Node) Insert(d domain.Name, v uint16) *Node {
 		return nil
 	})
 
-	n.hasValue = true
-	n.value = v
-
+	addCount(path[:i], n, n.set(v))
 	return r
 }
 
@@ -56,7 +76,13 @@ func (n *Node) InplaceInsert(d domain.Name, v uint16) {
 		n.branches = newLabelTree()
 	}
 
+	var path [domain.MaxLabels + 1]*Node
+
+	i := 0
 	d.GetLabels(func(label string) error {
+		path[i] = n
+		i++
+
 		next, ok := n.branches.rawGet(label)
 		if ok {
 			n = next
@@ -69,8 +95,7 @@ func (n *Node) InplaceInsert(d domain.Name, v uint16) {
 		return nil
 	})
 
-	n.hasValue = true
-	n.value = v
+	addCount(path[:i], n, n.set(v))
 }
 
 // Enumerate returns key-value pairs in given tree. It lists domains in the same order for the same tree.
@@ -79,12 +104,27 @@ func (n *Node) Enumerate() chan Pair {
 
 	go func() {
 		defer close(ch)
-		n.enumerate("", ch)
+
+		for k, v := range n.All() {
+			ch <- Pair{Key: k, Value: v}
+		}
 	}()
 
 	return ch
 }
 
+// All returns iterator over key-value pairs in given tree. It lists domains in the same order as Enumerate.
+func (n *Node) All() iter.Seq2[string, uint16] {
+	return func(yield func(string, uint16) bool) {
+		n.Walk(yield)
+	}
+}
+
+// Walk calls f for key-value pairs in given tree until f returns false. It lists domains in the same order as Enumerate and reports if all the pairs have been visited.
+func (n *Node) Walk(f func(string, uint16) bool) bool {
+	return n.walk("", f)
+}
+
 // Get gets value for domain which is equal to domain in the tree or is a subdomain of existing domain.
 func (n *Node) Get(d domain.Name) (uint16, bool) {
 	if n == nil {
@@ -111,6 +151,38 @@ func (n *Node) Get(d domain.Name) (uint16, bool) {
 	return value, hasValue
 }
 
+// GetWithName gets value for domain in the same way as Get. Additionally it returns domain of the matched entry (which is the domain itself or one of its parents) and number of labels in the matched domain.
+func (n *Node) GetWithName(d domain.Name) (uint16, domain.Name, int, bool) {
+	if n == nil {
+		return 0, domain.Name{}, 0, false
+	}
+
+	var value uint16
+	depth := 0
+
+	i := 0
+	d.GetLabels(func(label string) error {
+		next, ok := n.branches.rawGet(label)
+		if !ok {
+			return errStopIterations
+		}
+
+		n = next
+		i++
+		if n.hasValue {
+			value = n.value
+			depth = i
+		}
+		return nil
+	})
+
+	if depth <= 0 {
+		return 0, domain.Name{}, 0, false
+	}
+
+	return value, d.Suffix(depth), depth, true
+}
+
 // DeleteSubdomains removes current domain and all its subdomains if any. It returns new tree and flag if deletion indeed occurs.
 func (n *Node) DeleteSubdomains(d domain.Name) (*Node, bool) {
 	if n == nil {
@@ -132,8 +204,11 @@ func (n *Node) DeleteSubdomains(d domain.Name) (*Node, bool) {
 		return new(Node), true
 	}
 
+	c := nodes[i-1].count
+
 	n = nodes[i].copy()
 	n.branches, _ = n.branches.rawDel(labels[i])
+	n.count -= c
 	i++
 
 	return n.copyBranch(labels[i:], nodes[i:]), true
@@ -164,38 +239,78 @@ func (n *Node) Delete(d domain.Name) (*Node, bool) {
 			return new(Node), true
 		}
 
-		return &Node{branches: branches}, true
+		return &Node{branches: branches, count: n.count - 1}, true
 	}
 
+	c := n.count - 1
+
 	n = nodes[i].copy()
 	if branches.isEmpty() {
 		n.branches, _ = n.branches.rawDel(labels[i])
 	} else {
-		n.branches = n.branches.rawInsert(labels[i], &Node{branches: branches})
+		n.branches = n.branches.rawInsert(labels[i], &Node{branches: branches, count: c})
 	}
+	n.count--
 	i++
 
 	return n.copyBranch(labels[i:], nodes[i:]), true
 }
 
-func (n *Node) enumerate(s string, ch chan Pair) {
+// Len returns number of domains in the tree. It takes constant time.
+func (n *Node) Len() int {
 	if n == nil {
-		return
+		return 0
 	}
 
-	if n.hasValue {
-		ch <- Pair{
-			Key:   s,
-			Value: n.value}
+	return n.count
+}
+
+// Stats walks the tree and collects its statistics.
+func (n *Node) Stats() Stats {
+	var s Stats
+	n.stats(1, &s)
+	return s
+}
+
+func (n *Node) walk(s string, f func(string, uint16) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if n.hasValue && !f(s, n.value) {
+		return false
 	}
 
-	for item := range n.branches.enumerate() {
-		sub := item.Key
+	for k, v := range n.branches.all() {
 		if len(s) > 0 {
-			sub += "." + s
+			k += "." + s
+		}
+
+		if !v.walk(k, f) {
+			return false
 		}
+	}
+
+	return true
+}
+
+func (n *Node) stats(depth int, s *Stats) {
+	if n == nil {
+		return
+	}
 
-		item.Value.enumerate(sub, ch)
+	s.Nodes++
+	if n.hasValue {
+		s.Leaves++
+	}
+
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	s.Bytes += int(unsafe.Sizeof(*n)) + n.branches.bytes()
+	for _, c := range n.branches.rawAll() {
+		c.stats(depth+1, s)
 	}
 }
 
@@ -208,9 +323,22 @@ func (n *Node) copy() *Node {
 		branches: n.branches,
 		hasValue: n.hasValue,
 		value:    n.value,
+		count:    n.count,
 	}
 }
 
+// set puts value to the node and returns change in number of domains in the tree.
+func (n *Node) set(v uint16) int {
+	c := 1
+	if n.hasValue {
+		c = 0
+	}
+
+	n.hasValue = true
+	n.value = v
+	return c
+}
+
 func (n *Node) getBranch(d domain.Name, labels []string, nodes []*Node) int {
 	i := len(labels) - 1
 	nodes[i] = n
@@ -237,7 +365,10 @@ func (n *Node) getBranch(d domain.Name, labels []string, nodes []*Node) int {
 
 func (n *Node) copyBranch(labels []string, nodes []*Node) *Node {
 	for i, p := range nodes {
+		old, _ := p.branches.rawGet(labels[i])
+
 		p = p.copy()
+		p.count += n.Len() - old.Len()
 		if !n.hasValue && n.branches.isEmpty() {
 			p.branches, _ = p.branches.rawDel(labels[i])
 		} else {
@@ -249,3 +380,15 @@ func (n *Node) copyBranch(labels []string, nodes []*Node) *Node {
 
 	return n
 }
+
+func addCount(path []*Node, n *Node, c int) {
+	if c == 0 {
+		return
+	}
+
+	for _, p := range path {
+		p.count += c
+	}
+
+	n.count += c
+}
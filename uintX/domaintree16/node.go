@@ -5,6 +5,7 @@ package domaintree16
 import (
 	"fmt"
 	"strings"
+	"unsafe"
 
 	"github.com/infobloxopen/go-trees/domain"
 )
@@ -306,28 +307,32 @@ func (n *node) get(key string) (*Node, bool) {
 	return nil, false
 }
 
-func (n *node) enumerate(ch chan labelPair) {
+func (n *node) walk(f func(string, *Node) bool) bool {
 	if n == nil {
-		return
+		return true
 	}
 
-	n.chld[dirLeft].enumerate(ch)
-
-	ch <- labelPair{Key: domain.MakeHumanReadableLabel(n.key), Value: n.value}
-
-	n.chld[dirRight].enumerate(ch)
+	return n.chld[dirLeft].walk(f) &&
+		f(domain.MakeHumanReadableLabel(n.key), n.value) &&
+		n.chld[dirRight].walk(f)
 }
 
-func (n *node) rawEnumerate(ch chan labelPair) {
+func (n *node) rawWalk(f func(string, *Node) bool) bool {
 	if n == nil {
-		return
+		return true
 	}
 
-	n.chld[dirLeft].rawEnumerate(ch)
+	return n.chld[dirLeft].rawWalk(f) &&
+		f(n.key, n.value) &&
+		n.chld[dirRight].rawWalk(f)
+}
 
-	ch <- labelPair{Key: n.key, Value: n.value}
+func (n *node) bytes() int {
+	if n == nil {
+		return 0
+	}
 
-	n.chld[dirRight].rawEnumerate(ch)
+	return int(unsafe.Sizeof(*n)) + n.chld[dirLeft].bytes() + n.chld[dirRight].bytes()
 }
 
 func (n *node) del(key string) (*node, bool) {
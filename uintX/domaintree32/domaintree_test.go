@@ -144,6 +144,25 @@ func TestGet(t *testing.T) {
 	assertValue(v, ok, 6, true, "fetching \"yet.another.test.com\" from tree", t)
 }
 
+func TestGetWithName(t *testing.T) {
+	var r *Node
+
+	v, n, depth, ok := r.GetWithName(makeTestDN(t, "test.com"))
+	assertValue(v, ok, 0, false, "fetching from empty tree", t)
+	if depth != 0 {
+		t.Errorf("Expected no matched domain for empty tree but got %q (%d)", n, depth)
+	}
+
+	r = r.Insert(makeTestDN(t, "com"), 1)
+	r = r.Insert(makeTestDN(t, "test.com"), 2)
+	r = r.Insert(makeTestDN(t, "www.test.com"), 3)
+
+	assertValueWithName(r, "Ns.Test.Com.", 2, "Test.Com.", 2, t)
+	assertValueWithName(r, "www.test.com", 3, "www.test.com", 3, t)
+	assertValueWithName(r, "example.com", 1, "com", 1, t)
+	assertValueWithName(r, "test.org", 0, "", 0, t)
+}
+
 func TestDeleteSubdomains(t *testing.T) {
 	var r *Node
 
@@ -329,6 +348,62 @@ func makeTestDN(t *testing.T, s string) domain.Name {
 	return d
 }
 
+func TestLenAndStats(t *testing.T) {
+	var r *Node
+	if n := r.Len(); n != 0 {
+		t.Errorf("Expected no domains in empty tree but got %d", n)
+	}
+
+	if s := r.Stats(); s != (Stats{}) {
+		t.Errorf("Expected empty stats for empty tree but got %#v", s)
+	}
+
+	r = r.Insert(makeTestDN(t, "com"), 1)
+	r = r.Insert(makeTestDN(t, "test.com"), 2)
+	r = r.Insert(makeTestDN(t, "www.test.com"), 3)
+	r = r.Insert(makeTestDN(t, "test.net"), 4)
+	if n := r.Len(); n != 4 {
+		t.Errorf("Expected 4 domains but got %d", n)
+	}
+
+	s := r.Stats()
+	if s.Nodes != 6 || s.Leaves != 4 || s.MaxDepth != 4 || s.Bytes <= 0 {
+		t.Errorf("Expected 6 nodes, 4 leaves, depth 4 and some bytes but got %#v", s)
+	}
+
+	if n := r.Insert(makeTestDN(t, "test.com"), 5).Len(); n != 4 {
+		t.Errorf("Expected 4 domains after replacement but got %d", n)
+	}
+
+	d, _ := r.Delete(makeTestDN(t, "test.com"))
+	if n := d.Len(); n != 3 {
+		t.Errorf("Expected 3 domains after deletion of single domain but got %d", n)
+	}
+
+	d, _ = d.Delete(makeTestDN(t, "com"))
+	if n := d.Len(); n != 2 {
+		t.Errorf("Expected 2 domains after deletion of top level domain but got %d", n)
+	}
+
+	d, _ = r.DeleteSubdomains(makeTestDN(t, "test.com"))
+	if n := d.Len(); n != 2 {
+		t.Errorf("Expected 2 domains after deletion but got %d", n)
+	}
+
+	if n := r.Len(); n != 4 {
+		t.Errorf("Expected 4 domains in original tree after deletions but got %d", n)
+	}
+
+	r = new(Node)
+	for i, s := range []string{"www.test.com", "test.com", "www.test.com", "test.net"} {
+		r.InplaceInsert(makeTestDN(t, s), uint32(i))
+	}
+
+	if n := r.Len(); n != 3 {
+		t.Errorf("Expected 3 domains after inplace insertions but got %d", n)
+	}
+}
+
 func assertTree(r *Node, desc string, t *testing.T, e ...string) {
 	pairs := []string{}
 	for p := range r.Enumerate() {
@@ -366,3 +441,12 @@ func assertValue(v uint32, vok bool, e uint32, eok bool, desc string, t *testing
 		}
 	}
 }
+
+func assertValueWithName(r *Node, s string, e uint32, en string, ed int, t *testing.T) {
+	v, n, depth, ok := r.GetWithName(makeTestDN(t, s))
+	assertValue(v, ok, e, ed > 0, fmt.Sprintf("fetching %q with name from tree", s), t)
+
+	if n.String() != en || depth != ed {
+		t.Errorf("Expected %q (%d) as matched domain for %q but got %q (%d)", en, ed, s, n, depth)
+	}
+}
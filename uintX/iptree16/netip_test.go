@@ -0,0 +1,177 @@
+package iptree16
+
+// !!!DON'T EDIT!!! Generated by infobloxopen/go-trees/etc from <name>tree{{.bits}} with etc -s uint16 -d uintX.yaml -t ./<name>tree\{\{.bits\}\}
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestInsertPrefix(t *testing.T) {
+	var r *Tree
+
+	newR := r.InsertPrefix(netip.Prefix{}, 1)
+	if newR != r {
+		t.Errorf("Expected no changes inserting invalid prefix but got:\n%s\n", newR.root32.Dot())
+	}
+
+	r = r.InsertPrefix(netip.MustParsePrefix("192.0.2.1/24"), 1)
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::/32"), 2)
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::ff:0:0/96"), 3)
+	r = r.InsertAddr(netip.MustParseAddr("::ffff:192.0.2.1"), 4)
+	r.InplaceInsertPrefix(netip.MustParsePrefix("198.51.100.0/24"), 5)
+	r.InplaceInsertAddr(netip.MustParseAddr("2001:db8::1"), 6)
+
+	assertPrefixEnumerate(r, "192.0.2.0/24: 1, 192.0.2.1/32: 4, 198.51.100.0/24: 5, 2001:db8::/32: 2, "+
+		"2001:db8::1/128: 6, 2001:db8::ff:0:0/96: 3", "tree with prefixes", t)
+}
+
+func TestGetByPrefix(t *testing.T) {
+	var r *Tree
+
+	if v, ok := r.GetByPrefix(netip.MustParsePrefix("192.0.2.0/24")); ok {
+		t.Errorf("Expected no result in empty tree but got %d", v)
+	}
+
+	r = r.InsertPrefix(netip.MustParsePrefix("192.0.2.0/24"), 1)
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::/32"), 2)
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8:1::/48"), 3)
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::ff:0:0/96"), 4)
+
+	if v, ok := r.GetByPrefix(netip.Prefix{}); ok {
+		t.Errorf("Expected no result for invalid prefix but got %d", v)
+	}
+
+	for _, c := range []struct {
+		p string
+		e uint16
+	}{
+		{p: "192.0.2.0/24", e: 1},
+		{p: "192.0.2.0/28", e: 1},
+		{p: "2001:db8::/32", e: 2},
+		{p: "2001:db8::ff:0:0/112", e: 4},
+		{p: "2001:db8:1::/64", e: 3},
+		{p: "2001:db8::fe:0:0/96", e: 2},
+	} {
+		v, ok := r.GetByPrefix(netip.MustParsePrefix(c.p))
+		assertResult(v, ok, c.e, c.p, t)
+	}
+
+	for _, s := range []string{"198.51.100.0/24", "2001:db9::/32", "::ffff:192.0.2.0/120"} {
+		if v, ok := r.GetByPrefix(netip.MustParsePrefix(s)); ok {
+			t.Errorf("Expected no result for %s but got %d", s, v)
+		}
+	}
+
+	v, ok := r.GetByAddr(netip.MustParseAddr("192.0.2.1"))
+	assertResult(v, ok, 1, "address 192.0.2.1", t)
+
+	v, ok = r.GetByAddr(netip.MustParseAddr("2001:db8::ff:0:1%eth0"))
+	assertResult(v, ok, 4, "address 2001:db8::ff:0:1%eth0", t)
+
+	if v, ok := r.GetByAddr(netip.Addr{}); ok {
+		t.Errorf("Expected no result for invalid address but got %d", v)
+	}
+}
+
+func TestIPv4MappedAddr(t *testing.T) {
+	r := NewTree()
+	r = r.InsertPrefix(netip.MustParsePrefix("192.0.2.0/24"), 1)
+	r = r.InsertPrefix(netip.MustParsePrefix("::ffff:192.0.2.0/120"), 2)
+
+	a := netip.MustParseAddr("::ffff:192.0.2.1")
+	v, ok := r.GetByAddr(a)
+	assertResult(v, ok, 1, "address "+a.String(), t)
+
+	v, ok = r.GetByIP(net.ParseIP(a.String()))
+	assertResult(v, ok, 1, "IP "+a.String(), t)
+
+	// Prefixes aren't unmapped as networks with 16 bytes IP aren't by net.IPNet based methods.
+	v, ok = r.GetByPrefix(netip.PrefixFrom(a, 128))
+	assertResult(v, ok, 2, "prefix "+a.String()+"/128", t)
+
+	_, n, _ := net.ParseCIDR("::ffff:192.0.2.1/128")
+	v, ok = r.GetByNet(n)
+	assertResult(v, ok, 2, "network "+n.String(), t)
+
+	r = r.InsertAddr(a, 3)
+	v, ok = r.GetByIP(net.ParseIP("192.0.2.1"))
+	assertResult(v, ok, 3, "IP 192.0.2.1", t)
+
+	r, ok = r.DeleteByAddr(a)
+	if !ok {
+		t.Errorf("Expected deletion by %s but got nothing", a)
+	}
+
+	assertPrefixEnumerate(r, "192.0.2.0/24: 1, ::ffff:192.0.2.0/120: 2", "tree after deletion by IPv4-mapped address", t)
+}
+
+func TestGetByAddrAllocs(t *testing.T) {
+	r := NewTree()
+	r = r.InsertPrefix(netip.MustParsePrefix("192.0.2.0/24"), 1)
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::/32"), 2)
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::ff:0:0/96"), 3)
+
+	for _, s := range []string{"192.0.2.1", "2001:db8::1", "2001:db8::ff:0:1"} {
+		a := netip.MustParseAddr(s)
+		p := netip.PrefixFrom(a, a.BitLen())
+		if n := testing.AllocsPerRun(100, func() { r.GetByAddr(a) }); n > 0 {
+			t.Errorf("Expected no allocations for GetByAddr(%s) but got %g", a, n)
+		}
+
+		if n := testing.AllocsPerRun(100, func() { r.GetByPrefix(p) }); n > 0 {
+			t.Errorf("Expected no allocations for GetByPrefix(%s) but got %g", p, n)
+		}
+	}
+}
+
+func TestDeleteByPrefix(t *testing.T) {
+	var r *Tree
+
+	r, ok := r.DeleteByPrefix(netip.MustParsePrefix("192.0.2.0/24"))
+	if ok {
+		t.Error("Expected no deletion in empty tree but got one")
+	}
+
+	r = r.InsertPrefix(netip.MustParsePrefix("192.0.2.0/24"), 1)
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::/32"), 2)
+	r = r.InsertPrefix(netip.MustParsePrefix("2001:db8::ff:0:0/96"), 3)
+	r = r.InsertAddr(netip.MustParseAddr("2001:db8::1"), 4)
+
+	if _, ok := r.DeleteByPrefix(netip.Prefix{}); ok {
+		t.Error("Expected no deletion by invalid prefix but got one")
+	}
+
+	r, ok = r.DeleteByPrefix(netip.MustParsePrefix("2001:db8::ff:0:0/96"))
+	if !ok {
+		t.Error("Expected deletion by 2001:db8::ff:0:0/96 but got nothing")
+	}
+
+	r, ok = r.DeleteByAddr(netip.MustParseAddr("2001:db8::1"))
+	if !ok {
+		t.Error("Expected deletion by 2001:db8::1 but got nothing")
+	}
+
+	r, ok = r.DeleteByPrefix(netip.MustParsePrefix("192.0.2.0/24"))
+	if !ok {
+		t.Error("Expected deletion by 192.0.2.0/24 but got nothing")
+	}
+
+	assertPrefixEnumerate(r, "2001:db8::/32: 2", "tree after deletions", t)
+}
+
+func assertPrefixEnumerate(r *Tree, e, desc string, t *testing.T) {
+	t.Helper()
+
+	items := []string{}
+	for p := range r.EnumeratePrefixes() {
+		items = append(items, fmt.Sprintf("%s: %d", p.Key, p.Value))
+	}
+
+	if s := strings.Join(items, ", "); s != e {
+		t.Errorf("Expected following nodes for %s:\n\t%q\nbut got:\n\t%q", desc, e, s)
+	}
+}
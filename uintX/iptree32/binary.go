@@ -0,0 +1,322 @@
+package iptree32
+
+// !!!DON'T EDIT!!! Generated by infobloxopen/go-trees/etc from <name>tree{{.bits}} with etc -s uint32 -d uintX.yaml -t ./<name>tree\{\{.bits\}\}
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// BinaryVersion is a version of binary format produced by MarshalBinary.
+const BinaryVersion = 1
+
+var (
+	// ErrInvalidBinary is returned when data passed to UnmarshalBinary doesn't look like a marshalled tree.
+	ErrInvalidBinary = errors.New("invalid binary tree data")
+	// ErrUnsupportedVersion is returned when data passed to UnmarshalBinary has unknown binary format version.
+	ErrUnsupportedVersion = errors.New("unsupported binary tree version")
+	// ErrUnexpectedEnd is returned when data passed to UnmarshalBinary ends before the tree is completely decoded.
+	ErrUnexpectedEnd = errors.New("unexpected end of data")
+)
+
+var binaryMagic = []byte("IPU")
+
+const (
+	nodeFlagPresent = 1 << iota
+	nodeFlagLeaf
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. The data starts with "IPU", format version and value size in bits followed by IPv4 and IPv6 radix trees. Nodes are written in depth-first order as flags byte, big-endian key, significant bits, value (for leaf nodes only) followed by both children. Values are written as unsigned varints.
+func (t *Tree) MarshalBinary() ([]byte, error) {
+	b := append(append([]byte{}, binaryMagic...), BinaryVersion, 32)
+	if t == nil {
+		return append(b, 0, 0), nil
+	}
+
+	return t.root64.appendBinary(t.root32.appendBinary(b)), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces content of the tree with data produced by MarshalBinary.
+func (t *Tree) UnmarshalBinary(data []byte) error {
+	if len(data) < len(binaryMagic)+2 || !bytes.Equal(data[:len(binaryMagic)], binaryMagic) {
+		return ErrInvalidBinary
+	}
+
+	if data[len(binaryMagic)] != BinaryVersion {
+		return ErrUnsupportedVersion
+	}
+
+	if data[len(binaryMagic)+1] != 32 {
+		return ErrInvalidBinary
+	}
+
+	off := len(binaryMagic) + 2
+	r32, n, err := decodeNode32(data[off:], nil, 0)
+	if err != nil {
+		return err
+	}
+
+	off += n
+	r64, n, err := decodeNode64s(data[off:], nil, 0)
+	if err != nil {
+		return err
+	}
+
+	if off+n != len(data) {
+		return ErrInvalidBinary
+	}
+
+	t.root32 = r32
+	t.root64 = r64
+	return nil
+}
+
+func (n *node32) appendBinary(b []byte) []byte {
+	if n == nil {
+		return append(b, 0)
+	}
+
+	flags := byte(nodeFlagPresent)
+	if n.leaf {
+		flags |= nodeFlagLeaf
+	}
+
+	b = append(binary.BigEndian.AppendUint32(append(b, flags), n.key), n.bits)
+	if n.leaf {
+		b = binary.AppendUvarint(b, uint64(n.value))
+	}
+
+	return n.chld[1].appendBinary(n.chld[0].appendBinary(b))
+}
+
+func decodeNode32(b []byte, p *node32, branch uint32) (*node32, int, error) {
+	flags, err := decodeNodeFlags(b)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if flags == 0 {
+		return nil, 1, nil
+	}
+
+	if len(b) < 6 {
+		return nil, 0, ErrUnexpectedEnd
+	}
+
+	n := &node32{
+		key:  binary.BigEndian.Uint32(b[1:]),
+		bits: b[5],
+		leaf: flags&nodeFlagLeaf != 0,
+	}
+
+	if n.bits > key32BitSize {
+		return nil, 0, ErrInvalidBinary
+	}
+
+	if p != nil {
+		if n.bits <= p.bits || (n.key^p.key)&masks32[p.bits] != 0 || (n.key>>(key32BitSize-1-p.bits))&1 != branch {
+			return nil, 0, ErrInvalidBinary
+		}
+	}
+
+	off := 6
+	if n.leaf {
+		v, m, err := decodeValue(b[off:])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		n.value = v
+		off += m
+	}
+
+	for i := range n.chld {
+		c, m, err := decodeNode32(b[off:], n, uint32(i))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		n.chld[i] = c
+		off += m
+	}
+
+	n.updateCount()
+	return n, off, nil
+}
+
+func (n *node64) appendBinary(b []byte) []byte {
+	if n == nil {
+		return append(b, 0)
+	}
+
+	flags := byte(nodeFlagPresent)
+	if n.leaf {
+		flags |= nodeFlagLeaf
+	}
+
+	b = append(binary.BigEndian.AppendUint64(append(b, flags), n.key), n.bits)
+	if n.leaf {
+		b = binary.AppendUvarint(b, uint64(n.value))
+	}
+
+	return n.chld[1].appendBinary(n.chld[0].appendBinary(b))
+}
+
+func decodeNode64(b []byte, p *node64, branch uint64) (*node64, int, error) {
+	flags, err := decodeNodeFlags(b)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if flags == 0 {
+		return nil, 1, nil
+	}
+
+	if len(b) < 10 {
+		return nil, 0, ErrUnexpectedEnd
+	}
+
+	n := &node64{
+		key:  binary.BigEndian.Uint64(b[1:]),
+		bits: b[9],
+		leaf: flags&nodeFlagLeaf != 0,
+	}
+
+	if n.bits > key64BitSize {
+		return nil, 0, ErrInvalidBinary
+	}
+
+	if p != nil {
+		if n.bits <= p.bits || (n.key^p.key)&masks64[p.bits] != 0 || (n.key>>(key64BitSize-1-p.bits))&1 != branch {
+			return nil, 0, ErrInvalidBinary
+		}
+	}
+
+	off := 10
+	if n.leaf {
+		v, m, err := decodeValue(b[off:])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		n.value = v
+		off += m
+	}
+
+	for i := range n.chld {
+		c, m, err := decodeNode64(b[off:], n, uint64(i))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		n.chld[i] = c
+		off += m
+	}
+
+	n.updateCount()
+	return n, off, nil
+}
+
+func (n *node64s) appendBinary(b []byte) []byte {
+	if n == nil {
+		return append(b, 0)
+	}
+
+	flags := byte(nodeFlagPresent)
+	if n.leaf {
+		flags |= nodeFlagLeaf
+	}
+
+	b = append(binary.BigEndian.AppendUint64(append(b, flags), n.key), n.bits)
+	if n.leaf {
+		b = n.value.appendBinary(b)
+	}
+
+	return n.chld[1].appendBinary(n.chld[0].appendBinary(b))
+}
+
+func decodeNode64s(b []byte, p *node64s, branch uint64) (*node64s, int, error) {
+	flags, err := decodeNodeFlags(b)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if flags == 0 {
+		return nil, 1, nil
+	}
+
+	if len(b) < 10 {
+		return nil, 0, ErrUnexpectedEnd
+	}
+
+	n := &node64s{
+		key:  binary.BigEndian.Uint64(b[1:]),
+		bits: b[9],
+		leaf: flags&nodeFlagLeaf != 0,
+	}
+
+	if n.bits > key64BitSize {
+		return nil, 0, ErrInvalidBinary
+	}
+
+	if p != nil {
+		if n.bits <= p.bits || (n.key^p.key)&masks64[p.bits] != 0 || (n.key>>(key64BitSize-1-p.bits))&1 != branch {
+			return nil, 0, ErrInvalidBinary
+		}
+	}
+
+	off := 10
+	if n.leaf {
+		v, m, err := decodeNode64(b[off:], nil, 0)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if v == nil {
+			return nil, 0, ErrInvalidBinary
+		}
+
+		n.value = v
+		off += m
+	}
+
+	for i := range n.chld {
+		c, m, err := decodeNode64s(b[off:], n, uint64(i))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		n.chld[i] = c
+		off += m
+	}
+
+	n.updateCount()
+	return n, off, nil
+}
+
+func decodeNodeFlags(b []byte) (byte, error) {
+	if len(b) < 1 {
+		return 0, ErrUnexpectedEnd
+	}
+
+	if b[0]&^(nodeFlagPresent|nodeFlagLeaf) != 0 || b[0] != 0 && b[0]&nodeFlagPresent == 0 {
+		return 0, ErrInvalidBinary
+	}
+
+	return b[0], nil
+}
+
+func decodeValue(b []byte) (uint32, int, error) {
+	v, n := binary.Uvarint(b)
+	if n == 0 {
+		return 0, 0, ErrUnexpectedEnd
+	}
+
+	if n < 0 || v > math.MaxUint32 {
+		return 0, 0, ErrInvalidBinary
+	}
+
+	return uint32(v), n, nil
+}
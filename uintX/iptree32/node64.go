@@ -4,6 +4,7 @@ package iptree32
 
 import (
 	"fmt"
+	"iter"
 	"math/bits"
 )
 
@@ -43,6 +44,9 @@ type node64 struct {
 	value uint32
 
 	chld [2]*node64
+
+	// count is a number of leaves in subtree of the node.
+	count int
 }
 
 // Dot dumps tree to Graphviz .dot format
@@ -90,23 +94,48 @@ func (n *node64) InplaceInsert(key uint64, bits int, value uint32) *node64 {
 	return n.inplaceInsert(key, uint8(bits), value)
 }
 
-// Enumerate returns channel which is populated by nodes in order of their keys.
+// Enumerate returns channel which is populated by nodes with data in order of their keys.
 func (n *node64) Enumerate() chan *node64 {
 	ch := make(chan *node64)
 
 	go func() {
 		defer close(ch)
 
-		if n == nil {
-			return
+		for c := range n.All() {
+			ch <- c
 		}
-
-		n.enumerate(ch)
 	}()
 
 	return ch
 }
 
+// All returns iterator over nodes with data in order of their keys.
+func (n *node64) All() iter.Seq[*node64] {
+	return func(yield func(*node64) bool) {
+		n.Walk(yield)
+	}
+}
+
+// Walk calls f for nodes with data in order of their keys until f returns false. It reports if all the nodes have been visited.
+func (n *node64) Walk(f func(*node64) bool) bool {
+	// If tree is empty -
+	if n == nil {
+		// there is nothing to visit.
+		return true
+	}
+
+	return n.walk(f)
+}
+
+// Len returns number of leaves in the tree. It takes constant time as the number is kept in every node.
+func (n *node64) Len() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.count
+}
+
 // Match locates node which key is equal to or "contains" the key passed as argument.
 func (n *node64) Match(key uint64, bits int) (uint32, bool) {
 	if n == nil {
@@ -184,18 +213,21 @@ func (n *node64) insert(c *node64) *node64 {
 		branch := (n.key >> (key64BitSize - 1 - bits)) & 1
 		if bits == c.bits {
 			c.chld[branch] = n
+			c.updateCount()
 			return c
 		}
 
 		m := newNode64(c.key&masks64[bits], bits, false, 0)
 		m.chld[branch] = n
 		m.chld[1-branch] = c
+		m.updateCount()
 
 		return m
 	}
 
 	if c.bits == n.bits {
 		c.chld = n.chld
+		c.updateCount()
 		return c
 	}
 
@@ -204,6 +236,7 @@ func (n *node64) insert(c *node64) *node64 {
 
 	branch := (c.key >> (key64BitSize - 1 - bits)) & 1
 	m.chld[branch] = m.chld[branch].insert(c)
+	m.updateCount()
 
 	return m
 }
@@ -212,6 +245,10 @@ func (n *node64) inplaceInsert(key uint64, sbits uint8, value uint32) *node64 {
 	var (
 		p      *node64
 		branch uint64
+
+		// Nodes down the path which count should be updated if new leaf is added or value of existing one is replaced.
+		path  [key64BitSize + 1]*node64
+		depth int
 	)
 
 	r := n
@@ -233,22 +270,32 @@ func (n *node64) inplaceInsert(key uint64, sbits uint8, value uint32) *node64 {
 			}
 
 			m.chld[branch] = n
+			m.updateCount()
 			if p == nil {
 				r = m
 			} else {
 				p.chld[pBranch] = m
 			}
 
+			incrementCount64(path[:depth], m.count-n.count)
 			return r
 		}
 
 		if sbits == n.bits {
+			count := n.count
+
 			n.key = key
 			n.leaf = true
 			n.value = value
+			n.updateCount()
+
+			incrementCount64(path[:depth], n.count-count)
 			return r
 		}
 
+		path[depth] = n
+		depth++
+
 		p = n
 		branch = (key >> (key64BitSize - 1 - cbits)) & 1
 		n = n.chld[branch]
@@ -260,21 +307,21 @@ func (n *node64) inplaceInsert(key uint64, sbits uint8, value uint32) *node64 {
 	}
 
 	p.chld[branch] = n
+	incrementCount64(path[:depth], n.count)
 	return r
 }
 
-func (n *node64) enumerate(ch chan *node64) {
-	if n.leaf {
-		ch <- n
+func (n *node64) walk(f func(*node64) bool) bool {
+	// Implemented by depth-first search.
+	if n.leaf && !f(n) {
+		return false
 	}
 
-	if n.chld[0] != nil {
-		n.chld[0].enumerate(ch)
+	if n.chld[0] != nil && !n.chld[0].walk(f) {
+		return false
 	}
 
-	if n.chld[1] != nil {
-		n.chld[1].enumerate(ch)
-	}
+	return n.chld[1] == nil || n.chld[1].walk(f)
 }
 
 func (n *node64) match(key uint64, bits uint8) *node64 {
@@ -369,13 +416,33 @@ func (n *node64) del(key uint64, bits uint8) (*node64, bool) {
 	m.chld = n.chld
 
 	m.chld[branch] = c
+	m.updateCount()
 	return m, true
 }
 
 func newNode64(key uint64, bits uint8, leaf bool, value uint32) *node64 {
-	return &node64{
+	n := &node64{
 		key:   key,
 		bits:  bits,
 		leaf:  leaf,
 		value: value}
+
+	if leaf {
+		n.count = 1
+	}
+
+	return n
+}
+
+func (n *node64) updateCount() {
+	n.count = n.chld[0].Len() + n.chld[1].Len()
+	if n.leaf {
+		n.count += 1
+	}
+}
+
+func incrementCount64(path []*node64, d int) {
+	for _, n := range path {
+		n.count += d
+	}
 }
@@ -4,6 +4,7 @@ package iptree64
 
 import (
 	"fmt"
+	"iter"
 	"math/bits"
 )
 
@@ -35,6 +36,9 @@ type node32 struct {
 	value uint64
 
 	chld [2]*node32
+
+	// count is a number of leaves in subtree of the node.
+	count int
 }
 
 // Dot dumps tree to Graphviz .dot format
@@ -92,18 +96,41 @@ func (n *node32) Enumerate() chan *node32 {
 	go func() {
 		defer close(ch)
 
-		// If tree is empty -
-		if n == nil {
-			// return nothing.
-			return
+		for c := range n.All() {
+			ch <- c
 		}
-
-		n.enumerate(ch)
 	}()
 
 	return ch
 }
 
+// All returns iterator over nodes with data in order of their keys.
+func (n *node32) All() iter.Seq[*node32] {
+	return func(yield func(*node32) bool) {
+		n.Walk(yield)
+	}
+}
+
+// Walk calls f for nodes with data in order of their keys until f returns false. It reports if all the nodes have been visited.
+func (n *node32) Walk(f func(*node32) bool) bool {
+	// If tree is empty -
+	if n == nil {
+		// there is nothing to visit.
+		return true
+	}
+
+	return n.walk(f)
+}
+
+// Len returns number of leaves in the tree. It takes constant time as the number is kept in every node.
+func (n *node32) Len() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.count
+}
+
 // Match locates node which key is equal to or "contains" the key passed as argument.
 func (n *node32) Match(key uint32, bits int) (uint64, bool) {
 	// If tree is empty -
@@ -201,6 +228,7 @@ func (n *node32) insert(c *node32) *node32 {
 		if bits == c.bits {
 			// make new root from the candidate and put current node to one of its branch;
 			c.chld[branch] = n
+			c.updateCount()
 			return c
 		}
 
@@ -211,6 +239,7 @@ func (n *node32) insert(c *node32) *node32 {
 		m.chld[branch] = n
 		// and the candidate at the other.
 		m.chld[1-branch] = c
+		m.updateCount()
 
 		return m
 	}
@@ -219,6 +248,7 @@ func (n *node32) insert(c *node32) *node32 {
 	if c.bits == n.bits {
 		// replace current node with the candidate.
 		c.chld = n.chld
+		c.updateCount()
 		return c
 	}
 
@@ -231,6 +261,7 @@ func (n *node32) insert(c *node32) *node32 {
 	branch := (c.key >> (key32BitSize - 1 - bits)) & 1
 	// insert it to correct branch.
 	m.chld[branch] = m.chld[branch].insert(c)
+	m.updateCount()
 
 	return m
 }
@@ -239,6 +270,10 @@ func (n *node32) inplaceInsert(key uint32, sbits uint8, value uint64) *node32 {
 	var (
 		p      *node32
 		branch uint32
+
+		// Nodes down the path which count should be updated if new leaf is added or value of existing one is replaced.
+		path  [key32BitSize + 1]*node32
+		depth int
 	)
 
 	r := n
@@ -260,22 +295,32 @@ func (n *node32) inplaceInsert(key uint32, sbits uint8, value uint64) *node32 {
 			}
 
 			m.chld[branch] = n
+			m.updateCount()
 			if p == nil {
 				r = m
 			} else {
 				p.chld[pBranch] = m
 			}
 
+			incrementCount32(path[:depth], m.count-n.count)
 			return r
 		}
 
 		if sbits == n.bits {
+			count := n.count
+
 			n.key = key
 			n.leaf = true
 			n.value = value
+			n.updateCount()
+
+			incrementCount32(path[:depth], n.count-count)
 			return r
 		}
 
+		path[depth] = n
+		depth++
+
 		p = n
 		branch = (key >> (key32BitSize - 1 - cbits)) & 1
 		n = n.chld[branch]
@@ -287,22 +332,21 @@ func (n *node32) inplaceInsert(key uint32, sbits uint8, value uint64) *node32 {
 	}
 
 	p.chld[branch] = n
+	incrementCount32(path[:depth], n.count)
 	return r
 }
 
-func (n *node32) enumerate(ch chan *node32) {
+func (n *node32) walk(f func(*node32) bool) bool {
 	// Implemented by depth-first search.
-	if n.leaf {
-		ch <- n
+	if n.leaf && !f(n) {
+		return false
 	}
 
-	if n.chld[0] != nil {
-		n.chld[0].enumerate(ch)
+	if n.chld[0] != nil && !n.chld[0].walk(f) {
+		return false
 	}
 
-	if n.chld[1] != nil {
-		n.chld[1].enumerate(ch)
-	}
+	return n.chld[1] == nil || n.chld[1].walk(f)
 }
 
 func (n *node32) match(key uint32, bits uint8) *node32 {
@@ -426,13 +470,33 @@ func (n *node32) del(key uint32, bits uint8) (*node32, bool) {
 
 	// Replace changed child with new one and return new root with deletion mark set.
 	m.chld[branch] = c
+	m.updateCount()
 	return m, true
 }
 
 func newNode32(key uint32, bits uint8, leaf bool, value uint64) *node32 {
-	return &node32{
+	n := &node32{
 		key:   key,
 		bits:  bits,
 		leaf:  leaf,
 		value: value}
+
+	if leaf {
+		n.count = 1
+	}
+
+	return n
+}
+
+func (n *node32) updateCount() {
+	n.count = n.chld[0].Len() + n.chld[1].Len()
+	if n.leaf {
+		n.count += 1
+	}
+}
+
+func incrementCount32(path []*node32, d int) {
+	for _, n := range path {
+		n.count += d
+	}
 }
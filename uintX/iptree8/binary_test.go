@@ -0,0 +1,85 @@
+package iptree8
+
+// !!!DON'T EDIT!!! Generated by infobloxopen/go-trees/etc from <name>tree{{.bits}} with etc -s uint8 -d uintX.yaml -t ./<name>tree\{\{.bits\}\}
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	var r *Tree
+
+	b, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected no error for empty tree but got %s", err)
+	}
+
+	u := NewTree()
+	if err := u.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Expected no error for empty tree data but got %s", err)
+	}
+
+	assertPrefixEnumerate(u, "", "unmarshalled empty tree", t)
+
+	for i, s := range []string{
+		"10.0.0.0/8",
+		"10.0.0.0/16",
+		"192.0.2.0/24",
+		"2001:db8::/32",
+		"2001:db8::/64",
+		"2001:db8::ff:0:0/96",
+		"2001:db8:1::1/128",
+	} {
+		_, n, _ := net.ParseCIDR(s)
+		r = r.InsertNet(n, uint8(i*37))
+	}
+
+	b, err = r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	u = NewTree()
+	if err := u.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	assertPrefixEnumerate(u, "10.0.0.0/8: 0, 10.0.0.0/16: 37, 192.0.2.0/24: 74, 2001:db8::/32: 111, "+
+		"2001:db8::/64: 148, 2001:db8::ff:0:0/96: 185, 2001:db8:1::1/128: 222", "unmarshalled tree", t)
+
+	v, ok := u.GetByIP(net.ParseIP("2001:db8::ff:0:1"))
+	assertResult(v, ok, 185, "unmarshalled tree", t)
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	r := NewTree().InsertIP(net.ParseIP("192.0.2.1"), 1).InsertIP(net.ParseIP("2001:db8::1"), 2)
+	b, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	u := NewTree()
+	for _, c := range []struct {
+		desc string
+		data []byte
+		err  error
+	}{
+		{desc: "empty data", data: nil, err: ErrInvalidBinary},
+		{desc: "wrong magic", data: append([]byte("XYZ"), b[3:]...), err: ErrInvalidBinary},
+		{desc: "wrong version", data: append([]byte("IPU\xff"), b[4:]...), err: ErrUnsupportedVersion},
+		{desc: "wrong value size", data: append([]byte("IPU\x01\x03"), b[5:]...), err: ErrInvalidBinary},
+		{desc: "truncated data", data: b[:len(b)-1], err: ErrUnexpectedEnd},
+		{desc: "trailing data", data: append(append([]byte{}, b...), 0), err: ErrInvalidBinary},
+		{desc: "invalid node flags", data: append(append([]byte{}, b[:5]...), 0xff), err: ErrInvalidBinary},
+	} {
+		if err := u.UnmarshalBinary(c.data); !errors.Is(err, c.err) {
+			t.Errorf("Expected %q error for %s but got %v", c.err, c.desc, err)
+		}
+	}
+
+	if u.root32 != nil || u.root64 != nil {
+		t.Error("Expected tree to remain empty after failed unmarshalling")
+	}
+}
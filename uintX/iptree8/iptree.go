@@ -3,7 +3,11 @@ package iptree8
 
 // !!!DON'T EDIT!!! Generated by infobloxopen/go-trees/etc from <name>tree{{.bits}} with etc -s uint8 -d uintX.yaml -t ./<name>tree\{\{.bits\}\}
 
-import "net"
+import (
+	"iter"
+	"net"
+	"unsafe"
+)
 
 const (
 	iPv4Bits = net.IPv4len * 8
@@ -29,6 +33,18 @@ type Pair struct {
 
 type subTree64 *node64
 
+// Stats holds tree statistics returned by Stats method. IPv6 networks are kept in separate radix trees attached to the nodes of the main IPv6 tree by their first 64 bits so nodes of such trees are counted as well and their depth is added to depth of node they are attached to.
+type Stats struct {
+	// Nodes is a number of all nodes in the tree including intermediate ones.
+	Nodes int
+	// Leaves is a number of nodes with data.
+	Leaves int
+	// MaxDepth is a number of nodes on the longest path from the root.
+	MaxDepth int
+	// Bytes is an estimated memory size of the nodes.
+	Bytes int
+}
+
 // NewTree creates empty tree.
 func NewTree() *Tree {
 	return &Tree{}
@@ -40,6 +56,18 @@ func (t *Tree) InsertNet(n *net.IPNet, value uint8) *Tree {
 		return t
 	}
 
+	if key, bits := iPv4NetToUint32(n); bits >= 0 {
+		return t.insert32(key, bits, value)
+	}
+
+	if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
+		return t.insert64(MSKey, MSBits, LSKey, LSBits, value)
+	}
+
+	return t
+}
+
+func (t *Tree) insert32(key uint32, bits int, value uint8) *Tree {
 	var (
 		r32 *node32
 		r64 *node64s
@@ -50,26 +78,32 @@ func (t *Tree) InsertNet(n *net.IPNet, value uint8) *Tree {
 		r64 = t.root64
 	}
 
-	if key, bits := iPv4NetToUint32(n); bits >= 0 {
-		return &Tree{
-			root32: r32.Insert(key, bits, value),
-			root64: r64,
-		}
+	return &Tree{
+		root32: r32.Insert(key, bits, value),
+		root64: r64,
 	}
+}
 
-	if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
-		var r *node64
-		if v, ok := r64.ExactMatch(MSKey, MSBits); ok {
-			r = v
-		}
+func (t *Tree) insert64(MSKey uint64, MSBits int, LSKey uint64, LSBits int, value uint8) *Tree {
+	var (
+		r32 *node32
+		r64 *node64s
+	)
 
-		return &Tree{
-			root32: r32,
-			root64: r64.Insert(MSKey, MSBits, r.Insert(LSKey, LSBits, value)),
-		}
+	if t != nil {
+		r32 = t.root32
+		r64 = t.root64
 	}
 
-	return t
+	var r *node64
+	if v, ok := r64.ExactMatch(MSKey, MSBits); ok {
+		r = v
+	}
+
+	return &Tree{
+		root32: r32,
+		root64: r64.Insert(MSKey, MSBits, r.Insert(LSKey, LSBits, value)),
+	}
 }
 
 // InplaceInsertNet inserts (or replaces) value using given network as a key in current tree.
@@ -81,18 +115,20 @@ func (t *Tree) InplaceInsertNet(n *net.IPNet, value uint8) {
 	if key, bits := iPv4NetToUint32(n); bits >= 0 {
 		t.root32 = t.root32.InplaceInsert(key, bits, value)
 	} else if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
-		var r *node64
-		if v, ok := t.root64.ExactMatch(MSKey, MSBits); ok {
-			r = v.InplaceInsert(LSKey, LSBits, value)
-			if r != v {
-				t.root64 = t.root64.InplaceInsert(MSKey, MSBits, r)
-			}
-		} else {
-			t.root64 = t.root64.InplaceInsert(MSKey, MSBits, r.InplaceInsert(LSKey, LSBits, value))
-		}
+		t.inplaceInsert64(MSKey, MSBits, LSKey, LSBits, value)
 	}
 }
 
+func (t *Tree) inplaceInsert64(MSKey uint64, MSBits int, LSKey uint64, LSBits int, value uint8) {
+	var r *node64
+	if v, ok := t.root64.ExactMatch(MSKey, MSBits); ok {
+		r = v
+	}
+
+	// Put the subtree back even if its root remains the same to update counts of IPv6 tree.
+	t.root64 = t.root64.InplaceInsert(MSKey, MSBits, r.InplaceInsert(LSKey, LSBits, value))
+}
+
 // InsertIP inserts value using given IP address as a key. The method returns new tree (old one remains unaffected).
 func (t *Tree) InsertIP(ip net.IP, value uint8) *Tree {
 	return t.InsertNet(newIPNetFromIP(ip), value)
@@ -110,16 +146,30 @@ func (t *Tree) Enumerate() chan Pair {
 	go func() {
 		defer close(ch)
 
-		if t == nil {
-			return
+		for k, v := range t.All() {
+			ch <- Pair{Key: k, Value: v}
 		}
-
-		t.enumerate(ch)
 	}()
 
 	return ch
 }
 
+// All returns iterator over key-value pairs of tree content.
+func (t *Tree) All() iter.Seq2[*net.IPNet, uint8] {
+	return func(yield func(*net.IPNet, uint8) bool) {
+		t.Walk(yield)
+	}
+}
+
+// Walk calls f for key-value pairs of tree content until f returns false. It reports if all the pairs have been visited.
+func (t *Tree) Walk(f func(*net.IPNet, uint8) bool) bool {
+	if t == nil {
+		return true
+	}
+
+	return t.walk(f)
+}
+
 // GetByNet gets value for network which is equal to or contains given network.
 func (t *Tree) GetByNet(n *net.IPNet) (uint8, bool) {
 	if t == nil || n == nil {
@@ -131,30 +181,47 @@ func (t *Tree) GetByNet(n *net.IPNet) (uint8, bool) {
 	}
 
 	if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
-		s, ok := t.root64.Match(MSKey, MSBits)
-		if !ok {
-			return 0, false
-		}
+		return t.get64(MSKey, MSBits, LSKey, LSBits)
+	}
 
-		v, ok := s.Match(LSKey, LSBits)
-		if ok || MSBits < key64BitSize {
-			return v, ok
-		}
+	return 0, false
+}
 
-		s, ok = t.root64.Match(MSKey, MSBits-1)
-		if !ok {
-			return 0, false
-		}
+func (t *Tree) get64(MSKey uint64, MSBits int, LSKey uint64, LSBits int) (uint8, bool) {
+	s, ok := t.root64.Match(MSKey, MSBits)
+	if !ok {
+		return 0, false
+	}
 
-		return s.Match(LSKey, LSBits)
+	v, ok := s.Match(LSKey, LSBits)
+	if ok || MSBits < key64BitSize {
+		return v, ok
 	}
 
-	return 0, false
+	s, ok = t.root64.Match(MSKey, MSBits-1)
+	if !ok {
+		return 0, false
+	}
+
+	return s.Match(LSKey, LSBits)
 }
 
 // GetByIP gets value for network which is equal to or contains given IP address.
 func (t *Tree) GetByIP(ip net.IP) (uint8, bool) {
-	return t.GetByNet(newIPNetFromIP(ip))
+	if t == nil {
+		return 0, false
+	}
+
+	// Avoid newIPNetFromIP here to keep the lookup free of allocations.
+	if ip4 := ip.To4(); ip4 != nil {
+		return t.root32.Match(packIPToUint32(ip4), iPv4Bits)
+	}
+
+	if ip6 := ip.To16(); ip6 != nil {
+		return t.get64(packIPToUint64(ip6), key64BitSize, packIPToUint64(ip6[8:]), key64BitSize)
+	}
+
+	return 0, false
 }
 
 // DeleteByNet removes subtree which is contained by given network. The method returns new tree (old one remains unaffected) and flag indicating if deletion happens indeed.
@@ -164,23 +231,37 @@ func (t *Tree) DeleteByNet(n *net.IPNet) (*Tree, bool) {
 	}
 
 	if key, bits := iPv4NetToUint32(n); bits >= 0 {
-		r, ok := t.root32.Delete(key, bits)
+		return t.delete32(key, bits)
+	}
+
+	if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
+		return t.delete64(MSKey, MSBits, LSKey, LSBits)
+	}
+
+	return t, false
+}
+
+func (t *Tree) delete32(key uint32, bits int) (*Tree, bool) {
+	r, ok := t.root32.Delete(key, bits)
+	if ok {
+		return &Tree{root32: r, root64: t.root64}, true
+	}
+
+	return t, false
+}
+
+func (t *Tree) delete64(MSKey uint64, MSBits int, LSKey uint64, LSBits int) (*Tree, bool) {
+	if v, ok := t.root64.ExactMatch(MSKey, MSBits); ok {
+		r, ok := v.Delete(LSKey, LSBits)
 		if ok {
-			return &Tree{root32: r, root64: t.root64}, true
-		}
-	} else if MSKey, MSBits, LSKey, LSBits := iPv6NetToUint64Pair(n); MSBits >= 0 {
-		if v, ok := t.root64.ExactMatch(MSKey, MSBits); ok {
-			r, ok := v.Delete(LSKey, LSBits)
-			if ok {
-				r64 := t.root64
-				if r == nil {
-					r64, _ = r64.Delete(MSKey, MSBits)
-				} else {
-					r64 = r64.Insert(MSKey, MSBits, r)
-				}
-
-				return &Tree{root32: t.root32, root64: r64}, true
+			r64 := t.root64
+			if r == nil {
+				r64, _ = r64.Delete(MSKey, MSBits)
+			} else {
+				r64 = r64.Insert(MSKey, MSBits, r)
 			}
+
+			return &Tree{root32: t.root32, root64: r64}, true
 		}
 	}
 
@@ -192,28 +273,103 @@ func (t *Tree) DeleteByIP(ip net.IP) (*Tree, bool) {
 	return t.DeleteByNet(newIPNetFromIP(ip))
 }
 
-func (t *Tree) enumerate(ch chan Pair) {
-	for n := range t.root32.Enumerate() {
+// Len returns number of networks in the tree. It takes constant time.
+func (t *Tree) Len() int {
+	if t == nil {
+		return 0
+	}
+
+	return t.root32.Len() + t.root64.Len()
+}
+
+// Stats walks the tree and collects its statistics. IPv4 and IPv6 trees are counted together and MaxDepth is the greatest of their depths.
+func (t *Tree) Stats() Stats {
+	var s Stats
+	if t != nil {
+		stats32(t.root32, 1, &s)
+		stats64s(t.root64, 1, &s)
+	}
+
+	return s
+}
+
+func (t *Tree) walk(f func(*net.IPNet, uint8) bool) bool {
+	for n := range t.root32.All() {
 		mask := net.CIDRMask(int(n.bits), iPv4Bits)
-		ch <- Pair{
-			Key: &net.IPNet{
-				IP:   unpackUint32ToIP(n.key).Mask(mask),
-				Mask: mask},
-			Value: n.value}
+		if !f(&net.IPNet{IP: unpackUint32ToIP(n.key).Mask(mask), Mask: mask}, n.value) {
+			return false
+		}
 	}
 
-	for n := range t.root64.Enumerate() {
+	for n := range t.root64.All() {
 		MSIP := append(unpackUint64ToIP(n.key), make(net.IP, 8)...)
-		for m := range n.value.Enumerate() {
+		for m := range n.value.All() {
 			LSIP := unpackUint64ToIP(m.key)
 			mask := net.CIDRMask(int(n.bits+m.bits), iPv6Bits)
-			ch <- Pair{
-				Key: &net.IPNet{
-					IP:   append(MSIP[0:8], LSIP...).Mask(mask),
-					Mask: mask},
-				Value: m.value}
+			if !f(&net.IPNet{IP: append(MSIP[0:8], LSIP...).Mask(mask), Mask: mask}, m.value) {
+				return false
+			}
 		}
 	}
+
+	return true
+}
+
+func stats32(n *node32, depth int, s *Stats) {
+	if n == nil {
+		return
+	}
+
+	s.Nodes++
+	s.Bytes += int(unsafe.Sizeof(*n))
+	if n.leaf {
+		s.Leaves++
+	}
+
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	stats32(n.chld[0], depth+1, s)
+	stats32(n.chld[1], depth+1, s)
+}
+
+func stats64(n *node64, depth int, s *Stats) {
+	if n == nil {
+		return
+	}
+
+	s.Nodes++
+	s.Bytes += int(unsafe.Sizeof(*n))
+	if n.leaf {
+		s.Leaves++
+	}
+
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	stats64(n.chld[0], depth+1, s)
+	stats64(n.chld[1], depth+1, s)
+}
+
+func stats64s(n *node64s, depth int, s *Stats) {
+	if n == nil {
+		return
+	}
+
+	s.Nodes++
+	s.Bytes += int(unsafe.Sizeof(*n))
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	if n.leaf {
+		stats64(n.value, depth+1, s)
+	}
+
+	stats64s(n.chld[0], depth+1, s)
+	stats64s(n.chld[1], depth+1, s)
 }
 
 func iPv4NetToUint32(n *net.IPNet) (uint32, int) {
@@ -298,6 +298,74 @@ func TestTreeByIP(t *testing.T) {
 	}
 }
 
+func TestLenAndStats(t *testing.T) {
+	var r *Tree
+	if n := r.Len(); n != 0 {
+		t.Errorf("Expected no networks in empty tree but got %d", n)
+	}
+
+	if s := r.Stats(); s != (Stats{}) {
+		t.Errorf("Expected empty stats for empty tree but got %#v", s)
+	}
+
+	for i, s := range []string{"192.0.2.0/24", "192.0.2.0/28", "2001:db8::/32", "2001:db8::1/128", "2001:db8::2/128"} {
+		_, n, _ := net.ParseCIDR(s)
+		r = r.InsertNet(n, uint8(i))
+	}
+
+	if n := r.Len(); n != 5 {
+		t.Errorf("Expected 5 networks but got %d", n)
+	}
+
+	// There are 2 IPv4 nodes and 2 IPv6 nodes (2001:db8::/32 and its child 2001:db8::/64). The first
+	// IPv6 node has subtree with single node while the second one has branch node with 2 leaves.
+	s := r.Stats()
+	if s.Nodes != 8 || s.Leaves != 5 || s.MaxDepth != 4 || s.Bytes <= 0 {
+		t.Errorf("Expected 8 nodes, 5 leaves, depth 4 and some bytes but got %#v", s)
+	}
+
+	_, n, _ := net.ParseCIDR("2001:db8::1/128")
+	d, _ := r.DeleteByNet(n)
+	if n := d.Len(); n != 4 {
+		t.Errorf("Expected 4 networks after deletion of IPv6 network but got %d", n)
+	}
+
+	_, n, _ = net.ParseCIDR("2001:db8::/32")
+	d, _ = r.DeleteByNet(n)
+	if n := d.Len(); n != 2 {
+		t.Errorf("Expected 2 networks after deletion of IPv6 network with subnets but got %d", n)
+	}
+
+	if n := r.Len(); n != 5 {
+		t.Errorf("Expected 5 networks in original tree after deletions but got %d", n)
+	}
+
+	r = NewTree()
+	for i, s := range []string{"192.0.2.0/24", "192.0.2.0/28", "192.0.2.0/24", "2001:db8::1/128", "2001:db8::/112",
+		"2001:db8::2/128", "2001:db8::1/128", "2001:db8::/32"} {
+		_, n, _ := net.ParseCIDR(s)
+		r.InplaceInsertNet(n, uint8(i))
+	}
+
+	if n := r.Len(); n != 6 {
+		t.Errorf("Expected 6 networks after inplace insertions but got %d", n)
+	}
+
+	b, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	u := NewTree()
+	if err := u.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if n := u.Len(); n != 6 {
+		t.Errorf("Expected 6 networks in unmarshalled tree but got %d", n)
+	}
+}
+
 func TestIPv4NetToUint32(t *testing.T) {
 	_, n, _ := net.ParseCIDR("192.0.2.0/24")
 	key, bits := iPv4NetToUint32(n)
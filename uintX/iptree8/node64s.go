@@ -4,6 +4,7 @@ package iptree8
 
 import (
 	"fmt"
+	"iter"
 	"math/bits"
 )
 
@@ -19,6 +20,9 @@ type node64s struct {
 	value *node64
 
 	chld [2]*node64s
+
+	// count is a number of networks in subtrees attached to leaves in subtree of the node.
+	count int
 }
 
 // Dot dumps tree to Graphviz .dot format
@@ -66,23 +70,48 @@ func (n *node64s) InplaceInsert(key uint64, bits int, value *node64) *node64s {
 	return n.inplaceInsert(key, uint8(bits), value)
 }
 
-// Enumerate returns channel which is populated by nodes in order of their keys.
+// Enumerate returns channel which is populated by nodes with data in order of their keys.
 func (n *node64s) Enumerate() chan *node64s {
 	ch := make(chan *node64s)
 
 	go func() {
 		defer close(ch)
 
-		if n == nil {
-			return
+		for c := range n.All() {
+			ch <- c
 		}
-
-		n.enumerate(ch)
 	}()
 
 	return ch
 }
 
+// All returns iterator over nodes with data in order of their keys.
+func (n *node64s) All() iter.Seq[*node64s] {
+	return func(yield func(*node64s) bool) {
+		n.Walk(yield)
+	}
+}
+
+// Walk calls f for nodes with data in order of their keys until f returns false. It reports if all the nodes have been visited.
+func (n *node64s) Walk(f func(*node64s) bool) bool {
+	// If tree is empty -
+	if n == nil {
+		// there is nothing to visit.
+		return true
+	}
+
+	return n.walk(f)
+}
+
+// Len returns number of networks in subtrees attached to leaves in the tree. It takes constant time as the number is kept in every node.
+func (n *node64s) Len() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.count
+}
+
 // Match locates node which key is equal to or "contains" the key passed as argument.
 func (n *node64s) Match(key uint64, bits int) (*node64, bool) {
 	if n == nil {
@@ -160,18 +189,21 @@ func (n *node64s) insert(c *node64s) *node64s {
 		branch := (n.key >> (key64BitSize - 1 - bits)) & 1
 		if bits == c.bits {
 			c.chld[branch] = n
+			c.updateCount()
 			return c
 		}
 
 		m := newNode64s(c.key&masks64[bits], bits, false, nil)
 		m.chld[branch] = n
 		m.chld[1-branch] = c
+		m.updateCount()
 
 		return m
 	}
 
 	if c.bits == n.bits {
 		c.chld = n.chld
+		c.updateCount()
 		return c
 	}
 
@@ -180,6 +212,7 @@ func (n *node64s) insert(c *node64s) *node64s {
 
 	branch := (c.key >> (key64BitSize - 1 - bits)) & 1
 	m.chld[branch] = m.chld[branch].insert(c)
+	m.updateCount()
 
 	return m
 }
@@ -188,6 +221,10 @@ func (n *node64s) inplaceInsert(key uint64, sbits uint8, value *node64) *node64s
 	var (
 		p      *node64s
 		branch uint64
+
+		// Nodes down the path which count should be updated if new leaf is added or value of existing one is replaced.
+		path  [key64BitSize + 1]*node64s
+		depth int
 	)
 
 	r := n
@@ -209,22 +246,32 @@ func (n *node64s) inplaceInsert(key uint64, sbits uint8, value *node64) *node64s
 			}
 
 			m.chld[branch] = n
+			m.updateCount()
 			if p == nil {
 				r = m
 			} else {
 				p.chld[pBranch] = m
 			}
 
+			incrementCount64s(path[:depth], m.count-n.count)
 			return r
 		}
 
 		if sbits == n.bits {
+			count := n.count
+
 			n.key = key
 			n.leaf = true
 			n.value = value
+			n.updateCount()
+
+			incrementCount64s(path[:depth], n.count-count)
 			return r
 		}
 
+		path[depth] = n
+		depth++
+
 		p = n
 		branch = (key >> (key64BitSize - 1 - cbits)) & 1
 		n = n.chld[branch]
@@ -236,21 +283,21 @@ func (n *node64s) inplaceInsert(key uint64, sbits uint8, value *node64) *node64s
 	}
 
 	p.chld[branch] = n
+	incrementCount64s(path[:depth], n.count)
 	return r
 }
 
-func (n *node64s) enumerate(ch chan *node64s) {
-	if n.leaf {
-		ch <- n
+func (n *node64s) walk(f func(*node64s) bool) bool {
+	// Implemented by depth-first search.
+	if n.leaf && !f(n) {
+		return false
 	}
 
-	if n.chld[0] != nil {
-		n.chld[0].enumerate(ch)
+	if n.chld[0] != nil && !n.chld[0].walk(f) {
+		return false
 	}
 
-	if n.chld[1] != nil {
-		n.chld[1].enumerate(ch)
-	}
+	return n.chld[1] == nil || n.chld[1].walk(f)
 }
 
 func (n *node64s) match(key uint64, bits uint8) *node64s {
@@ -345,13 +392,33 @@ func (n *node64s) del(key uint64, bits uint8) (*node64s, bool) {
 	m.chld = n.chld
 
 	m.chld[branch] = c
+	m.updateCount()
 	return m, true
 }
 
 func newNode64s(key uint64, bits uint8, leaf bool, value *node64) *node64s {
-	return &node64s{
+	n := &node64s{
 		key:   key,
 		bits:  bits,
 		leaf:  leaf,
 		value: value}
+
+	if leaf {
+		n.count = n.value.Len()
+	}
+
+	return n
+}
+
+func (n *node64s) updateCount() {
+	n.count = n.chld[0].Len() + n.chld[1].Len()
+	if n.leaf {
+		n.count += n.value.Len()
+	}
+}
+
+func incrementCount64s(path []*node64s, d int) {
+	for _, n := range path {
+		n.count += d
+	}
 }